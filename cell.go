@@ -2,12 +2,18 @@ package xlsx
 
 import (
 	"bytes"
+	"database/sql"
 	"encoding/base64"
 	"fmt"
 	"math"
+	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
+
+	"github.com/gobuffalo/nulls"
 )
 
 const (
@@ -72,6 +78,7 @@ type Cell struct {
 	cellType       CellType
 	DataValidation *xlsxDataValidation
 	Hyperlink      Hyperlink
+	Comment        Comment
 	num            int
 }
 
@@ -129,6 +136,13 @@ type Hyperlink struct {
 	Tooltip       string
 }
 
+// Comment is a note on a cell, which spreadsheet programs show when
+// the pointer is over the cell.
+type Comment struct {
+	Author string
+	Text   string
+}
+
 // CellInterface defines the public API of the Cell.
 type CellInterface interface {
 	String() string
@@ -153,6 +167,16 @@ func (c *Cell) Type() CellType {
 	return c.cellType
 }
 
+// Clear empties the cell, leaving it a blank string cell with no
+// value, rich text or formula. Its style, number format, hyperlink,
+// comment, data validation and merge are kept.
+func (c *Cell) Clear() {
+	c.Value = ""
+	c.RichText = nil
+	c.formula = ""
+	c.cellType = CellTypeString
+}
+
 // SetString sets the value of a cell to a string.
 func (c *Cell) SetString(s string) {
 	c.Value = s
@@ -162,6 +186,11 @@ func (c *Cell) SetString(s string) {
 }
 
 // SetRichText sets the value of a cell to a set of the rich text.
+// Each run is text with a font of its own, so that one cell can hold,
+// say, a bold label followed by a plain value; a run with a nil Font
+// takes the cell's style. The runs are saved as a shared string and
+// read back into the same RichText. The cell's Value is left empty,
+// and FormattedValue gives the text of the runs joined together.
 func (c *Cell) SetRichText(r []RichTextRun) {
 	c.Value = ""
 	c.RichText = append([]RichTextRun(nil), r...)
@@ -182,15 +211,57 @@ func (c *Cell) String() string {
 
 // SetFloat sets the value of a cell to a float.
 func (c *Cell) SetFloat(n float64) {
-	c.SetValue(n)
+	c.SetNumeric(strconv.FormatFloat(n, 'f', -1, 64))
 }
 
-// IsTime returns true if the cell stores a time value.
+// IsTime returns true if the cell stores a time value, that is a date,
+// a time of day or both, going by its number format. See IsDate.
 func (c *Cell) IsTime() bool {
+	if c.cellType == CellTypeDate {
+		return true
+	}
 	c.getNumberFormat()
 	return c.parsedNumFmt.isTimeFormat
 }
 
+// IsDate returns true if the cell stores a time value that is shown as
+// a calendar date, with or without the time of day, as a number format
+// of "yyyy-mm-dd" or "d mmm h:mm" does. It returns false for plain
+// numbers and for times of day and durations, such as "h:mm" and
+// "[h]:mm:ss", which IsTime is true of.
+func (c *Cell) IsDate() bool {
+	return c.cellType == CellTypeDate || c.IsTime() && isDateFormat(c.NumFmt)
+}
+
+// NumberFormat returns the number format the cell is shown with, such
+// as "0.00%" or "yyyy-mm-dd", which for a cell read from a file is
+// that of its style. A cell with no number format is shown as
+// "general".
+func (c *Cell) NumberFormat() string {
+	if c.NumFmt == "" {
+		return builtInNumFmt[builtInNumFmtIndex_GENERAL]
+	}
+	return c.NumFmt
+}
+
+// isDate1904 reports whether the cell's dates are counted from 1904,
+// which is the setting of its file if it has one, see File.SetDate1904.
+func (c *Cell) isDate1904() bool {
+	if c.Row != nil && c.Row.Sheet != nil && c.Row.Sheet.File != nil {
+		return c.Row.Sheet.File.Date1904
+	}
+	return c.date1904
+}
+
+// timeLocation returns the location of the cell's dates, which is that
+// of its file, see TimeLocation, or UTC.
+func (c *Cell) timeLocation() *time.Location {
+	if c.Row != nil && c.Row.Sheet != nil && c.Row.Sheet.File != nil && c.Row.Sheet.File.timeLocation != nil {
+		return c.Row.Sheet.File.timeLocation
+	}
+	return timeLocationUTC
+}
+
 //GetTime returns the value of a Cell as a time.Time
 func (c *Cell) GetTime(date1904 bool) (t time.Time, err error) {
 	f, err := c.Float()
@@ -200,6 +271,42 @@ func (c *Cell) GetTime(date1904 bool) (t time.Time, err error) {
 	return TimeFromExcelTime(f, date1904), nil
 }
 
+// isoDateLayouts are the layouts of the values of CellTypeDate cells,
+// which are dates in the ISO 8601 format.
+var isoDateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02T15:04", "2006-01-02"}
+
+// Time returns the value of the cell as a time.Time, reading the ISO
+// 8601 date of a CellTypeDate cell or the number of days since the
+// epoch of the cell's file that a numeric cell holds, whatever its
+// number format. The time is in the location of the file, see
+// TimeLocation, unless an ISO 8601 date gives its own offset. An empty
+// cell gives ErrEmptyCell, and a cell of another type, such as a
+// string, gives an error wrapping ErrCellType.
+func (c *Cell) Time() (time.Time, error) {
+	if c.isEmpty() {
+		return time.Time{}, ErrEmptyCell
+	}
+	loc := c.timeLocation()
+	switch c.cellType {
+	case CellTypeNumeric:
+		t, err := c.GetTime(c.isDate1904())
+		if err != nil || loc == timeLocationUTC {
+			return t, err
+		}
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc), nil
+	case CellTypeDate:
+		var err error
+		for _, layout := range isoDateLayouts {
+			var t time.Time
+			if t, err = time.ParseInLocation(layout, c.Value, loc); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, err
+	}
+	return time.Time{}, c.cellTypeError("time")
+}
+
 /*
 	The following are samples of format samples.
 
@@ -227,7 +334,8 @@ func (c *Cell) SetFormat(format string) {
 
 // DateTimeOptions are additional options for exporting times
 type DateTimeOptions struct {
-	// Location allows calculating times in other timezones/locations
+	// Location allows calculating times in other timezones/locations,
+	// and is the location of the cell's file, see TimeLocation, if nil
 	Location *time.Location
 	// ExcelTimeFormat is the string you want excel to use to format the datetime
 	ExcelTimeFormat string
@@ -248,20 +356,33 @@ var (
 	}
 )
 
-// SetDate sets the value of a cell to a float.
+// SetDate sets the value of a cell to a float, the date of t in the
+// location of the cell's file, see TimeLocation.
 func (c *Cell) SetDate(t time.Time) {
-	c.SetDateWithOptions(t, DefaultDateOptions)
+	c.SetDateWithFormat(t, DefaultDateFormat)
 }
 
+// SetDateTime sets the value of a cell to a float, the date and time of
+// t in the location of the cell's file, see TimeLocation.
 func (c *Cell) SetDateTime(t time.Time) {
-	c.SetDateWithOptions(t, DefaultDateTimeOptions)
+	c.SetDateWithFormat(t, DefaultDateTimeFormat)
+}
+
+// SetDateWithFormat sets the value of a cell to a float, the date and
+// time of t in the location of the cell's file, see TimeLocation, shown
+// with the Excel number format 'format', such as "yyyy-mm-dd hh:mm".
+func (c *Cell) SetDateWithFormat(t time.Time, format string) {
+	c.SetDateWithOptions(t, DateTimeOptions{ExcelTimeFormat: format})
 }
 
 // SetDateWithOptions allows for more granular control when exporting dates and times
 func (c *Cell) SetDateWithOptions(t time.Time, options DateTimeOptions) {
+	if options.Location == nil {
+		options.Location = c.timeLocation()
+	}
 	_, offset := t.In(options.Location).Zone()
 	t = time.Unix(t.Unix()+int64(offset), 0)
-	c.SetDateTimeWithFormat(TimeToExcelTime(t.In(timeLocationUTC), c.date1904), options.ExcelTimeFormat)
+	c.SetDateTimeWithFormat(TimeToExcelTime(t.In(timeLocationUTC), c.isDate1904()), options.ExcelTimeFormat)
 }
 
 func (c *Cell) SetDateTimeWithFormat(n float64, format string) {
@@ -271,27 +392,48 @@ func (c *Cell) SetDateTimeWithFormat(n float64, format string) {
 	c.cellType = CellTypeNumeric
 }
 
-// Float returns the value of cell as a number.
+// Float returns the value of cell as a number. An empty cell gives 0
+// and ErrEmptyCell, and a cell that doesn't hold a number gives 0 and
+// an error wrapping ErrCellType.
 func (c *Cell) Float() (float64, error) {
+	if c.isEmpty() {
+		return 0, ErrEmptyCell
+	}
 	f, err := strconv.ParseFloat(c.Value, 64)
 	if err != nil {
-		return math.NaN(), err
+		return 0, c.cellTypeError("number")
 	}
 	return f, nil
 }
 
 // SetInt64 sets a cell's value to a 64-bit integer.
 func (c *Cell) SetInt64(n int64) {
-	c.SetValue(n)
+	c.SetNumeric(strconv.FormatInt(n, 10))
+}
+
+// SetUint64 sets a cell's value to an unsigned 64-bit integer. Note
+// that Excel holds numbers as float64, so it rounds integers above
+// 2^53.
+func (c *Cell) SetUint64(n uint64) {
+	c.SetNumeric(strconv.FormatUint(n, 10))
 }
 
-// Int64 returns the value of cell as 64-bit integer.
+// Int64 returns the value of cell as 64-bit integer, dropping any
+// fraction, so that "1.5" gives 1. An empty cell gives 0 and
+// ErrEmptyCell, and a cell that doesn't hold a number in the range of
+// an int64 gives 0 and an error wrapping ErrCellType.
 func (c *Cell) Int64() (int64, error) {
-	f, err := strconv.ParseInt(c.Value, 10, 64)
-	if err != nil {
-		return -1, err
+	if c.isEmpty() {
+		return 0, ErrEmptyCell
 	}
-	return f, nil
+	if i, err := strconv.ParseInt(c.Value, 10, 64); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(c.Value, 64)
+	if err != nil || math.IsNaN(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, c.cellTypeError("integer")
+	}
+	return int64(f), nil
 }
 
 // GeneralNumeric returns the value of the cell as a string. It is formatted very closely to the the XLSX spec for how
@@ -310,7 +452,55 @@ func (c *Cell) GeneralNumericWithoutScientific() (string, error) {
 
 // SetInt sets a cell's value to an integer.
 func (c *Cell) SetInt(n int) {
-	c.SetValue(n)
+	c.SetNumeric(strconv.Itoa(n))
+}
+
+// displayWidth estimates the width the value of the cell is shown
+// with, in the units of Col.Width. Wide characters, such as those of
+// Chinese, count twice, and a value of several lines takes the width
+// of its longest line.
+func (c *Cell) displayWidth() float64 {
+	text, err := c.FormattedValue()
+	if err != nil {
+		text = c.Value
+	}
+	if c.cellType == CellTypeBool {
+		text = "FALSE"
+	}
+	var widest float64
+	for _, line := range strings.Split(text, "\n") {
+		var w float64
+		for _, r := range line {
+			if unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana) || (r >= 0xff01 && r <= 0xff60) {
+				w += 2
+			} else {
+				w++
+			}
+		}
+		if w > widest {
+			widest = w
+		}
+	}
+	if c.style != nil {
+		if c.style.Font.Size > 0 {
+			widest *= float64(c.style.Font.Size) / float64(defaultFontSize)
+		}
+		if c.style.Font.Bold {
+			widest *= 1.1
+		}
+	}
+	return widest
+}
+
+// SetComment attaches a comment by 'author' with the text 'text' to
+// the cell, replacing any comment it had. An empty text removes the
+// comment.
+func (c *Cell) SetComment(author, text string) {
+	if text == "" {
+		c.Comment = Comment{}
+		return
+	}
+	c.Comment = Comment{Author: author, Text: text}
 }
 
 // SetHyperlink sets this cell to contain the given hyperlink, displayText and tooltip.
@@ -330,12 +520,19 @@ func (c *Cell) SetHyperlink(hyperlink string, displayText string, tooltip string
 	}
 }
 
-// SetInt sets a cell's value to an integer.
+// SetValue sets a cell's value to n, choosing the cell type from the
+// type of n. The sql.Null and nulls types are written as the value
+// they hold, as WriteStruct and WriteSlice write them, and blank the
+// cell when they aren't Valid.
 func (c *Cell) SetValue(n interface{}) {
 	switch t := n.(type) {
 	case time.Time:
 		c.SetDateTime(t)
 		return
+	case sql.NullString, sql.NullBool, sql.NullInt64, sql.NullFloat64,
+		nulls.String, nulls.Bool, nulls.Int, nulls.Int64, nulls.Float64, nulls.Time, nulls.UUID:
+		var o WriterOptions
+		o.writeValue(reflect.ValueOf(t), func() *Cell { return c })
 	case int, int8, int16, int32, int64:
 		c.SetNumeric(fmt.Sprintf("%d", n))
 	case float64:
@@ -358,6 +555,20 @@ func (c *Cell) SetValue(n interface{}) {
 	}
 }
 
+// SetValueWithFormat sets the value of a cell as SetValue does, and
+// then applies the Excel number format 'format', such as "#,##0.00",
+// "0.00%" or "yyyy-mm-dd". Unlike SetValue, a bool is stored as a
+// boolean cell. A number format written in another locale, such as
+// "#.##0,00", can be passed through Locale.NumberFormat first.
+func (c *Cell) SetValueWithFormat(v interface{}, format string) {
+	if b, ok := v.(bool); ok {
+		c.SetBool(b)
+	} else {
+		c.SetValue(v)
+	}
+	c.NumFmt = format
+}
+
 // SetNumeric sets a cell's value to a number
 func (c *Cell) SetNumeric(s string) {
 	c.Value = s
@@ -366,15 +577,10 @@ func (c *Cell) SetNumeric(s string) {
 	c.cellType = CellTypeNumeric
 }
 
-// Int returns the value of cell as integer.
-// Has max 53 bits of precision
-// See: float64(int64(math.MaxInt))
+// Int returns the value of cell as integer, as Int64 does.
 func (c *Cell) Int() (int, error) {
-	f, err := strconv.ParseFloat(c.Value, 64)
-	if err != nil {
-		return -1, err
-	}
-	return int(f), nil
+	i, err := c.Int64()
+	return int(i), err
 }
 
 // SetBool sets a cell's value to a boolean.
@@ -403,6 +609,47 @@ func (c *Cell) Bool() bool {
 	return c.Value != ""
 }
 
+// BoolValue returns the value of the cell as a bool. A boolean cell
+// gives its value, a numeric cell whether it isn't zero, and a string
+// cell whether it holds "TRUE" rather than "FALSE", in any case. An
+// empty cell gives ErrEmptyCell, and any other value an error, which
+// wraps ErrCellType for a cell of the wrong type. See also Bool, which
+// makes the best of any value.
+func (c *Cell) BoolValue() (bool, error) {
+	if c.isEmpty() {
+		return false, ErrEmptyCell
+	}
+	switch c.cellType {
+	case CellTypeBool:
+		return c.Value == "1" || strings.EqualFold(c.Value, "true"), nil
+	case CellTypeNumeric:
+		f, err := strconv.ParseFloat(c.Value, 64)
+		if err != nil {
+			return false, err
+		}
+		return f != 0, nil
+	case CellTypeString, CellTypeInline, CellTypeStringFormula:
+		switch {
+		case strings.EqualFold(c.Value, "true"):
+			return true, nil
+		case strings.EqualFold(c.Value, "false"):
+			return false, nil
+		}
+	}
+	return false, c.cellTypeError("bool")
+}
+
+// isEmpty reports whether the cell has no value.
+func (c *Cell) isEmpty() bool {
+	return c.Value == "" && len(c.RichText) == 0
+}
+
+// cellTypeError returns an error wrapping ErrCellType for reading the
+// value of the cell as 'want'.
+func (c *Cell) cellTypeError(want string) error {
+	return fmt.Errorf("value %q can't be read as a %s: %w", c.Value, want, ErrCellType)
+}
+
 // SetFormula sets the format string for a cell.
 func (c *Cell) SetFormula(formula string) {
 	c.formula = formula
@@ -414,6 +661,96 @@ func (c *Cell) SetStringFormula(formula string) {
 	c.cellType = CellTypeStringFormula
 }
 
+// SetFormulaWithCachedValue sets the formula of the cell along with
+// its result, 'cached', which is saved with the formula for the
+// readers and previewers that show the saved result rather than work
+// the formula out. Excel works the formula out again when the file is
+// opened. The result is set as by SetValue, except that a bool is
+// saved as a boolean, text as the text result of a formula, and nil as
+// no result, as with SetFormula. The number format of the cell is kept
+// unless the result is a time.Time.
+func (c *Cell) SetFormulaWithCachedValue(formula string, cached interface{}) {
+	switch v := cached.(type) {
+	case nil:
+		c.SetFormula(formula)
+		c.Value = ""
+		return
+	case bool:
+		c.SetBool(v)
+	case time.Time:
+		c.SetValue(v)
+	default:
+		numFmt := c.NumFmt
+		c.SetValue(v)
+		if c.cellType != CellTypeNumeric {
+			c.SetStringFormula(formula)
+			return
+		}
+		c.NumFmt = numFmt
+	}
+	c.formula = formula
+}
+
+// SetFormulaf sets the formula of the cell to 'format' with each %s
+// verb replaced by the next of 'refs', rendered as a reference such as
+// "B2", as in
+//
+//	cell.SetFormulaf("SUM(%s:%s)", CellRef{Col: 1, Row: 1}, CellRef{Col: 1, Row: 9})
+//
+// A leading "=", as typed in Excel, is dropped, as it isn't part of the
+// formula stored in the file.
+func (c *Cell) SetFormulaf(format string, refs ...CellRef) {
+	args := make([]interface{}, len(refs))
+	for i, ref := range refs {
+		args[i] = ref
+	}
+	c.SetFormula(strings.TrimPrefix(fmt.Sprintf(format, args...), "="))
+}
+
+// CellRef refers to a cell by its zero based column and row indexes.
+type CellRef struct {
+	Col, Row int
+	// FixedCol and FixedRow make the reference absolute in the column
+	// or row, as in "$B2" or "B$2".
+	FixedCol, FixedRow bool
+}
+
+// String returns the reference in A1 style, such as "B2".
+func (ref CellRef) String() string {
+	return GetCellIDStringFromCoordsWithFixed(ref.Col, ref.Row, ref.FixedCol, ref.FixedRow)
+}
+
+// cellRefPartsRegexp matches a reference to a single cell in A1 style,
+// picking out its parts.
+var cellRefPartsRegexp = regexp.MustCompile(`^(\$?)([A-Z]{1,3})(\$?)([1-9][0-9]{0,6})$`)
+
+// ParseCellRef returns the reference ref, given in A1 style such as
+// "B7" or "$B$7", the letters of which can be in either case. An error
+// is returned if ref isn't such a reference, or is beyond the last
+// column or row a sheet can have.
+func ParseCellRef(ref string) (CellRef, error) {
+	m := cellRefPartsRegexp.FindStringSubmatch(strings.ToUpper(ref))
+	if m == nil {
+		return CellRef{}, fmt.Errorf("invalid cell reference %q", ref)
+	}
+	row, _ := strconv.Atoi(m[4])
+	cellRef := CellRef{
+		Col:      ColLettersToIndex(m[2]),
+		Row:      row - 1,
+		FixedCol: m[1] != "",
+		FixedRow: m[3] != "",
+	}
+	if cellRef.Col > Excel2006MaxColIndex || cellRef.Row > Excel2006MaxRowIndex {
+		return CellRef{}, fmt.Errorf("cell reference %q is beyond the last cell of a sheet", ref)
+	}
+	return cellRef, nil
+}
+
+// Ref returns a reference to the cell. The cell must belong to a row.
+func (c *Cell) Ref() CellRef {
+	return CellRef{Col: c.num, Row: c.Row.num}
+}
+
 // Formula returns the formula string for the cell.
 func (c *Cell) Formula() string {
 	return c.formula
@@ -427,11 +764,60 @@ func (c *Cell) GetStyle() *Style {
 	return c.style
 }
 
-// SetStyle sets the style of a cell.
+// SetStyle sets the style of a cell. The style isn't copied, so one
+// Style can be shared by many cells, which saves memory for large
+// sheets. Styles are written once to the file however many cells use
+// them, and equal styles share an entry too, so that styling cells one
+// at a time doesn't grow the file. The behaviour of changing a Style
+// once it has been given to a cell, including through GetStyle, is
+// undefined.
 func (c *Cell) SetStyle(style *Style) {
 	c.style = style
 }
 
+// SetLocked sets whether the cell is locked, so that it can't be changed
+// once its sheet is protected, see Sheet.Protect. Cells are locked
+// unless they are unlocked, so this is how the cells users should fill
+// in are left open. The cell's style is copied rather than changed, so
+// cells sharing it aren't affected, and the copy is kept by the sheet so
+// that the cells of one style that are unlocked share a style again.
+func (c *Cell) SetLocked(locked bool) {
+	if c.style != nil && c.style.ApplyProtection && c.style.Protection.Locked == locked {
+		return
+	}
+	key := lockedStyleKey{style: c.style, locked: locked}
+	var styles map[lockedStyleKey]*Style
+	if c.Row != nil && c.Row.Sheet != nil {
+		if c.Row.Sheet.lockedStyles == nil {
+			c.Row.Sheet.lockedStyles = make(map[lockedStyleKey]*Style)
+		}
+		styles = c.Row.Sheet.lockedStyles
+		if style, ok := styles[key]; ok {
+			c.style = style
+			return
+		}
+	}
+	style := *c.GetStyle()
+	style.ApplyProtection = true
+	style.Protection.Locked = locked
+	if styles != nil {
+		styles[key] = &style
+	}
+	c.style = &style
+}
+
+// lockedStyleKey is a style, or nil for a cell without one, paired with
+// whether its copy made by SetLocked is locked.
+type lockedStyleKey struct {
+	style  *Style
+	locked bool
+}
+
+// Locked reports whether the cell is locked, see SetLocked.
+func (c *Cell) Locked() bool {
+	return c.style == nil || !c.style.ApplyProtection || c.style.Protection.Locked
+}
+
 // GetNumberFormat returns the number format string for a cell.
 func (c *Cell) GetNumberFormat() string {
 	return c.NumFmt
@@ -465,8 +851,11 @@ func (c *Cell) getNumberFormat() *parsedNumberFormat {
 // FormattedValue returns a value, and possibly an error condition
 // from a Cell.  If it is possible to apply a format to the cell
 // value, it will do so, if not then an error will be returned, along
-// with the raw value of the Cell.
+// with the raw value of the Cell. A rich text cell gives its text.
 func (c *Cell) FormattedValue() (string, error) {
+	if len(c.RichText) > 0 {
+		return richTextToPlainText(c.RichText), nil
+	}
 	fullFormat := c.getNumberFormat()
 	returnVal, err := fullFormat.FormatValue(c)
 	if fullFormat.parseEncounteredError != nil {
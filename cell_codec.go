@@ -0,0 +1,89 @@
+package xlsx
+
+import (
+	"reflect"
+	"sync"
+)
+
+// CellEncoder writes v, a value of the type it was registered for, to
+// cell.
+type CellEncoder func(cell *Cell, v interface{}) error
+
+// CellDecoder returns the value held by cell as a value of the type it
+// was registered for.
+type CellDecoder func(cell *Cell) (interface{}, error)
+
+// cellCodecs holds the registered encoders or decoders of one kind.
+type cellCodecs struct {
+	mu     sync.RWMutex
+	exact  map[reflect.Type]interface{}
+	ifaces []reflect.Type // interface types, in order of registration
+}
+
+var (
+	cellEncoders = &cellCodecs{exact: make(map[reflect.Type]interface{})}
+	cellDecoders = &cellCodecs{exact: make(map[reflect.Type]interface{})}
+)
+
+func (cc *cellCodecs) register(t reflect.Type, fn interface{}) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if _, ok := cc.exact[t]; !ok && t.Kind() == reflect.Interface {
+		cc.ifaces = append(cc.ifaces, t)
+	}
+	cc.exact[t] = fn
+}
+
+// lookup returns the function registered for t itself or, failing
+// that, for the first registered interface type that t implements.
+func (cc *cellCodecs) lookup(t reflect.Type) interface{} {
+	cc.mu.RLock()
+	defer cc.mu.RUnlock()
+	if fn, ok := cc.exact[t]; ok {
+		return fn
+	}
+	for _, iface := range cc.ifaces {
+		if t.Implements(iface) {
+			return cc.exact[iface]
+		}
+	}
+	return nil
+}
+
+// RegisterCellEncoder makes WriteStruct, WriteSlice and the other
+// writers use 'fn' to write values of type t. If t is an interface
+// type, 'fn' is used for every type that implements it and has no
+// encoder of its own; such encoders are tried in the order they were
+// registered. Encoders take precedence over the built-in handling of
+// a type, and are only consulted for a struct type when a StructWriter
+// is created, so they are best registered in an init function.
+// Registering a second encoder for t replaces the first.
+func RegisterCellEncoder(t reflect.Type, fn CellEncoder) {
+	if t == nil || fn == nil {
+		panic("xlsx: RegisterCellEncoder called with a nil type or function")
+	}
+	cellEncoders.register(t, fn)
+}
+
+// RegisterCellDecoder makes ReadStruct use 'fn' to read fields of type
+// t, in the same way as RegisterCellEncoder. The value returned by
+// 'fn' must be assignable to the field; a nil value leaves the field
+// as it was.
+func RegisterCellDecoder(t reflect.Type, fn CellDecoder) {
+	if t == nil || fn == nil {
+		panic("xlsx: RegisterCellDecoder called with a nil type or function")
+	}
+	cellDecoders.register(t, fn)
+}
+
+// lookupCellEncoder returns the encoder for values of type t, or nil.
+func lookupCellEncoder(t reflect.Type) CellEncoder {
+	fn, _ := cellEncoders.lookup(t).(CellEncoder)
+	return fn
+}
+
+// lookupCellDecoder returns the decoder for fields of type t, or nil.
+func lookupCellDecoder(t reflect.Type) CellDecoder {
+	fn, _ := cellDecoders.lookup(t).(CellDecoder)
+	return fn
+}
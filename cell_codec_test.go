@@ -0,0 +1,132 @@
+package xlsx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+// codecMoney is written as a number of pounds and read back from one.
+type codecMoney struct {
+	pence int64
+}
+
+// codecLabeler is implemented by the enums of the codec tests, which
+// are written as their label.
+type codecLabeler interface {
+	Label() string
+}
+
+type codecColour int
+
+func (c codecColour) Label() string {
+	return [...]string{"red", "green"}[c]
+}
+
+func init() {
+	RegisterCellEncoder(reflect.TypeOf(codecMoney{}), func(cell *Cell, v interface{}) error {
+		cell.SetFloatWithFormat(float64(v.(codecMoney).pence)/100, "0.00")
+		return nil
+	})
+	RegisterCellDecoder(reflect.TypeOf(codecMoney{}), func(cell *Cell) (interface{}, error) {
+		f, err := cell.Float()
+		if err != nil {
+			return nil, err
+		}
+		return codecMoney{int64(f*100 + 0.5)}, nil
+	})
+	RegisterCellEncoder(reflect.TypeOf((*codecLabeler)(nil)).Elem(), func(cell *Cell, v interface{}) error {
+		cell.SetString(strings.ToUpper(v.(codecLabeler).Label()))
+		return nil
+	})
+	RegisterCellDecoder(reflect.TypeOf(codecColour(0)), func(cell *Cell) (interface{}, error) {
+		switch strings.ToLower(cell.Value) {
+		case "red":
+			return codecColour(0), nil
+		case "green":
+			return codecColour(1), nil
+		case "":
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unknown colour %q", cell.Value)
+	})
+	RegisterCellDecoder(reflect.TypeOf(codecWrong(0)), func(cell *Cell) (interface{}, error) {
+		return strconv.Itoa(len(cell.Value)), nil
+	})
+}
+
+type codecWrong int
+
+func TestCellCodecs(t *testing.T) {
+	c := qt.New(t)
+
+	type item struct {
+		Price  codecMoney  `xlsx:"0"`
+		Colour codecColour `xlsx:"1"`
+		Count  int         `xlsx:"2"`
+	}
+
+	c.Run("Encode", func(c *qt.C) {
+		f := NewFile()
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		n, err := row.WriteStruct(&item{codecMoney{1250}, 1, 3}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 3)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "12.5")
+		c.Assert(row.GetCell(0).NumFmt, qt.Equals, "0.00")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "GREEN")
+		c.Assert(row.GetCell(2).Value, qt.Equals, "3")
+
+		values := []interface{}{codecMoney{5}, &codecMoney{7}, codecColour(0)}
+		row = sheet.AddRow()
+		c.Assert(row.WriteSlice(&values, -1), qt.Equals, 3)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "0.05")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "0.07")
+		c.Assert(row.GetCell(2).Value, qt.Equals, "RED")
+	})
+
+	c.Run("Decode", func(c *qt.C) {
+		f := NewFile()
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRowValues(12.5, "Green", 3)
+		var got item
+		c.Assert(row.ReadStruct(&got), qt.IsNil)
+		c.Assert(got, qt.Equals, item{codecMoney{1250}, 1, 3})
+
+		row = sheet.AddRowValues(1, "blue", 3)
+		err := row.ReadStruct(&got)
+		c.Assert(err, qt.ErrorMatches, `unknown colour "blue"`)
+
+		type wrong struct {
+			W codecWrong `xlsx:"0"`
+		}
+		err = row.ReadStruct(&wrong{})
+		c.Assert(err, qt.ErrorMatches, "xlsx: cell decoder for xlsx.codecWrong returned a string")
+	})
+
+	c.Run("RegisterNil", func(c *qt.C) {
+		c.Assert(func() { RegisterCellEncoder(nil, nil) }, qt.PanicMatches, "xlsx: RegisterCellEncoder called with a nil type or function")
+		c.Assert(func() { RegisterCellDecoder(reflect.TypeOf(0), nil) }, qt.PanicMatches, "xlsx: RegisterCellDecoder called with a nil type or function")
+	})
+
+	c.Run("DecoderErrorIsReturned", func(c *qt.C) {
+		sentinel := errors.New("bad cell")
+		type failing int
+		RegisterCellDecoder(reflect.TypeOf(failing(0)), func(*Cell) (interface{}, error) {
+			return nil, sentinel
+		})
+		type e struct {
+			F failing `xlsx:"0"`
+		}
+		f := NewFile()
+		sheet, _ := f.AddSheet("Test1")
+		err := sheet.AddRowValues("x").ReadStruct(&e{})
+		c.Assert(err, qt.Equals, sentinel)
+	})
+}
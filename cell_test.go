@@ -1,11 +1,16 @@
 package xlsx
 
 import (
+	"bytes"
+	"database/sql"
+	"errors"
 	"math"
+	"strings"
 	"testing"
 	"time"
 
 	qt "github.com/frankban/quicktest"
+	"github.com/gobuffalo/nulls"
 )
 
 func TestCell(t *testing.T) {
@@ -621,6 +626,14 @@ func TestCell(t *testing.T) {
 		c.Assert(cell.NumFmt, qt.Equals, builtInNumFmt[builtInNumFmtIndex_GENERAL])
 		c.Assert(cell.Type(), qt.Equals, CellTypeNumeric)
 
+		cell.SetUint64(math.MaxUint64)
+		c.Assert(cell.Value, qt.Equals, "18446744073709551615")
+		c.Assert(cell.NumFmt, qt.Equals, builtInNumFmt[builtInNumFmtIndex_GENERAL])
+		c.Assert(cell.Type(), qt.Equals, CellTypeNumeric)
+
+		cell.SetInt64(math.MinInt64)
+		c.Assert(cell.Value, qt.Equals, "-9223372036854775808")
+
 		cell.SetFloat(1.024)
 		float, _ := cell.Float()
 		intValue, _ = cell.Int() // convert
@@ -721,6 +734,62 @@ func TestCell(t *testing.T) {
 		// others
 		cell.SetValue([]string{"test"})
 		c.Assert(cell.Value, qt.Equals, "[test]")
+
+		// nulls, written as the values they hold or as blank cells
+		when := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+		for _, tc := range []struct {
+			value    interface{}
+			want     string
+			cellType CellType
+		}{
+			{sql.NullString{String: "beer", Valid: true}, "beer", CellTypeString},
+			{sql.NullString{String: "", Valid: true}, "", CellTypeInline},
+			{sql.NullBool{Bool: true, Valid: true}, "1", CellTypeBool},
+			{sql.NullInt64{Int64: 42, Valid: true}, "42", CellTypeNumeric},
+			{sql.NullFloat64{Float64: 1.5, Valid: true}, "1.5", CellTypeNumeric},
+			{nulls.NewString("ale"), "ale", CellTypeString},
+			{nulls.NewBool(false), "0", CellTypeBool},
+			{nulls.NewInt(7), "7", CellTypeNumeric},
+			{nulls.NewInt64(8), "8", CellTypeNumeric},
+			{nulls.NewFloat64(0.25), "0.25", CellTypeNumeric},
+			{nulls.NewTime(when), "43891.5", CellTypeNumeric},
+			{sql.NullString{String: "ignored"}, "", CellTypeString},
+			{sql.NullBool{Bool: true}, "", CellTypeString},
+			{sql.NullInt64{Int64: 42}, "", CellTypeString},
+			{sql.NullFloat64{Float64: 1.5}, "", CellTypeString},
+			{nulls.Int{Int: 7}, "", CellTypeString},
+			{nulls.Time{Time: when}, "", CellTypeString},
+			{nulls.UUID{}, "", CellTypeString},
+		} {
+			cell := Cell{}
+			cell.SetValue(tc.value)
+			c.Assert(cell.Value, qt.Equals, tc.want, qt.Commentf("%#v", tc.value))
+			c.Assert(cell.Type(), qt.Equals, tc.cellType, qt.Commentf("%#v", tc.value))
+		}
+	})
+
+	c.Run("TestSetDateWithFormat", func(c *qt.C) {
+		when := time.Date(2020, 3, 1, 18, 0, 0, 0, time.UTC)
+		cell := Cell{}
+
+		cell.SetDate(when)
+		c.Assert(cell.Value, qt.Equals, "43891.75")
+		c.Assert(cell.NumFmt, qt.Equals, DefaultDateFormat)
+		c.Assert(cell.IsDate(), qt.Equals, true)
+
+		cell.SetDateTime(when)
+		c.Assert(cell.Value, qt.Equals, "43891.75")
+		c.Assert(cell.NumFmt, qt.Equals, DefaultDateTimeFormat)
+
+		cell.SetDateWithFormat(when, "yyyy-mm-dd hh:mm")
+		c.Assert(cell.Value, qt.Equals, "43891.75")
+		c.Assert(cell.Type(), qt.Equals, CellTypeNumeric)
+		value, err := cell.FormattedValue()
+		c.Assert(err, qt.IsNil)
+		c.Assert(value, qt.Equals, "2020-03-01 18:00")
+		got, err := cell.Time()
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.Equals, when)
 	})
 
 	c.Run("TestSetDateWithOptions", func(c *qt.C) {
@@ -817,6 +886,345 @@ func TestCell(t *testing.T) {
 		}
 	})
 
+	c.Run("TestSetValueWithFormat", func(c *qt.C) {
+		cell := Cell{}
+
+		cell.SetValueWithFormat(1234.5, "#,##0.00")
+		c.Assert(cell.Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(cell.NumFmt, qt.Equals, "#,##0.00")
+		fvc := formattedValueChecker{c}
+		fvc.Equals(cell, "1234.50")
+
+		cell.SetValueWithFormat(0.25, "0.00%")
+		fvc.Equals(cell, "25.00%")
+
+		cell.SetValueWithFormat(time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC), "yyyy-mm-dd")
+		c.Assert(cell.NumFmt, qt.Equals, "yyyy-mm-dd")
+		fvc.Equals(cell, "2020-03-04")
+
+		cell.SetValueWithFormat(true, "general")
+		c.Assert(cell.Type(), qt.Equals, CellTypeBool)
+		c.Assert(cell.Bool(), qt.Equals, true)
+	})
+
+	csRunO(c, "TestSetFormulaf", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		var first, last *Cell
+		for i := 0; i < 3; i++ {
+			row := sheet.AddRow()
+			row.AddCell().SetInt(i)
+			cell := row.AddCell()
+			cell.SetInt(i * 10)
+			if i == 0 {
+				first = cell
+			}
+			last = cell
+		}
+		c.Assert(first.Ref(), qt.Equals, CellRef{Col: 1, Row: 0})
+		c.Assert(last.Ref().String(), qt.Equals, "B3")
+
+		total := sheet.AddRow().AddCell()
+		total.SetFormulaf("=SUM(%s:%s)", first.Ref(), last.Ref())
+		c.Assert(total.Formula(), qt.Equals, "SUM(B1:B3)")
+		c.Assert(total.Type(), qt.Equals, CellTypeNumeric)
+
+		total.SetFormulaf("%s*%s", CellRef{Col: 27, Row: 9, FixedCol: true}, CellRef{Col: 0, Row: 0, FixedCol: true, FixedRow: true})
+		c.Assert(total.Formula(), qt.Equals, "$AB10*$A$1")
+	})
+
+	csRunO(c, "TestSetComment", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		for _, name := range []string{"Test1", "Test2"} {
+			sheet, _ := f.AddSheet(name)
+			row := sheet.AddRow()
+			row.AddCell().SetString("Name")
+			cell := row.AddCell()
+			cell.SetInt(12)
+			cell.SetComment("Importer", "Too few items on "+name)
+			sheet.AddRow()
+			cell, _ = sheet.Cell(2, 3)
+			cell.SetComment("Eric", "Left blank")
+		}
+		sheet := f.Sheets[0]
+		cell, _ := sheet.Cell(0, 0)
+		cell.SetComment("Eric", "gone")
+		cell.SetComment("Eric", "")
+		c.Assert(cell.Comment, qt.Equals, Comment{})
+
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<legacyDrawing r:id="rId2"></legacyDrawing>`)
+		c.Assert(parts["xl/worksheets/_rels/sheet1.xml.rels"], qt.Contains, `Target="../comments1.xml"`)
+		c.Assert(parts["xl/worksheets/_rels/sheet2.xml.rels"], qt.Contains, `Target="../drawings/vmlDrawing2.vml"`)
+		c.Assert(parts["xl/comments1.xml"], qt.Contains, `<authors><author>Importer</author><author>Eric</author></authors>`)
+		c.Assert(parts["xl/comments2.xml"], qt.Contains, `<comment ref="D3" authorId="1"><text><t>Left blank</t></text></comment>`)
+		c.Assert(parts["xl/drawings/vmlDrawing1.vml"], qt.Contains, `<x:Row>2</x:Row><x:Column>3</x:Column>`)
+		c.Assert(parts["[Content_Types].xml"], qt.Contains, `<Override PartName="/xl/comments2.xml"`)
+		c.Assert(strings.Count(parts["[Content_Types].xml"], `Extension="vml"`), qt.Equals, 1)
+
+		var buf bytes.Buffer
+		c.Assert(f.Write(&buf), qt.IsNil)
+		f, err = OpenBinary(buf.Bytes(), option)
+		c.Assert(err, qt.IsNil)
+		for _, name := range []string{"Test1", "Test2"} {
+			sheet := f.Sheet[name]
+			cell, err := sheet.Cell(0, 1)
+			c.Assert(err, qt.IsNil)
+			c.Assert(cell.Value, qt.Equals, "12")
+			c.Assert(cell.Comment, qt.Equals, Comment{Author: "Importer", Text: "Too few items on " + name})
+			cell, err = sheet.Cell(2, 3)
+			c.Assert(err, qt.IsNil)
+			c.Assert(cell.Comment, qt.Equals, Comment{Author: "Eric", Text: "Left blank"})
+			cell, err = sheet.Cell(0, 0)
+			c.Assert(err, qt.IsNil)
+			c.Assert(cell.Comment, qt.Equals, Comment{})
+		}
+	})
+
+	csRunO(c, "TestRichTextRoundTrip", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		runs := []RichTextRun{
+			{
+				Font: &RichTextFont{Bold: true, Family: RichTextFontFamilyUnspecified, Charset: RichTextCharsetUnspecified},
+				Text: "Total: ",
+			},
+			{
+				Font: &RichTextFont{
+					Name:      "Arial",
+					Size:      14,
+					Family:    RichTextFontFamilySwiss,
+					Charset:   RichTextCharsetUnspecified,
+					Color:     NewRichTextColorFromARGB(255, 192, 0, 0),
+					Italic:    true,
+					Underline: RichTextUnderlineSingle,
+				},
+				Text: "42",
+			},
+			{Text: " items"},
+		}
+		cell := sheet.AddRow().AddCell()
+		cell.SetRichText(runs)
+		value, err := cell.FormattedValue()
+		c.Assert(err, qt.IsNil)
+		c.Assert(value, qt.Equals, "Total: 42 items")
+
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/sharedStrings.xml"], qt.Contains, `<r><rPr><b></b></rPr><t xml:space="preserve">Total: </t></r>`)
+
+		var buf bytes.Buffer
+		c.Assert(f.Write(&buf), qt.IsNil)
+		f, err = OpenBinary(buf.Bytes(), option)
+		c.Assert(err, qt.IsNil)
+		cell, err = f.Sheet["Test1"].Cell(0, 0)
+		c.Assert(err, qt.IsNil)
+		c.Assert(areRichTextsEqual(cell.RichText, runs), qt.Equals, true, qt.Commentf("%#v", cell.RichText))
+	})
+
+	c.Run("TestIsDate", func(c *qt.C) {
+		cases := []struct {
+			numFmt         string
+			isTime, isDate bool
+		}{
+			{"general", false, false},
+			{"0.00%", false, false},
+			{`0.00 "days"`, false, false},
+			{"yyyy-mm-dd", true, true},
+			{"d mmm", true, true},
+			{"mmm yy", true, true},
+			{"m/d/yy h:mm", true, true},
+			{`[$-409]mmmm\ d`, true, true},
+			{"h:mm AM/PM", true, false},
+			{"hh:mm:ss", true, false},
+			{"mm:ss.0", true, false},
+			{"[h]:mm:ss", true, false},
+			{"[Red]h:mm", true, false},
+		}
+		for _, tc := range cases {
+			cell := &Cell{}
+			cell.SetFloatWithFormat(43891.5, tc.numFmt)
+			c.Assert(cell.IsTime(), qt.Equals, tc.isTime, qt.Commentf("%s", tc.numFmt))
+			c.Assert(cell.IsDate(), qt.Equals, tc.isDate, qt.Commentf("%s", tc.numFmt))
+			c.Assert(cell.NumberFormat(), qt.Equals, tc.numFmt)
+		}
+
+		cell := &Cell{}
+		c.Assert(cell.NumberFormat(), qt.Equals, "general")
+		cell.SetDate(time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC))
+		c.Assert(cell.IsDate(), qt.Equals, true)
+		cell.Value = "2020-03-01"
+		cell.cellType = CellTypeDate
+		cell.NumFmt = ""
+		c.Assert(cell.IsTime(), qt.Equals, true)
+		c.Assert(cell.IsDate(), qt.Equals, true)
+	})
+
+	c.Run("TestTypedGetters", func(c *qt.C) {
+		empty := &Cell{}
+		i, err := empty.Int()
+		c.Assert(err, qt.Equals, ErrEmptyCell)
+		c.Assert(i, qt.Equals, 0)
+		i64, err := empty.Int64()
+		c.Assert(err, qt.Equals, ErrEmptyCell)
+		c.Assert(i64, qt.Equals, int64(0))
+		f, err := empty.Float()
+		c.Assert(err, qt.Equals, ErrEmptyCell)
+		c.Assert(f, qt.Equals, 0.0)
+		_, err = empty.BoolValue()
+		c.Assert(err, qt.Equals, ErrEmptyCell)
+		_, err = empty.Time()
+		c.Assert(err, qt.Equals, ErrEmptyCell)
+
+		cell := &Cell{}
+		cell.SetBool(true)
+		b, err := cell.BoolValue()
+		c.Assert(err, qt.IsNil)
+		c.Assert(b, qt.Equals, true)
+		_, err = cell.Time()
+		c.Assert(errors.Is(err, ErrCellType), qt.Equals, true)
+
+		cell.SetInt(0)
+		b, err = cell.BoolValue()
+		c.Assert(err, qt.IsNil)
+		c.Assert(b, qt.Equals, false)
+
+		cell.SetString("False")
+		b, err = cell.BoolValue()
+		c.Assert(err, qt.IsNil)
+		c.Assert(b, qt.Equals, false)
+		cell.SetString("yes")
+		_, err = cell.BoolValue()
+		c.Assert(err, qt.ErrorMatches, `value "yes" can't be read as a bool: cell holds a value of another type`)
+		c.Assert(errors.Is(err, ErrCellType), qt.Equals, true)
+		f, err = cell.Float()
+		c.Assert(err, qt.ErrorMatches, `value "yes" can't be read as a number: cell holds a value of another type`)
+		c.Assert(f, qt.Equals, 0.0)
+		i, err = cell.Int()
+		c.Assert(errors.Is(err, ErrCellType), qt.Equals, true)
+		c.Assert(i, qt.Equals, 0)
+		i64, err = cell.Int64()
+		c.Assert(errors.Is(err, ErrCellType), qt.Equals, true)
+		c.Assert(i64, qt.Equals, int64(0))
+
+		// Int and Int64 both drop the fraction of a number.
+		cell.SetFloat(-1.5)
+		i, err = cell.Int()
+		c.Assert(err, qt.IsNil)
+		c.Assert(i, qt.Equals, -1)
+		i64, err = cell.Int64()
+		c.Assert(err, qt.IsNil)
+		c.Assert(i64, qt.Equals, int64(-1))
+		cell.SetInt64(math.MaxInt64)
+		i64, err = cell.Int64()
+		c.Assert(err, qt.IsNil)
+		c.Assert(i64, qt.Equals, int64(math.MaxInt64))
+		cell.SetFloat(1e19)
+		_, err = cell.Int64()
+		c.Assert(errors.Is(err, ErrCellType), qt.Equals, true)
+
+		when := time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC)
+		cell.SetDateTime(when)
+		t, err := cell.Time()
+		c.Assert(err, qt.IsNil)
+		c.Assert(t, qt.Equals, when)
+	})
+
+	csRunO(c, "TestSetFormulaWithCachedValue", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		number := row.AddCell()
+		number.NumFmt = "0.00"
+		number.SetFormulaWithCachedValue("1.5*2", 3.0)
+		text := row.AddCell()
+		text.SetFormulaWithCachedValue(`"a"&"b"`, "ab")
+		boolean := row.AddCell()
+		boolean.SetFormulaWithCachedValue("1>0", true)
+		none := row.AddCell()
+		none.SetValue(7)
+		none.SetFormulaWithCachedValue("A1", nil)
+
+		c.Assert(number.Value, qt.Equals, "3")
+		c.Assert(number.NumFmt, qt.Equals, "0.00")
+		c.Assert(text.Type(), qt.Equals, CellTypeStringFormula)
+		c.Assert(boolean.Type(), qt.Equals, CellTypeBool)
+		c.Assert(none.Value, qt.Equals, "")
+
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<f>1.5*2</f><v>3</v></c>`)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<c r="B1" t="str"><f>&#34;a&#34;&amp;&#34;b&#34;</f><v>ab</v></c>`)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<c r="C1" t="b"><f>1&gt;0</f><v>1</v></c>`)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<c r="D1"><f>A1</f></c>`)
+
+		b, err := f.Bytes()
+		c.Assert(err, qt.IsNil)
+		f, err = OpenBinary(b, option)
+		c.Assert(err, qt.IsNil)
+		row, err = f.Sheet["Test1"].Row(0)
+		c.Assert(err, qt.IsNil)
+		for i, want := range []struct{ formula, value string }{{"1.5*2", "3.00"}, {`"a"&"b"`, "ab"}, {"1>0", "TRUE"}} {
+			cell := row.GetCell(i)
+			c.Assert(cell.Formula(), qt.Equals, want.formula)
+			value, err := cell.FormattedValue()
+			c.Assert(err, qt.IsNil)
+			c.Assert(value, qt.Equals, want.value)
+		}
+	})
+
+	c.Run("TestParseCellRef", func(c *qt.C) {
+		for _, want := range []CellRef{
+			{Col: 0, Row: 0},
+			{Col: 27, Row: 6, FixedCol: true},
+			{Col: 2, Row: 99, FixedRow: true},
+			{Col: Excel2006MaxColIndex, Row: Excel2006MaxRowIndex, FixedCol: true, FixedRow: true},
+		} {
+			got, err := ParseCellRef(want.String())
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.Equals, want)
+		}
+		ref, err := ParseCellRef("ab7")
+		c.Assert(err, qt.IsNil)
+		c.Assert(ref, qt.Equals, CellRef{Col: 27, Row: 6})
+		_, err = ParseCellRef("A$")
+		c.Assert(err, qt.ErrorMatches, `invalid cell reference "A\$"`)
+		_, err = ParseCellRef("ZZZ1")
+		c.Assert(err, qt.ErrorMatches, `cell reference "ZZZ1" is beyond the last cell of a sheet`)
+	})
+
+	csRunO(c, "TestSetStyleSharesXfs", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, err := f.AddSheet("Sheet1")
+		c.Assert(err, qt.IsNil)
+		shared := NewStyle()
+		shared.Font.Bold = true
+		for i := 0; i < 1000; i++ {
+			row := sheet.AddRow()
+			row.AddCell().SetStyle(shared)
+			equal := NewStyle()
+			equal.Font.Bold = true
+			cell := row.AddCell()
+			cell.SetStyle(equal)
+			cell.SetFloatWithFormat(1.5, "0.00")
+		}
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		// The default xf, and the bold style with and without the
+		// number format.
+		c.Assert(f.styles.CellXfs.Count, qt.Equals, 3)
+		c.Assert(f.styles.Fonts.Count, qt.Equals, 2)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<c r="A1000" s="1"`)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<c r="B1000" s="2"`)
+
+		// Saving again picks up changes made in between.
+		shared.Font.Italic = true
+		_, err = f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.styles.CellXfs.Count, qt.Equals, 3)
+		c.Assert(f.styles.Fonts.Count, qt.Equals, 3)
+	})
 }
 
 // formattedValueChecker removes all the boilerplate for testing Cell.FormattedValue
@@ -844,3 +1252,19 @@ func cellsFormattedValueEquals(t *testing.T, cell *Cell, expected string) {
 		t.Errorf("Expected cell.FormattedValue() to be %v, got %v", expected, val)
 	}
 }
+
+func BenchmarkSetValue(b *testing.B) {
+	cell := &Cell{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cell.SetValue(int64(i))
+	}
+}
+
+func BenchmarkSetInt64(b *testing.B) {
+	cell := &Cell{}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cell.SetInt64(int64(i))
+	}
+}
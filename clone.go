@@ -0,0 +1,162 @@
+package xlsx
+
+import (
+	"errors"
+	"fmt"
+)
+
+// CloneSheet adds a copy of the sheet src, named newName, to the end of
+// the file, such as to fill in a copy of a template sheet for each of a
+// number of records. Everything about src is copied: its rows and
+// cells, with their styles, merges, comments and data validations, its
+// column widths and styles, and its settings, such as its views, tab
+// color, visibility, auto filter, data validations, conditional
+// formats, images and protection. Nothing is shared with src, so either
+// can be changed without changing the other.
+//
+// The tables of src are copied under new names, which are their names
+// followed by the first number from 2 that makes them unique. Formulas
+// are copied as they are, so that they keep referring to the same
+// sheets and tables as in src. The name newName must be a valid sheet
+// name not already in the file, as for AddSheet.
+func (f *File) CloneSheet(src *Sheet, newName string) (*Sheet, error) {
+	if src == nil {
+		return nil, errors.New("no sheet to clone")
+	}
+	constructor := f.cellStoreConstructor
+	if constructor == nil {
+		constructor = NewMemoryCellStore
+	}
+	dst, err := f.AddSheetWithCellStore(newName, constructor)
+	if err != nil {
+		return nil, err
+	}
+	if err := src.cloneTo(dst); err != nil {
+		delete(f.Sheet, newName)
+		f.Sheets = f.Sheets[:len(f.Sheets)-1]
+		dst.Close()
+		return nil, err
+	}
+	return dst, nil
+}
+
+// cloneTo copies the rows, columns and settings of s to dst, a new
+// sheet.
+func (s *Sheet) cloneTo(dst *Sheet) error {
+	styles := make(map[*Style]*Style)
+	err := s.ForEachRow(func(row *Row) error {
+		return dst.cellStore.WriteRow(row.clone(dst, styles))
+	})
+	if err != nil {
+		return err
+	}
+	dst.MaxRow, dst.MaxCol = s.MaxRow, s.MaxCol
+
+	if s.Cols != nil {
+		s.Cols.ForEach(func(_ int, col *Col) {
+			clone := col.copyToRange(col.Min, col.Max)
+			clone.style = cloneStyle(col.style, styles)
+			dst.Cols.Add(clone)
+		})
+	}
+	dst.Hidden, dst.veryHidden, dst.TabColor = s.Hidden, s.veryHidden, s.TabColor
+	dst.SheetFormat = s.SheetFormat
+	for _, view := range s.SheetViews {
+		if view.Pane != nil {
+			pane := *view.Pane
+			view.Pane = &pane
+		}
+		dst.SheetViews = append(dst.SheetViews, view)
+	}
+	if s.AutoFilter != nil {
+		af := *s.AutoFilter
+		dst.AutoFilter = &af
+	}
+	dst.Relations = append([]Relation(nil), s.Relations...)
+	for _, dv := range s.DataValidations {
+		clone := *dv
+		dst.DataValidations = append(dst.DataValidations, &clone)
+	}
+	for _, cf := range s.ConditionalFormats {
+		cf.Rule.Formulas = append([]string(nil), cf.Rule.Formulas...)
+		cf.Rule.Colors = append([]string(nil), cf.Rule.Colors...)
+		dst.ConditionalFormats = append(dst.ConditionalFormats, cf)
+	}
+	for _, img := range s.Images {
+		img.Data = append([]byte(nil), img.Data...)
+		dst.Images = append(dst.Images, img)
+	}
+	for _, t := range s.Tables {
+		t.Columns = append([]string(nil), t.Columns...)
+		name := t.Name
+		for n := 2; dst.tableNameTaken(t.Name); n++ {
+			t.Name = fmt.Sprintf("%s%d", name, n)
+		}
+		dst.Tables = append(dst.Tables, t)
+	}
+	if s.protection != nil {
+		protection := *s.protection
+		dst.protection = &protection
+	}
+	return nil
+}
+
+// cloneStyle returns a copy of style, which is made once for each style
+// and kept in styles, or nil if style is nil.
+func cloneStyle(style *Style, styles map[*Style]*Style) *Style {
+	if style == nil {
+		return nil
+	}
+	if clone, ok := styles[style]; ok {
+		return clone
+	}
+	clone := *style
+	if style.NamedStyleIndex != nil {
+		index := *style.NamedStyleIndex
+		clone.NamedStyleIndex = &index
+	}
+	styles[style] = &clone
+	return &clone
+}
+
+// clone returns a copy of the row, and of its cells, for the sheet s,
+// with the styles of the cells copied by cloneStyle.
+func (r *Row) clone(s *Sheet, styles map[*Style]*Style) *Row {
+	clone := *r
+	clone.Sheet = s
+	clone.cells = make([]*Cell, len(r.cells))
+	for i, cell := range r.cells {
+		if cell != nil {
+			clone.cells[i] = cell.clone(&clone, styles)
+		}
+	}
+	return &clone
+}
+
+// clone returns a copy of the cell for the row r, with its style copied
+// by cloneStyle.
+func (c *Cell) clone(r *Row, styles map[*Style]*Style) *Cell {
+	clone := *c
+	clone.Row = r
+	clone.style = cloneStyle(c.style, styles)
+	clone.parsedNumFmt = nil
+	if c.RichText != nil {
+		clone.RichText = make([]RichTextRun, len(c.RichText))
+		for i, run := range c.RichText {
+			if run.Font != nil {
+				font := *run.Font
+				if font.Color != nil {
+					color := *font.Color
+					font.Color = &color
+				}
+				run.Font = &font
+			}
+			clone.RichText[i] = run
+		}
+	}
+	if c.DataValidation != nil {
+		dv := *c.DataValidation
+		clone.DataValidation = &dv
+	}
+	return &clone
+}
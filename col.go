@@ -4,6 +4,8 @@ package xlsx
 const ColWidth = 9.5
 const Excel2006MaxRowCount = 1048576
 const Excel2006MaxRowIndex = Excel2006MaxRowCount - 1
+const Excel2006MaxColCount = 16384
+const Excel2006MaxColIndex = Excel2006MaxColCount - 1
 
 type Col struct {
 	Min          int
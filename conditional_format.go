@@ -0,0 +1,253 @@
+package xlsx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConditionalRuleType is the kind of a ConditionalRule.
+type ConditionalRuleType int
+
+// Conditional formatting rule types
+const (
+	// ConditionalCellValue formats the cells whose value compares
+	// with the rule's formulas as given by its operator.
+	ConditionalCellValue ConditionalRuleType = iota
+	// ConditionalDataBar draws a bar in each cell, as long as the
+	// cell's value is against the range of values.
+	ConditionalDataBar
+	// ConditionalColorScale fills each cell with a colour between the
+	// two or three colours of the rule, by where the cell's value falls
+	// in the range of values.
+	ConditionalColorScale
+)
+
+// ConditionalOperator compares the value of a cell in a
+// ConditionalCellValue rule.
+type ConditionalOperator int
+
+// Conditional formatting operators
+const (
+	ConditionalOperatorGreaterThan ConditionalOperator = iota
+	ConditionalOperatorGreaterThanOrEqual
+	ConditionalOperatorLessThan
+	ConditionalOperatorLessThanOrEqual
+	ConditionalOperatorEqual
+	ConditionalOperatorNotEqual
+	ConditionalOperatorBetween
+	ConditionalOperatorNotBetween
+)
+
+var conditionalOperators = map[ConditionalOperator]string{
+	ConditionalOperatorGreaterThan:        "greaterThan",
+	ConditionalOperatorGreaterThanOrEqual: "greaterThanOrEqual",
+	ConditionalOperatorLessThan:           "lessThan",
+	ConditionalOperatorLessThanOrEqual:    "lessThanOrEqual",
+	ConditionalOperatorEqual:              "equal",
+	ConditionalOperatorNotEqual:           "notEqual",
+	ConditionalOperatorBetween:            "between",
+	ConditionalOperatorNotBetween:         "notBetween",
+}
+
+// ConditionalRule is a conditional formatting rule, which changes how
+// cells look according to their values. Colours are given as ARGB hex,
+// as in "FFFF0000" for red.
+type ConditionalRule struct {
+	Type ConditionalRuleType
+	// Operator and Formulas give the condition of a
+	// ConditionalCellValue rule. Formulas hold one value to compare
+	// with, or two for the between operators, each written as in a
+	// cell formula: "100", "$B$1" or "\"done\"".
+	Operator ConditionalOperator
+	Formulas []string
+	// FontColor, FillColor and Bold give the format a
+	// ConditionalCellValue rule applies to the cells that meet its
+	// condition. An empty colour is left as it is.
+	FontColor string
+	FillColor string
+	Bold      bool
+	// Colors holds the colour of the bars of a ConditionalDataBar
+	// rule, or the two or three colours of a ConditionalColorScale
+	// rule, for the lowest value, the 50th percentile if there are
+	// three, and the highest value.
+	Colors []string
+}
+
+// ConditionalFormat is a ConditionalRule applied to the cells of the
+// range Ref, such as "B2:B100".
+type ConditionalFormat struct {
+	Ref  string
+	Rule ConditionalRule
+}
+
+// AddConditionalFormat applies the conditional formatting rule 'rule'
+// to the cells of the range rangeRef, which is a single cell, as in
+// "A1", a range, as in "B2:D20", or several of them separated by
+// spaces. The rules of a sheet take priority in the order they are
+// added. An error is returned, and nothing added, if rangeRef is
+// malformed or the rule is incomplete.
+func (s *Sheet) AddConditionalFormat(rangeRef string, rule ConditionalRule) error {
+	if err := checkRangeRef(rangeRef); err != nil {
+		return err
+	}
+	switch rule.Type {
+	case ConditionalCellValue:
+		want := 1
+		if rule.Operator == ConditionalOperatorBetween || rule.Operator == ConditionalOperatorNotBetween {
+			want = 2
+		}
+		if _, ok := conditionalOperators[rule.Operator]; !ok {
+			return fmt.Errorf("conditional format: unknown operator %d", rule.Operator)
+		}
+		if len(rule.Formulas) != want {
+			return fmt.Errorf("conditional format: operator %s needs %d formulas, got %d", conditionalOperators[rule.Operator], want, len(rule.Formulas))
+		}
+	case ConditionalDataBar:
+		if len(rule.Colors) != 1 {
+			return fmt.Errorf("conditional format: a data bar needs 1 color, got %d", len(rule.Colors))
+		}
+	case ConditionalColorScale:
+		if len(rule.Colors) != 2 && len(rule.Colors) != 3 {
+			return fmt.Errorf("conditional format: a color scale needs 2 or 3 colors, got %d", len(rule.Colors))
+		}
+	default:
+		return fmt.Errorf("conditional format: unknown rule type %d", rule.Type)
+	}
+	s.ConditionalFormats = append(s.ConditionalFormats, ConditionalFormat{Ref: rangeRef, Rule: rule})
+	return nil
+}
+
+// checkRangeRef returns an error unless ref is a list of cell
+// references and ranges separated by spaces.
+func checkRangeRef(ref string) error {
+	parts := strings.Fields(ref)
+	if len(parts) == 0 {
+		return fmt.Errorf("invalid range %q", ref)
+	}
+	for _, part := range parts {
+		for _, cell := range strings.SplitN(part, cellRangeChar, 2) {
+			if _, _, err := GetCoordsFromCellIDString(cell); err != nil {
+				return fmt.Errorf("invalid range %q: %w", ref, err)
+			}
+		}
+	}
+	return nil
+}
+
+// makeConditionalFormatting adds the conditional formats of the sheet
+// to worksheet, with the formats of cell value rules added to styles.
+func (s *Sheet) makeConditionalFormatting(worksheet *xlsxWorksheet, styles *xlsxStyleSheet) {
+	for i, cf := range s.ConditionalFormats {
+		rule := cf.Rule
+		xRule := xlsxCfRule{Priority: i + 1}
+		switch rule.Type {
+		case ConditionalCellValue:
+			xRule.Type = "cellIs"
+			xRule.Operator = conditionalOperators[rule.Operator]
+			xRule.Formula = rule.Formulas
+			dxfId := styles.addDxf(rule.makeDxf())
+			xRule.DxfId = &dxfId
+		case ConditionalDataBar:
+			xRule.Type = "dataBar"
+			xRule.DataBar = &xlsxDataBar{
+				Cfvo:  []xlsxCfvo{{Type: "min"}, {Type: "max"}},
+				Color: []xlsxColor{{RGB: rule.Colors[0]}},
+			}
+		case ConditionalColorScale:
+			xRule.Type = "colorScale"
+			scale := &xlsxColorScale{Cfvo: []xlsxCfvo{{Type: "min"}}}
+			if len(rule.Colors) == 3 {
+				scale.Cfvo = append(scale.Cfvo, xlsxCfvo{Type: "percentile", Val: "50"})
+			}
+			scale.Cfvo = append(scale.Cfvo, xlsxCfvo{Type: "max"})
+			for _, color := range rule.Colors {
+				scale.Color = append(scale.Color, xlsxColor{RGB: color})
+			}
+			xRule.ColorScale = scale
+		}
+		worksheet.ConditionalFormatting = append(worksheet.ConditionalFormatting, xlsxConditionalFormatting{
+			Sqref:  cf.Ref,
+			CfRule: []xlsxCfRule{xRule},
+		})
+	}
+}
+
+// makeDxf returns the differential format a cell value rule applies.
+func (rule ConditionalRule) makeDxf() xlsxDxf {
+	var dxf xlsxDxf
+	if rule.FontColor != "" || rule.Bold {
+		dxf.Font = &xlsxFont{Color: xlsxColor{RGB: rule.FontColor}}
+		if rule.Bold {
+			dxf.Font.B = &xlsxVal{}
+		}
+	}
+	if rule.FillColor != "" {
+		dxf.Fill = &xlsxFill{PatternFill: xlsxPatternFill{
+			PatternType: "solid",
+			FgColor:     xlsxColor{RGB: rule.FillColor},
+			BgColor:     xlsxColor{RGB: rule.FillColor},
+		}}
+	}
+	return dxf
+}
+
+// readConditionalFormatting returns the conditional formats of
+// worksheet that are of a type ConditionalRule can hold, taking the
+// formats of cell value rules from styles, which may be nil. Rules of
+// other types are left out.
+func readConditionalFormatting(worksheet *xlsxWorksheet, styles *xlsxStyleSheet) []ConditionalFormat {
+	var formats []ConditionalFormat
+	for _, xcf := range worksheet.ConditionalFormatting {
+		for _, xRule := range xcf.CfRule {
+			var rule ConditionalRule
+			switch {
+			case xRule.Type == "cellIs":
+				op, ok := conditionalOperatorByName(xRule.Operator)
+				if !ok {
+					continue
+				}
+				rule.Type = ConditionalCellValue
+				rule.Operator = op
+				rule.Formulas = xRule.Formula
+				if xRule.DxfId != nil && styles != nil && *xRule.DxfId >= 0 && *xRule.DxfId < len(styles.DXfs.Dxf) {
+					dxf := styles.DXfs.Dxf[*xRule.DxfId]
+					if dxf.Font != nil {
+						rule.FontColor = dxf.Font.Color.RGB
+						rule.Bold = dxf.Font.B != nil
+					}
+					if dxf.Fill != nil {
+						rule.FillColor = dxf.Fill.PatternFill.BgColor.RGB
+						if rule.FillColor == "" {
+							rule.FillColor = dxf.Fill.PatternFill.FgColor.RGB
+						}
+					}
+				}
+			case xRule.Type == "dataBar" && xRule.DataBar != nil:
+				rule.Type = ConditionalDataBar
+				for _, color := range xRule.DataBar.Color {
+					rule.Colors = append(rule.Colors, color.RGB)
+				}
+			case xRule.Type == "colorScale" && xRule.ColorScale != nil:
+				rule.Type = ConditionalColorScale
+				for _, color := range xRule.ColorScale.Color {
+					rule.Colors = append(rule.Colors, color.RGB)
+				}
+			default:
+				continue
+			}
+			formats = append(formats, ConditionalFormat{Ref: xcf.Sqref, Rule: rule})
+		}
+	}
+	return formats
+}
+
+// conditionalOperatorByName returns the ConditionalOperator saved as
+// name.
+func conditionalOperatorByName(name string) (ConditionalOperator, bool) {
+	for op, opName := range conditionalOperators {
+		if opName == name {
+			return op, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,216 @@
+package xlsx
+
+import (
+	"bufio"
+	"encoding/csv"
+	"io"
+	"strings"
+	"time"
+)
+
+// CSVOptions control how WriteCSV writes a sheet as CSV, and how
+// ReadCSV reads CSV into a sheet.
+type CSVOptions struct {
+	// Comma is the field delimiter. When it is zero, ',' is used.
+	Comma rune
+	// QuoteAll quotes every field. By default only fields that need it,
+	// because they hold the delimiter, a quote, a line break or
+	// leading white space, are quoted.
+	QuoteAll bool
+	// UseCRLF ends lines with \r\n instead of \n.
+	UseCRLF bool
+	// SkipHeader makes WriteCSV leave out the first row of the sheet,
+	// such as one written by WriteStructHeader, and ReadCSV leave out
+	// the first record.
+	SkipHeader bool
+	// Header makes ReadCSV take the first record as a header, whose
+	// fields are kept as text whatever they look like.
+	Header bool
+	// AllText makes ReadCSV keep every field as text, rather than
+	// reading those that look like numbers, booleans or dates as such.
+	AllText bool
+	// TextColumns are the columns, counted from zero, whose fields
+	// ReadCSV keeps as text, such as columns of zip codes.
+	TextColumns []int
+	// DateLayouts are the layouts, as for time.Parse, that ReadCSV
+	// tries in turn on a field to read it as a date. When empty,
+	// "2006-01-02", "2006-01-02 15:04:05" and time.RFC3339 are tried.
+	DateLayouts []string
+}
+
+var defaultCSVDateLayouts = []string{"2006-01-02", "2006-01-02 15:04:05", time.RFC3339}
+
+// ReadCSV reads CSV from r, adding a row to the end of sheet s for
+// each record, with a cell for each field. Unless opts say otherwise,
+// a field that looks like a number, a boolean or a date is read as
+// one: numbers are set with SetValue, TRUE and FALSE, in any case, with
+// SetBool, and dates with SetDate or, if they have a time of day,
+// SetValue. A number with a leading zero, such as "007", or with more
+// digits than a cell holds is kept as text, as are empty fields.
+func (s *Sheet) ReadCSV(r io.Reader, opts CSVOptions) error {
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.FieldsPerRecord = -1
+	text := make(map[int]bool, len(opts.TextColumns))
+	for _, col := range opts.TextColumns {
+		text[col] = true
+	}
+	layouts := opts.DateLayouts
+	if len(layouts) == 0 {
+		layouts = defaultCSVDateLayouts
+	}
+	for first := true; ; first = false {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if first && opts.SkipHeader {
+			continue
+		}
+		row := s.AddRow()
+		for i, field := range record {
+			cell := row.AddCell()
+			if opts.AllText || text[i] || (first && opts.Header) {
+				cell.SetString(field)
+				continue
+			}
+			setInferredValue(cell, field, layouts)
+		}
+		if len(record) > s.MaxCol {
+			s.MaxCol = len(record)
+		}
+	}
+}
+
+// setInferredValue sets cell to the number, boolean or date that the
+// text field looks like, trying the date 'layouts', or to field itself.
+func setInferredValue(cell *Cell, field string, layouts []string) {
+	if f, ok := parseDecimal(field); ok && !hasLeadingZero(field) {
+		cell.SetValue(f)
+		return
+	}
+	switch {
+	case strings.EqualFold(field, "true"):
+		cell.SetBool(true)
+		return
+	case strings.EqualFold(field, "false"):
+		cell.SetBool(false)
+		return
+	}
+	if field != "" {
+		for _, layout := range layouts {
+			t, err := time.Parse(layout, field)
+			if err != nil {
+				continue
+			}
+			if t.Equal(t.Truncate(24 * time.Hour)) {
+				cell.SetDate(t)
+			} else {
+				cell.SetValue(t)
+			}
+			return
+		}
+	}
+	cell.SetString(field)
+}
+
+// hasLeadingZero reports whether the number s starts with a zero that
+// is not followed by a decimal point, as in codes such as "007".
+func hasLeadingZero(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	return len(s) > 1 && s[0] == '0' && s[1] != '.'
+}
+
+// WriteCSV writes the rows of sheet s to w as CSV, with a record for
+// each row and a field for each cell. A cell is written as it is shown,
+// with its number format applied, so that a date comes out as a date;
+// a formula is written as the value it had when it was last
+// calculated. Empty cells and rows give empty fields and records, and
+// each record has as many fields as the widest row.
+func (s *Sheet) WriteCSV(w io.Writer, opts CSVOptions) error {
+	comma := opts.Comma
+	if comma == 0 {
+		comma = ','
+	}
+	newline := "\n"
+	if opts.UseCRLF {
+		newline = "\r\n"
+	}
+
+	width := s.MaxCol
+	err := s.ForEachRow(func(r *Row) error {
+		if r.cellCount > width {
+			width = r.cellCount
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(w)
+	next := 0
+	if opts.SkipHeader {
+		next = 1
+	}
+	record := make([]string, width)
+	writeRecord := func() {
+		for i, field := range record {
+			if i > 0 {
+				bw.WriteRune(comma)
+			}
+			writeCSVField(bw, field, comma, opts.QuoteAll)
+		}
+		bw.WriteString(newline)
+	}
+	err = s.ForEachRow(func(r *Row) error {
+		if r.num < next {
+			return nil
+		}
+		for i := range record {
+			record[i] = ""
+		}
+		// Rows missing from the sheet are empty records.
+		for ; next < r.num; next++ {
+			writeRecord()
+		}
+		for i, cell := range r.cells {
+			if cell == nil || i >= width {
+				continue
+			}
+			value, err := cell.FormattedValue()
+			if err != nil {
+				value = cell.Value
+			}
+			record[i] = value
+		}
+		writeRecord()
+		next = r.num + 1
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// writeCSVField writes field to w, quoted if quoteAll is set or if it
+// has to be, with any quotes inside it doubled.
+func writeCSVField(w *bufio.Writer, field string, comma rune, quoteAll bool) {
+	quote := quoteAll ||
+		strings.ContainsRune(field, comma) ||
+		strings.ContainsAny(field, "\"\r\n") ||
+		strings.HasPrefix(field, " ") || strings.HasPrefix(field, "\t")
+	if !quote {
+		w.WriteString(field)
+		return
+	}
+	w.WriteByte('"')
+	w.WriteString(strings.Replace(field, `"`, `""`, -1))
+	w.WriteByte('"')
+}
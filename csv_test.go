@@ -0,0 +1,158 @@
+package xlsx
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestWriteCSV(t *testing.T) {
+	c := qt.New(t)
+
+	type item struct {
+		Name  string    `xlsx:"0"`
+		Price float64   `xlsx:"1,'#,##0.00'"`
+		Sold  time.Time `xlsx:"2,yyyy-mm-dd"`
+		Stock bool      `xlsx:"3"`
+	}
+	makeSheet := func(option FileOption) *Sheet {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		sheet.AddRow().WriteStructHeader(item{})
+		_, err := sheet.WriteStructs([]item{
+			{"Widget, large", 1234.5, time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC), true},
+			{`Say "hi"`, 2, time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), false},
+		})
+		c.Assert(err, qt.IsNil)
+		sheet.AddRow()
+		row := sheet.AddRow()
+		cell := row.AddCell()
+		cell.SetFormula("SUM(B2:B3)")
+		cell.Value = "1236.5"
+		return sheet
+	}
+
+	csRunO(c, "Default", func(c *qt.C, option FileOption) {
+		var buf bytes.Buffer
+		c.Assert(makeSheet(option).WriteCSV(&buf, CSVOptions{}), qt.IsNil)
+		c.Assert(buf.String(), qt.Equals, "Name,Price,Sold,Stock\n"+
+			"\"Widget, large\",1234.50,2020-03-04,TRUE\n"+
+			"\"Say \"\"hi\"\"\",2.00,2021-01-02,FALSE\n"+
+			",,,\n"+
+			"1236.5,,,\n")
+
+		records, err := csv.NewReader(&buf).ReadAll()
+		c.Assert(err, qt.IsNil)
+		c.Assert(records, qt.HasLen, 5)
+		c.Assert(records[2][0], qt.Equals, `Say "hi"`)
+	})
+
+	csRunO(c, "Options", func(c *qt.C, option FileOption) {
+		var buf bytes.Buffer
+		err := makeSheet(option).WriteCSV(&buf, CSVOptions{Comma: ';', QuoteAll: true, UseCRLF: true, SkipHeader: true})
+		c.Assert(err, qt.IsNil)
+		c.Assert(buf.String(), qt.Equals, "\"Widget, large\";\"1234.50\";\"2020-03-04\";\"TRUE\"\r\n"+
+			"\"Say \"\"hi\"\"\";\"2.00\";\"2021-01-02\";\"FALSE\"\r\n"+
+			"\"\";\"\";\"\";\"\"\r\n"+
+			"\"1236.5\";\"\";\"\";\"\"\r\n")
+	})
+}
+
+func TestReadCSV(t *testing.T) {
+	c := qt.New(t)
+
+	const input = "Name;Zip;Price;Stock;Sold\n" +
+		"Widget;007;1234.5;true;2020-03-04\n" +
+		"Gadget;10115;-2;FALSE;2021-01-02 15:04:05\n" +
+		"\"Left; right\";12345678901234567890;0.25;maybe;\n"
+
+	csRunO(c, "Infer", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		err := sheet.ReadCSV(strings.NewReader(input), CSVOptions{Comma: ';', Header: true, TextColumns: []int{1}})
+		c.Assert(err, qt.IsNil)
+		c.Assert(sheet.MaxRow, qt.Equals, 4)
+		c.Assert(sheet.MaxCol, qt.Equals, 5)
+
+		cellAt := func(col, row int) *Cell {
+			cell, err := sheet.Cell(row, col)
+			c.Assert(err, qt.IsNil)
+			return cell
+		}
+		for col := 0; col < 5; col++ {
+			c.Assert(cellAt(col, 0).Type(), qt.Equals, CellTypeString)
+		}
+		c.Assert(cellAt(2, 0).Value, qt.Equals, "Price")
+
+		c.Assert(cellAt(0, 1).Value, qt.Equals, "Widget")
+		c.Assert(cellAt(1, 1).Type(), qt.Equals, CellTypeString)
+		c.Assert(cellAt(1, 1).Value, qt.Equals, "007")
+		c.Assert(cellAt(1, 2).Type(), qt.Equals, CellTypeString)
+		price, err := cellAt(2, 1).Float()
+		c.Assert(err, qt.IsNil)
+		c.Assert(price, qt.Equals, 1234.5)
+		c.Assert(cellAt(2, 2).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(cellAt(3, 1).Type(), qt.Equals, CellTypeBool)
+		c.Assert(cellAt(3, 1).Bool(), qt.Equals, true)
+		c.Assert(cellAt(3, 2).Type(), qt.Equals, CellTypeBool)
+		c.Assert(cellAt(3, 2).Bool(), qt.Equals, false)
+
+		sold, err := cellAt(4, 1).GetTime(false)
+		c.Assert(err, qt.IsNil)
+		c.Assert(sold.Equal(time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC)), qt.Equals, true)
+		value, err := cellAt(4, 1).FormattedValue()
+		c.Assert(err, qt.IsNil)
+		c.Assert(value, qt.Equals, "03-04-20")
+		sold, err = cellAt(4, 2).GetTime(false)
+		c.Assert(err, qt.IsNil)
+		c.Assert(sold.Round(time.Second).Equal(time.Date(2021, 1, 2, 15, 4, 5, 0, time.UTC)), qt.Equals, true)
+
+		c.Assert(cellAt(0, 3).Value, qt.Equals, "Left; right")
+		c.Assert(cellAt(1, 3).Value, qt.Equals, "12345678901234567890")
+		c.Assert(cellAt(2, 3).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(cellAt(3, 3).Type(), qt.Equals, CellTypeString)
+		c.Assert(cellAt(3, 3).Value, qt.Equals, "maybe")
+		c.Assert(cellAt(4, 3).Value, qt.Equals, "")
+
+		// Go's digit separators don't make a number.
+		sheet, _ = f.AddSheet("Test2")
+		c.Assert(sheet.ReadCSV(strings.NewReader("1_000,12_34,1e3\n"), CSVOptions{}), qt.IsNil)
+		c.Assert(cellAt(0, 0).Type(), qt.Equals, CellTypeString)
+		c.Assert(cellAt(0, 0).Value, qt.Equals, "1_000")
+		c.Assert(cellAt(1, 0).Type(), qt.Equals, CellTypeString)
+		c.Assert(cellAt(1, 0).Value, qt.Equals, "12_34")
+		c.Assert(cellAt(2, 0).Type(), qt.Equals, CellTypeNumeric)
+	})
+
+	csRunO(c, "AllText", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		err := sheet.ReadCSV(strings.NewReader(input), CSVOptions{Comma: ';', SkipHeader: true, AllText: true})
+		c.Assert(err, qt.IsNil)
+		c.Assert(sheet.MaxRow, qt.Equals, 3)
+		cell, err := sheet.Cell(0, 2)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.Type(), qt.Equals, CellTypeString)
+		c.Assert(cell.Value, qt.Equals, "1234.5")
+	})
+
+	csRunO(c, "RoundTrip", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		const input = "a,1,TRUE\n\"b, c\",2.5,FALSE\n"
+		c.Assert(sheet.ReadCSV(strings.NewReader(input), CSVOptions{}), qt.IsNil)
+		var buf bytes.Buffer
+		c.Assert(sheet.WriteCSV(&buf, CSVOptions{}), qt.IsNil)
+		c.Assert(buf.String(), qt.Equals, input)
+	})
+
+	c.Run("Malformed", func(c *qt.C) {
+		sheet, _ := NewFile().AddSheet("Test1")
+		err := sheet.ReadCSV(strings.NewReader("a,\"b\n"), CSVOptions{})
+		c.Assert(err, qt.Not(qt.IsNil))
+	})
+}
@@ -0,0 +1,129 @@
+package xlsx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxDefinedNameLength is the longest name Excel allows for a defined
+// name.
+const maxDefinedNameLength = 255
+
+var (
+	// definedNameRegexp matches the names Excel allows for defined
+	// names, other than those that look like cell references.
+	definedNameRegexp = regexp.MustCompile(`^[\p{L}_\\][\p{L}\p{N}_.\\]*$`)
+	// r1c1RefRegexp matches the references in R1C1 style, such as "R2C3",
+	// "R2" and "C", that can't be used as defined names either.
+	r1c1RefRegexp = regexp.MustCompile(`^(R[0-9]*C?[0-9]*|C[0-9]*)$`)
+)
+
+// SetDefinedName gives the name 'name' to refersTo, a reference or
+// formula such as "Sheet1!$A$1:$B$10", which formulas can then use in
+// its place. The name is scoped to the sheet named scope, or to the
+// whole workbook when scope is empty, and replaces any definition of
+// the same name, compared without regard to case, in that scope.
+//
+// Names start with a letter, an underscore or a backslash, followed by
+// letters, digits, underscores, periods and backslashes, and can't look
+// like a cell reference, such as "AB12" or "R1C1". An error is
+// returned, and nothing defined, if name isn't such a name or is the
+// name of a table, refersTo is empty, or there is no sheet named scope.
+func (f *File) SetDefinedName(name, refersTo string, scope string) error {
+	if err := checkDefinedName("defined name", name); err != nil {
+		return err
+	}
+	refersTo = strings.TrimPrefix(refersTo, "=")
+	if refersTo == "" {
+		return fmt.Errorf("defined name %q refers to nothing", name)
+	}
+	localSheetID, err := f.definedNameScope(scope)
+	if err != nil {
+		return err
+	}
+	for _, sheet := range f.Sheets {
+		for _, t := range sheet.Tables {
+			if strings.EqualFold(t.Name, name) {
+				return fmt.Errorf("defined name %q is the name of a table", name)
+			}
+		}
+	}
+	if dn := f.findDefinedName(name, localSheetID); dn != nil {
+		dn.Data = refersTo
+		return nil
+	}
+	f.DefinedNames = append(f.DefinedNames, &xlsxDefinedName{
+		Name:         name,
+		Data:         refersTo,
+		LocalSheetID: localSheetID,
+	})
+	return nil
+}
+
+// DefinedName returns what the name 'name' refers to in scope, which is
+// a sheet name or empty for the workbook, as set by SetDefinedName or
+// read from a file. The boolean is false if the name isn't defined in
+// exactly that scope.
+func (f *File) DefinedName(name, scope string) (string, bool) {
+	localSheetID, err := f.definedNameScope(scope)
+	if err != nil {
+		return "", false
+	}
+	dn := f.findDefinedName(name, localSheetID)
+	if dn == nil {
+		return "", false
+	}
+	return dn.Data, true
+}
+
+// checkDefinedName returns an error if name can't be used as a defined
+// name, or as the name of the kind of thing named by kind that follows
+// the same rules, such as a table.
+func checkDefinedName(kind, name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("%s is empty", kind)
+	case len(name) > maxDefinedNameLength:
+		return fmt.Errorf("%s %q is longer than %d characters", kind, name, maxDefinedNameLength)
+	case !definedNameRegexp.MatchString(name):
+		return fmt.Errorf("invalid %s %q", kind, name)
+	}
+	upper := strings.ToUpper(name)
+	if cellRefRegexp.MatchString(upper) || r1c1RefRegexp.MatchString(upper) {
+		return fmt.Errorf("%s %q is a cell reference", kind, name)
+	}
+	return nil
+}
+
+// definedNameScope returns the localSheetId of the defined names scoped
+// to the sheet named scope, or nil for the workbook when scope is
+// empty.
+func (f *File) definedNameScope(scope string) (*int, error) {
+	if scope == "" {
+		return nil, nil
+	}
+	for i, sheet := range f.Sheets {
+		if sheet.Name == scope {
+			return &i, nil
+		}
+	}
+	return nil, fmt.Errorf("no sheet named %q to scope a defined name to", scope)
+}
+
+// findDefinedName returns the defined name 'name' of the scope
+// localSheetID, or nil if there isn't one.
+func (f *File) findDefinedName(name string, localSheetID *int) *xlsxDefinedName {
+	for _, dn := range f.DefinedNames {
+		if !strings.EqualFold(dn.Name, name) {
+			continue
+		}
+		if (dn.LocalSheetID == nil) != (localSheetID == nil) {
+			continue
+		}
+		if localSheetID == nil || *dn.LocalSheetID == *localSheetID {
+			return dn
+		}
+	}
+	return nil
+}
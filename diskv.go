@@ -88,7 +88,7 @@ func (cs *DiskVCellStore) ReadRow(key string) (*Row, error) {
 // within the persistant store.
 func (cs *DiskVCellStore) MoveRow(r *Row, index int) error {
 	oldKey := r.key()
-	r.num++
+	r.num = index
 	newKey := r.key()
 	if cs.store.Has(newKey) {
 		return fmt.Errorf("Target index for row (%d) would overwrite a row already exists", index)
@@ -517,6 +517,15 @@ func (cs *DiskVCellStore) writeStyle(s *Style) error {
 	if err = cs.writeBool(s.ApplyAlignment); err != nil {
 		return err
 	}
+	if err = cs.writeBool(s.ApplyProtection); err != nil {
+		return err
+	}
+	if err = cs.writeBool(s.Protection.Locked); err != nil {
+		return err
+	}
+	if err = cs.writeBool(s.Protection.Hidden); err != nil {
+		return err
+	}
 	if err = cs.writeEndOfRecord(); err != nil {
 		return err
 	}
@@ -550,6 +559,15 @@ func (cs *DiskVCellStore) readStyle() (*Style, error) {
 	if s.ApplyAlignment, err = cs.readBool(); err != nil {
 		return s, err
 	}
+	if s.ApplyProtection, err = cs.readBool(); err != nil {
+		return s, err
+	}
+	if s.Protection.Locked, err = cs.readBool(); err != nil {
+		return s, err
+	}
+	if s.Protection.Hidden, err = cs.readBool(); err != nil {
+		return s, err
+	}
 	if err = cs.readEndOfRecord(); err != nil {
 		return s, err
 	}
@@ -663,6 +681,9 @@ func (cs *DiskVCellStore) writeRow(r *Row) error {
 	if err = cs.writeInt(int(r.OutlineLevel)); err != nil {
 		return err
 	}
+	if err = cs.writeBool(r.Collapsed); err != nil {
+		return err
+	}
 	if err = cs.writeBool(r.isCustom); err != nil {
 		return err
 	}
@@ -741,6 +762,12 @@ func (cs *DiskVCellStore) writeCell(c *Cell) error {
 	if err = cs.writeRichText(c.RichText); err != nil {
 		return err
 	}
+	if err = cs.writeString(c.Comment.Author); err != nil {
+		return err
+	}
+	if err = cs.writeString(c.Comment.Text); err != nil {
+		return err
+	}
 	if err = cs.writeEndOfRecord(); err != nil {
 		return err
 	}
@@ -774,6 +801,10 @@ func (cs *DiskVCellStore) readRow() (*Row, error) {
 		return nil, err
 	}
 	r.OutlineLevel = uint8(outlineLevel)
+	r.Collapsed, err = cs.readBool()
+	if err != nil {
+		return nil, err
+	}
 
 	r.isCustom, err = cs.readBool()
 	if err != nil {
@@ -866,6 +897,12 @@ func (cs *DiskVCellStore) readCell() (*Cell, error) {
 	if c.RichText, err = cs.readRichText(); err != nil {
 		return c, err
 	}
+	if c.Comment.Author, err = cs.readString(); err != nil {
+		return c, err
+	}
+	if c.Comment.Text, err = cs.readString(); err != nil {
+		return c, err
+	}
 	if err = cs.readEndOfRecord(); err != nil {
 		return c, err
 	}
@@ -884,6 +921,9 @@ func (cs *DiskVCellStore) readCell() (*Cell, error) {
 
 // WriteRow writes a Row to persistant storage.
 func (cs *DiskVCellStore) WriteRow(r *Row) error {
+	if r == nil {
+		return nil
+	}
 	cs.buf.Reset()
 	err := cs.writeRow(r)
 	if err != nil {
@@ -10,14 +10,28 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 	"unicode/utf8"
 )
 
 // File is a high level structure providing a slice of Sheet structs
 // to the user.
+//
+// A File that has been read, with OpenFile and the like, can be read
+// from several goroutines at once as long as each goroutine keeps to
+// Sheets of its own: the parts the Sheets of a File share, such as the
+// shared strings and the styles, are either read in full when the File
+// is opened or guarded against concurrent use. A Sheet, its Rows and
+// its Cells can only be used from one goroutine at a time, even just
+// to read them, as reading moves the Sheet's current row. Changing a
+// File, or any of its Sheets, and writing it must be done from a
+// single goroutine.
 type File struct {
 	worksheets           map[string]*zip.File
 	worksheetRels        map[string]*zip.File
+	comments             map[string]*zip.File
+	drawings             map[string]*zip.File
+	tables               map[string]*zip.File
 	referenceTable       *RefTable
 	Date1904             bool
 	styles               *xlsxStyleSheet
@@ -27,6 +41,7 @@ type File struct {
 	DefinedNames         []*xlsxDefinedName
 	cellStoreConstructor CellStoreConstructor
 	rowLimit             int
+	timeLocation         *time.Location
 }
 
 const NoRowLimit int = -1
@@ -41,6 +56,17 @@ func RowLimit(n int) FileOption {
 	}
 }
 
+// TimeLocation sets the location whose local time the dates of the
+// file's cells are in, which is UTC if loc is nil or the option isn't
+// given. Excel keeps no time zone with its dates, so a time.Time is
+// written as its wall clock in loc, and dates are read back as times in
+// loc; the same file then gives the same times on every machine.
+func TimeLocation(loc *time.Location) FileOption {
+	return func(f *File) {
+		f.timeLocation = loc
+	}
+}
+
 // NewFile creates a new File struct. You may pass it zero, one or
 // many FileOption functions that affect the behaviour of the file.
 func NewFile(options ...FileOption) *File {
@@ -154,6 +180,37 @@ func (f *File) Write(writer io.Writer) (err error) {
 	return zipWriter.Close()
 }
 
+// WriteTo writes the File to w as xlsx, as Write does, and returns the
+// number of bytes written. It implements io.WriterTo, so that, for
+// example, io.Copy can send a File straight to an HTTP response
+// without a temporary file.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := f.Write(cw)
+	return cw.n, err
+}
+
+// Bytes returns the File as xlsx, as it would be saved.
+func (f *File) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// countingWriter is an io.Writer that counts the bytes written to w.
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
 // AddSheet Add a new Sheet, with the provided name, to a File.
 // The minimum sheet name length is 1 character. If the sheet name length is less an error is thrown.
 // The maximum sheet name length is 31 characters. If the sheet name length is exceeded an error is thrown.
@@ -207,10 +264,58 @@ func (f *File) AppendSheet(sheet Sheet, sheetName string) (*Sheet, error) {
 	return &sheet, nil
 }
 
+// filterDatabaseName is the name of the hidden defined name that some
+// applications need to find the range of the auto filter of a sheet.
+const filterDatabaseName = "_xlnm._FilterDatabase"
+
+// isFilterDatabaseName reports whether dn is the filter database name
+// of a sheet of f that has an auto filter.
+func (f *File) isFilterDatabaseName(dn *xlsxDefinedName) bool {
+	return dn.Name == filterDatabaseName && dn.LocalSheetID != nil &&
+		*dn.LocalSheetID >= 0 && *dn.LocalSheetID < len(f.Sheets) &&
+		f.Sheets[*dn.LocalSheetID].AutoFilter != nil
+}
+
+// filterDatabaseNames returns the filter database names of the ranges
+// of the auto filters of the sheets.
+func (f *File) filterDatabaseNames() []xlsxDefinedName {
+	var names []xlsxDefinedName
+	for i, sheet := range f.Sheets {
+		localSheetID := i
+		if sheet.AutoFilter == nil {
+			continue
+		}
+		minx, miny, maxx, maxy, err := parseRangeRef(sheet.AutoFilter.TopLeftCell + cellRangeChar + sheet.AutoFilter.BottomRightCell)
+		if err != nil {
+			continue
+		}
+		names = append(names, xlsxDefinedName{
+			Name:         filterDatabaseName,
+			Hidden:       true,
+			LocalSheetID: &localSheetID,
+			Data: "'" + strings.Replace(sheet.Name, "'", "''", -1) + "'" + externalSheetBangChar +
+				GetCellIDStringFromCoordsWithFixed(minx, miny, true, true) + cellRangeChar +
+				GetCellIDStringFromCoordsWithFixed(maxx, maxy, true, true),
+		})
+	}
+	return names
+}
+
+// SetDate1904 sets whether the dates of the file are counted from 1904,
+// as in workbooks made by older versions of Excel for the Mac, rather
+// than from 1900. Reading and writing times in the file's cells
+// follows the setting, which is saved with the file and taken from
+// the files that are read. Cells already holding dates keep their
+// numbers, which then stand for dates 1462 days, about four years,
+// apart, so the setting should be made before any dates are written.
+func (f *File) SetDate1904(date1904 bool) {
+	f.Date1904 = date1904
+}
+
 func (f *File) makeWorkbook() xlsxWorkbook {
 	return xlsxWorkbook{
 		FileVersion: xlsxFileVersion{AppName: "Go XLSX"},
-		WorkbookPr:  xlsxWorkbookPr{ShowObjects: "all"},
+		WorkbookPr:  xlsxWorkbookPr{ShowObjects: "all", Date1904: f.Date1904},
 		BookViews: xlsxBookViews{
 			WorkBookView: []xlsxWorkBookView{
 				{
@@ -261,6 +366,9 @@ func addRelationshipNameSpaceToWorksheet(worksheetMarshal string) string {
 	oldHyperlink := `<hyperlink id=`
 	newHyperlink := `<hyperlink r:id=`
 	newSheetMarshall = strings.Replace(newSheetMarshall, oldHyperlink, newHyperlink, -1)
+	newSheetMarshall = strings.Replace(newSheetMarshall, `<drawing id=`, `<drawing r:id=`, 1)
+	newSheetMarshall = strings.Replace(newSheetMarshall, `<legacyDrawing id=`, `<legacyDrawing r:id=`, 1)
+	newSheetMarshall = strings.Replace(newSheetMarshall, `<tablePart id=`, `<tablePart r:id=`, -1)
 	return newSheetMarshall
 }
 
@@ -286,6 +394,10 @@ func (f *File) MarshallParts() (map[string]string, error) {
 	parts = make(map[string]string)
 	workbook = f.makeWorkbook()
 	sheetIndex := 1
+	hasVML := false
+	imageIndex := 1
+	tableIndex := 1
+	imageFormats := make(map[string]bool)
 
 	if f.styles == nil {
 		f.styles = newXlsxStyleSheet(f.theme)
@@ -309,10 +421,7 @@ func (f *File) MarshallParts() (map[string]string, error) {
 		sheetPath := fmt.Sprintf("worksheets/sheet%d.xml", sheetIndex)
 		partName := "xl/" + sheetPath
 		relPartName := fmt.Sprintf("xl/worksheets/_rels/sheet%d.xml.rels", sheetIndex)
-		sheetState := sheetStateVisible
-		if sheet.Hidden {
-			sheetState = sheetStateHidden
-		}
+		sheetState := string(sheet.Visibility())
 		types.Overrides = append(
 			types.Overrides,
 			xlsxOverride{
@@ -325,6 +434,101 @@ func (f *File) MarshallParts() (map[string]string, error) {
 			Id:      rId,
 			State:   sheetState}
 
+		if comments := xSheet.comments; comments != nil {
+			if xSheetRels == nil {
+				xSheetRels = &xlsxWorksheetRels{XMLName: xml.Name{Local: "Relationships"}}
+			}
+			commentsPath := fmt.Sprintf("comments%d.xml", sheetIndex)
+			vmlPath := fmt.Sprintf("drawings/vmlDrawing%d.vml", sheetIndex)
+			n := len(xSheetRels.Relationships)
+			xSheetRels.Relationships = append(xSheetRels.Relationships,
+				xlsxWorksheetRelation{Id: "rId" + strconv.Itoa(n+1), Type: RelationshipTypeComments, Target: "../" + commentsPath},
+				xlsxWorksheetRelation{Id: "rId" + strconv.Itoa(n+2), Type: RelationshipTypeVMLDrawing, Target: "../" + vmlPath})
+			xSheet.LegacyDrawing = &xlsxLegacyDrawing{RelationshipId: "rId" + strconv.Itoa(n+2)}
+			parts["xl/"+commentsPath], err = marshal(comments)
+			if err != nil {
+				return parts, err
+			}
+			parts["xl/"+vmlPath], err = comments.makeVMLDrawing(sheetIndex)
+			if err != nil {
+				return parts, err
+			}
+			types.Overrides = append(
+				types.Overrides,
+				xlsxOverride{
+					PartName:    "/xl/" + commentsPath,
+					ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.comments+xml"})
+			if !hasVML {
+				types.Defaults = append(
+					types.Defaults,
+					xlsxDefault{
+						Extension:   "vml",
+						ContentType: "application/vnd.openxmlformats-officedocument.vmlDrawing"})
+				hasVML = true
+			}
+		}
+
+		if len(sheet.Images) > 0 {
+			if xSheetRels == nil {
+				xSheetRels = &xlsxWorksheetRels{XMLName: xml.Name{Local: "Relationships"}}
+			}
+			drawingPath := fmt.Sprintf("drawings/drawing%d.xml", sheetIndex)
+			drawingRels := xlsxWorksheetRels{XMLName: xml.Name{Local: "Relationships"}}
+			for i, img := range sheet.Images {
+				mediaPath := fmt.Sprintf("media/image%d.%s", imageIndex, img.Format)
+				parts["xl/"+mediaPath] = string(img.Data)
+				drawingRels.Relationships = append(drawingRels.Relationships,
+					xlsxWorksheetRelation{Id: "rId" + strconv.Itoa(i+1), Type: RelationshipTypeImage, Target: "../" + mediaPath})
+				if !imageFormats[img.Format] {
+					types.Defaults = append(
+						types.Defaults,
+						xlsxDefault{
+							Extension:   img.Format,
+							ContentType: imageContentTypes[img.Format]})
+					imageFormats[img.Format] = true
+				}
+				imageIndex++
+			}
+			rId := "rId" + strconv.Itoa(len(xSheetRels.Relationships)+1)
+			xSheetRels.Relationships = append(xSheetRels.Relationships,
+				xlsxWorksheetRelation{Id: rId, Type: RelationshipTypeDrawing, Target: "../" + drawingPath})
+			xSheet.Drawing = &xlsxDrawing{RelationshipId: rId}
+			parts["xl/"+drawingPath] = makeDrawing(sheet.Images)
+			parts[fmt.Sprintf("xl/drawings/_rels/drawing%d.xml.rels", sheetIndex)], err = marshal(drawingRels)
+			if err != nil {
+				return parts, err
+			}
+			types.Overrides = append(
+				types.Overrides,
+				xlsxOverride{
+					PartName:    "/xl/" + drawingPath,
+					ContentType: "application/vnd.openxmlformats-officedocument.drawing+xml"})
+		}
+
+		if len(sheet.Tables) > 0 {
+			if xSheetRels == nil {
+				xSheetRels = &xlsxWorksheetRels{XMLName: xml.Name{Local: "Relationships"}}
+			}
+			xSheet.TableParts = &xlsxTableParts{Count: len(sheet.Tables)}
+			for _, t := range sheet.Tables {
+				tablePath := fmt.Sprintf("tables/table%d.xml", tableIndex)
+				rId := "rId" + strconv.Itoa(len(xSheetRels.Relationships)+1)
+				xSheetRels.Relationships = append(xSheetRels.Relationships,
+					xlsxWorksheetRelation{Id: rId, Type: RelationshipTypeTable, Target: "../" + tablePath})
+				xSheet.TableParts.TablePart = append(xSheet.TableParts.TablePart, xlsxTablePart{RelationshipId: rId})
+				parts["xl/"+tablePath], err = marshal(t.makeXLSXTable(tableIndex))
+				if err != nil {
+					return parts, err
+				}
+				types.Overrides = append(
+					types.Overrides,
+					xlsxOverride{
+						PartName:    "/xl/" + tablePath,
+						ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.table+xml"})
+				tableIndex++
+			}
+		}
+
 		worksheetMarshal, err := marshal(xSheet)
 		if err != nil {
 			return parts, err
@@ -340,6 +544,15 @@ func (f *File) MarshallParts() (map[string]string, error) {
 		sheetIndex++
 	}
 
+	for _, dn := range f.DefinedNames {
+		if f.isFilterDatabaseName(dn) {
+			// Written afresh below, in case the filter has changed.
+			continue
+		}
+		workbook.DefinedNames.DefinedName = append(workbook.DefinedNames.DefinedName, *dn)
+	}
+	workbook.DefinedNames.DefinedName = append(workbook.DefinedNames.DefinedName, f.filterDatabaseNames()...)
+
 	workbookMarshal, err := marshal(workbook)
 	if err != nil {
 		return parts, err
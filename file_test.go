@@ -1,12 +1,15 @@
 package xlsx
 
 import (
+	"bytes"
 	"encoding/xml"
 	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
 )
@@ -830,6 +833,30 @@ func TestFile(t *testing.T) {
 		c.Assert(cell1.Value, qt.Equals, "A cell!")
 	})
 
+	// We can write a File to any io.Writer, or get it as bytes.
+	csRunO(c, "TestWriteToAndBytes", func(c *qt.C, option FileOption) {
+		var _ io.WriterTo = &File{}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("MySheet")
+		sheet.AddRow().AddCell().SetString("A cell!")
+
+		var buf bytes.Buffer
+		n, err := f.WriteTo(&buf)
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, int64(buf.Len()))
+		c.Assert(n > 0, qt.Equals, true)
+
+		b, err := f.Bytes()
+		c.Assert(err, qt.IsNil)
+		for _, data := range [][]byte{buf.Bytes(), b} {
+			xlsxFile, err := OpenBinary(data, option)
+			c.Assert(err, qt.IsNil)
+			cell, err := xlsxFile.Sheet["MySheet"].Cell(0, 0)
+			c.Assert(err, qt.IsNil)
+			c.Assert(cell.Value, qt.Equals, "A cell!")
+		}
+	})
+
 	csRunO(c, "TestMarshalFileWithHyperlinks", func(c *qt.C, option FileOption) {
 		var f *File
 		f = NewFile(option)
@@ -888,6 +915,255 @@ func TestFile(t *testing.T) {
 		c.Assert(cell1.Value, qt.Equals, "http://www.google.com")
 	})
 
+	// Different sheets of a file read can be read at the same time,
+	// which the race detector checks when the tests are run with -race.
+	csRunO(c, "TestConcurrentSheetReads", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		style := NewStyle()
+		style.Font.Bold = true
+		for _, name := range []string{"One", "Two", "Three", "Four"} {
+			sheet, err := f.AddSheet(name)
+			c.Assert(err, qt.IsNil)
+			for i := 0; i < 20; i++ {
+				row := sheet.AddRow()
+				row.AddCell().SetString(name)
+				row.AddCell().SetDate(time.Date(2020, 3, i+1, 0, 0, 0, 0, time.UTC))
+				row.AddCell().SetFloatWithFormat(float64(i)/100, "0.00%")
+				row.GetCell(0).SetStyle(style)
+			}
+		}
+		b, err := f.Bytes()
+		c.Assert(err, qt.IsNil)
+		f, err = OpenBinary(b, option)
+		c.Assert(err, qt.IsNil)
+
+		var wg sync.WaitGroup
+		errs := make([]error, len(f.Sheets))
+		for i, sheet := range f.Sheets {
+			wg.Add(1)
+			go func(i int, sheet *Sheet) {
+				defer wg.Done()
+				errs[i] = sheet.ForEachRow(func(row *Row) error {
+					return row.ForEachCell(func(cell *Cell) error {
+						cell.GetStyle()
+						cell.IsDate()
+						if _, err := cell.FormattedValue(); err != nil {
+							return err
+						}
+						if cell.Value != sheet.Name {
+							_, err := cell.Float()
+							return err
+						}
+						return nil
+					})
+				})
+			}(i, sheet)
+		}
+		wg.Wait()
+		for i, err := range errs {
+			c.Assert(err, qt.IsNil, qt.Commentf("sheet %d", i))
+		}
+	})
+
+	// Hyperlinks read from a file are kept when it is saved again.
+	csRunO(c, "TestResaveFileWithHyperlinks", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("MySheet")
+		cell := sheet.AddRow().AddCell()
+		cell.SetHyperlink("https://example.com/", "Example", "Go there")
+		want := cell.Hyperlink
+
+		for i := 0; i < 2; i++ {
+			b, err := f.Bytes()
+			c.Assert(err, qt.IsNil)
+			f, err = OpenBinary(b, option)
+			c.Assert(err, qt.IsNil)
+			cell, err = f.Sheet["MySheet"].Cell(0, 0)
+			c.Assert(err, qt.IsNil)
+			c.Assert(cell.Hyperlink, qt.Equals, want, qt.Commentf("save %d", i+1))
+			c.Assert(cell.Value, qt.Equals, "Example")
+		}
+	})
+
+	// Images are saved with their drawing, and kept when a file read
+	// is saved again.
+	csRunO(c, "TestResaveFileWithImages", func(c *qt.C, option FileOption) {
+		png := []byte("\x89PNG\r\n\x1a\nnot really a png")
+		jpeg := []byte("\xff\xd8\xffnot really a jpeg")
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("MySheet")
+		cell := sheet.AddRow().AddCell()
+		cell.SetComment("Eric", "Logo below")
+		logo := Anchor{FromCol: 1, FromRow: 1, FromColOffset: 10 * EMUsPerPixel, ToCol: 3, ToRow: 5, ToRowOffset: 5 * EMUsPerPixel}
+		c.Assert(sheet.AddImage(png, "PNG", logo), qt.IsNil)
+		c.Assert(sheet.AddImage(jpeg, "jpg", Anchor{FromCol: 4, ToCol: 6, ToRow: 2}), qt.IsNil)
+		other, _ := f.AddSheet("Other")
+		other.AddRow().AddCell().SetString("x")
+		c.Assert(other.AddImage(png, "png", logo), qt.IsNil)
+		want := []Image{
+			{Data: png, Format: "png", Anchor: logo},
+			{Data: jpeg, Format: "jpeg", Anchor: Anchor{FromCol: 4, ToCol: 6, ToRow: 2}},
+		}
+
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/media/image1.png"], qt.Equals, string(png))
+		c.Assert(parts["xl/media/image2.jpeg"], qt.Equals, string(jpeg))
+		c.Assert(parts["xl/media/image3.png"], qt.Equals, string(png))
+		c.Assert(parts["xl/worksheets/_rels/sheet1.xml.rels"], qt.Contains, `<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/drawing" Target="../drawings/drawing1.xml">`)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<drawing r:id="rId3"></drawing><legacyDrawing r:id="rId2"></legacyDrawing>`)
+		c.Assert(parts["xl/drawings/_rels/drawing1.xml.rels"], qt.Contains, `<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="../media/image2.jpeg">`)
+		c.Assert(parts["xl/drawings/drawing1.xml"], qt.Contains, `<xdr:from><xdr:col>1</xdr:col><xdr:colOff>95250</xdr:colOff><xdr:row>1</xdr:row><xdr:rowOff>0</xdr:rowOff></xdr:from>`)
+		c.Assert(parts["[Content_Types].xml"], qt.Contains, `<Default Extension="png" ContentType="image/png"></Default><Default Extension="jpeg" ContentType="image/jpeg"></Default>`)
+		c.Assert(parts["[Content_Types].xml"], qt.Contains, `<Override PartName="/xl/drawings/drawing2.xml" ContentType="application/vnd.openxmlformats-officedocument.drawing+xml"></Override>`)
+
+		for i := 0; i < 2; i++ {
+			b, err := f.Bytes()
+			c.Assert(err, qt.IsNil)
+			f, err = OpenBinary(b, option)
+			c.Assert(err, qt.IsNil)
+			c.Assert(f.Sheet["MySheet"].Images, qt.DeepEquals, want, qt.Commentf("save %d", i+1))
+			c.Assert(f.Sheet["Other"].Images, qt.DeepEquals, want[:1], qt.Commentf("save %d", i+1))
+			cell, err = f.Sheet["MySheet"].Cell(0, 0)
+			c.Assert(err, qt.IsNil)
+			c.Assert(cell.Comment.Text, qt.Equals, "Logo below")
+		}
+	})
+
+	csRunO(c, "TestDefinedNames", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, err := f.AddSheet("Data")
+		c.Assert(err, qt.IsNil)
+		_, err = f.AddSheet("Summary")
+		c.Assert(err, qt.IsNil)
+		for i := 1; i <= 3; i++ {
+			sheet.AddRow().AddCell().SetInt(i)
+		}
+
+		c.Assert(f.SetDefinedName("Values", "Data!$A$1:$A$3", ""), qt.IsNil)
+		c.Assert(f.SetDefinedName("Rate", "=0.2", "Data"), qt.IsNil)
+		c.Assert(f.SetDefinedName("Rate", "0.1", "Summary"), qt.IsNil)
+		c.Assert(f.SetDefinedName("RATE", "0.3", "Summary"), qt.IsNil)
+		c.Assert(f.DefinedNames, qt.HasLen, 3)
+
+		for _, name := range []string{"two words", "1st", "A1", "xfd1048576", "R1C1", "r", "C12", "Sales-Tax", ""} {
+			c.Assert(f.SetDefinedName(name, "Data!$A$1", ""), qt.Not(qt.IsNil), qt.Commentf(name))
+		}
+		c.Assert(f.SetDefinedName("Values", "", ""), qt.ErrorMatches, `defined name "Values" refers to nothing`)
+		c.Assert(f.SetDefinedName("Values", "Data!$A$1", "Missing"), qt.ErrorMatches, `no sheet named "Missing" to scope a defined name to`)
+		c.Assert(f.SetDefinedName("_Total.Net", "SUM(Values)", ""), qt.IsNil)
+
+		b, err := f.Bytes()
+		c.Assert(err, qt.IsNil)
+		f, err = OpenBinary(b, option)
+		c.Assert(err, qt.IsNil)
+		for _, want := range []struct {
+			name, scope, refersTo string
+		}{
+			{"Values", "", "Data!$A$1:$A$3"},
+			{"values", "", "Data!$A$1:$A$3"},
+			{"Rate", "Data", "0.2"},
+			{"Rate", "Summary", "0.3"},
+			{"_Total.Net", "", "SUM(Values)"},
+		} {
+			refersTo, ok := f.DefinedName(want.name, want.scope)
+			c.Assert(ok, qt.Equals, true, qt.Commentf("%s in %q", want.name, want.scope))
+			c.Assert(refersTo, qt.Equals, want.refersTo)
+		}
+		_, ok := f.DefinedName("Rate", "")
+		c.Assert(ok, qt.Equals, false)
+		_, ok = f.DefinedName("Values", "Data")
+		c.Assert(ok, qt.Equals, false)
+	})
+
+	// Dates are written and read with the epoch of the file, which is
+	// saved with it.
+	csRunO(c, "TestDate1904", func(c *qt.C, option FileOption) {
+		when := time.Date(2020, 3, 1, 10, 30, 0, 0, time.UTC)
+		for _, want := range []struct {
+			date1904 bool
+			value    string
+		}{
+			{false, "43891.4375"},
+			{true, "42429.4375"},
+		} {
+			f := NewFile(option)
+			f.SetDate1904(want.date1904)
+			sheet, err := f.AddSheet("Dates")
+			c.Assert(err, qt.IsNil)
+			cell := sheet.AddRow().AddCell()
+			cell.SetValue(when)
+			c.Assert(cell.Value, qt.Equals, want.value)
+
+			b, err := f.Bytes()
+			c.Assert(err, qt.IsNil)
+			f, err = OpenBinary(b, option)
+			c.Assert(err, qt.IsNil)
+			c.Assert(f.Date1904, qt.Equals, want.date1904)
+			cell, err = f.Sheets[0].Cell(0, 0)
+			c.Assert(err, qt.IsNil)
+			c.Assert(cell.Value, qt.Equals, want.value)
+			got, err := cell.Time()
+			c.Assert(err, qt.IsNil)
+			c.Assert(got, qt.Equals, when)
+		}
+
+		// The same number is a date four years later in a 1904 file.
+		f := NewFile(option)
+		sheet, err := f.AddSheet("Dates")
+		c.Assert(err, qt.IsNil)
+		cell := sheet.AddRow().AddCell()
+		cell.SetFloat(42429.4375)
+		f.SetDate1904(true)
+		got, err := cell.Time()
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.Equals, when)
+	})
+
+	// Dates are written as the wall clock of the file's location, and
+	// read back as times in it.
+	csRunO(c, "TestTimeLocation", func(c *qt.C, option FileOption) {
+		loc := time.FixedZone("UTC+2", 2*60*60)
+		when := time.Date(2020, 3, 1, 10, 0, 0, 0, time.UTC)
+
+		f := NewFile(option, TimeLocation(loc))
+		sheet, err := f.AddSheet("Dates")
+		c.Assert(err, qt.IsNil)
+		row := sheet.AddRow()
+		row.AddCell().SetValue(when)
+		row.AddCell().SetDate(when)
+		iso := row.AddCell()
+		iso.Value = "2020-03-01T12:00:00"
+		iso.cellType = CellTypeDate
+		utc := row.AddCell()
+		utc.SetDateWithOptions(when, DateTimeOptions{Location: time.UTC, ExcelTimeFormat: DefaultDateTimeFormat})
+		c.Assert(row.GetCell(0).Value, qt.Equals, "43891.5")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "43891.5")
+		c.Assert(utc.Value, qt.Equals, "43891.41666666667")
+
+		b, err := f.Bytes()
+		c.Assert(err, qt.IsNil)
+		read, err := OpenBinary(b, option, TimeLocation(loc))
+		c.Assert(err, qt.IsNil)
+		for i := 0; i < 3; i++ {
+			cell, err := read.Sheets[0].Cell(0, i)
+			c.Assert(err, qt.IsNil)
+			got, err := cell.Time()
+			c.Assert(err, qt.IsNil)
+			c.Assert(got.Equal(when), qt.Equals, true, qt.Commentf("cell %d: %v", i, got))
+			c.Assert(got.Location(), qt.Equals, loc)
+		}
+
+		// Without the option the same numbers are times in UTC.
+		read, err = OpenBinary(b, option)
+		c.Assert(err, qt.IsNil)
+		cell, err := read.Sheets[0].Cell(0, 0)
+		c.Assert(err, qt.IsNil)
+		got, err := cell.Time()
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.Equals, time.Date(2020, 3, 1, 12, 0, 0, 0, time.UTC))
+	})
+
 	csRunO(c, "TestReadWorkbookWithTypes", func(c *qt.C, option FileOption) {
 		var xlsxFile *File
 		var err error
@@ -127,7 +127,7 @@ func (fullFormat *parsedNumberFormat) formatNumericCell(cell *Cell) (string, err
 	}
 
 	if fullFormat.isTimeFormat {
-		return fullFormat.parseTime(rawValue, cell.date1904)
+		return fullFormat.parseTime(rawValue, cell.isDate1904())
 	}
 	var numberFormat *formatOptions
 	floatVal, floatErr := strconv.ParseFloat(rawValue, 64)
@@ -194,6 +194,13 @@ func (fullFormat *parsedNumberFormat) formatNumericCell(cell *Cell) (string, err
 		formattedNum = fmt.Sprintf("%e", floatVal)
 	case "":
 		// Do nothing.
+	case strings.Repeat("0", len(numberFormat.reducedFormatString)):
+		// A run of zeros pads a whole number with leading zeros, as is
+		// done for codes such as "00042".
+		formattedNum = fmt.Sprintf("%0*.0f", len(numberFormat.reducedFormatString), math.Abs(floatVal))
+		if floatVal < 0 {
+			formattedNum = "-" + formattedNum
+		}
 	default:
 		return rawValue, nil
 	}
@@ -654,6 +661,56 @@ func isTimeFormat(format string) bool {
 	return foundTimeFormatCharacters
 }
 
+// isDateFormat checks whether an Excel time format string shows a
+// calendar date, as "yyyy-mm-dd" and "d mmm h:mm" do, rather than only
+// a time of day or a duration, as "h:mm AM/PM" and "[h]:mm:ss" do. An
+// "m" is the month unless it follows an hour or comes before seconds,
+// when it is the minutes.
+func isDateFormat(format string) bool {
+	if !isTimeFormat(format) {
+		return false
+	}
+	lower := strings.ToLower(format)
+	lower = strings.Replace(lower, "am/pm", "", -1)
+	lower = strings.Replace(lower, "a/p", "", -1)
+	// Keep only the letters that aren't escaped, quoted or bracketed.
+	var letters []rune
+	runes := []rune(lower)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\\' || r == '_':
+			i++
+		case r == '"' || r == '[':
+			end := '"'
+			if r == '[' {
+				end = ']'
+			}
+			for i++; i < len(runes) && runes[i] != end; i++ {
+			}
+		case r >= 'a' && r <= 'z':
+			letters = append(letters, r)
+		}
+	}
+	for i := 0; i < len(letters); i++ {
+		switch letters[i] {
+		case 'y', 'd':
+			return true
+		case 'm':
+			j := i
+			for j < len(letters) && letters[j] == 'm' {
+				j++
+			}
+			afterHour := i > 0 && letters[i-1] == 'h'
+			beforeSeconds := j < len(letters) && letters[j] == 's'
+			if !afterHour && !beforeSeconds {
+				return true
+			}
+			i = j - 1
+		}
+	}
+	return false
+}
+
 // is12HourTime checks whether an Excel time format string is a 12
 // hours form.
 func is12HourTime(format string) bool {
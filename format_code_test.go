@@ -142,6 +142,18 @@ func TestCellFormatCode(t *testing.T) {
 				formattedValueOutput: "$-+/()!^&'~{}<>=: 19 :=><}{~'&^)(/+-$",
 				cellType:             CellTypeNumeric,
 			},
+			{
+				formatString:         `00000`,
+				value:                "42",
+				formattedValueOutput: "00042",
+				cellType:             CellTypeNumeric,
+			},
+			{
+				formatString:         `000;(000)`,
+				value:                "-7.6",
+				formattedValueOutput: "(008)",
+				cellType:             CellTypeNumeric,
+			},
 			{
 				formatString:         `0;-0;"zero"`,
 				value:                "18.989999999999998",
@@ -0,0 +1,154 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// EMUsPerPixel is the number of EMUs (English Metric Units), the unit
+// of the offsets of an Anchor, in a pixel at 96 DPI.
+const EMUsPerPixel = 9525
+
+// imageContentTypes are the content types of the image formats
+// AddImage takes.
+var imageContentTypes = map[string]string{
+	"png":  "image/png",
+	"jpeg": "image/jpeg",
+	"gif":  "image/gif",
+}
+
+// Anchor positions an image on a sheet. Its top-left corner is in the
+// cell at FromCol and FromRow and its bottom-right corner in the cell
+// at ToCol and ToRow, all counted from zero, and each corner is placed
+// within its cell by offsets from the cell's top-left corner in EMUs,
+// see EMUsPerPixel.
+type Anchor struct {
+	FromCol       int
+	FromRow       int
+	FromColOffset int64
+	FromRowOffset int64
+	ToCol         int
+	ToRow         int
+	ToColOffset   int64
+	ToRowOffset   int64
+}
+
+// Image is an image on a sheet, see AddImage. Format is "png", "jpeg"
+// or "gif".
+type Image struct {
+	Data   []byte
+	Format string
+	Anchor Anchor
+}
+
+// AddImage places the image img on the sheet, stretched over the cells
+// of anchor. The format of img is given as "png", "jpeg" (or "jpg") or
+// "gif". An error is returned, and nothing added, if the format isn't
+// one of those, img is empty, or the anchor's corners are out of order.
+func (s *Sheet) AddImage(img []byte, format string, anchor Anchor) error {
+	format = strings.ToLower(format)
+	if format == "jpg" {
+		format = "jpeg"
+	}
+	if _, ok := imageContentTypes[format]; !ok {
+		return fmt.Errorf("image: unsupported format %q", format)
+	}
+	if len(img) == 0 {
+		return errors.New("image: no image data")
+	}
+	if anchor.FromCol < 0 || anchor.FromRow < 0 || anchor.FromColOffset < 0 || anchor.FromRowOffset < 0 || anchor.ToColOffset < 0 || anchor.ToRowOffset < 0 {
+		return errors.New("image: negative anchor")
+	}
+	if anchor.ToCol < anchor.FromCol || anchor.ToRow < anchor.FromRow {
+		return fmt.Errorf("image: anchor ends at column %d, row %d before it starts at column %d, row %d", anchor.ToCol, anchor.ToRow, anchor.FromCol, anchor.FromRow)
+	}
+	s.Images = append(s.Images, Image{Data: img, Format: format, Anchor: anchor})
+	return nil
+}
+
+// readImagesFromZipFile returns the images of the drawing named name,
+// the parts of which are in parts. Images other than PNG, JPEG and GIF
+// pictures anchored to two cells, and anything else in the drawing,
+// are left out.
+func readImagesFromZipFile(name string, parts map[string]*zip.File) ([]Image, error) {
+	f := parts[name]
+	if f == nil {
+		return nil, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	drawing := new(xlsxWsDr)
+	if err := xml.NewDecoder(rc).Decode(drawing); err != nil {
+		return nil, err
+	}
+	dir, base := path.Split(name)
+	rels, err := readWorksheetRelsFromZipFile(parts[dir+"_rels/"+base+".rels"])
+	if err != nil || rels == nil {
+		return nil, err
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		if rel.Type == RelationshipTypeImage {
+			targets[rel.Id] = rel.Target
+		}
+	}
+
+	var images []Image
+	for _, a := range drawing.TwoCellAnchor {
+		if a.Pic == nil {
+			continue
+		}
+		target, ok := targets[a.Pic.BlipFill.Blip.Embed]
+		if !ok {
+			continue
+		}
+		mediaName := strings.TrimPrefix(target, "/")
+		if mediaName == target {
+			mediaName = path.Join(dir, target)
+		}
+		format := strings.ToLower(strings.TrimPrefix(path.Ext(mediaName), "."))
+		if format == "jpg" {
+			format = "jpeg"
+		}
+		if _, ok := imageContentTypes[format]; !ok || parts[mediaName] == nil {
+			continue
+		}
+		data, err := readZipFile(parts[mediaName])
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, Image{
+			Data:   data,
+			Format: format,
+			Anchor: Anchor{
+				FromCol:       a.From.Col,
+				FromRow:       a.From.Row,
+				FromColOffset: a.From.ColOff,
+				FromRowOffset: a.From.RowOff,
+				ToCol:         a.To.Col,
+				ToRow:         a.To.Row,
+				ToColOffset:   a.To.ColOff,
+				ToRowOffset:   a.To.RowOff,
+			},
+		})
+	}
+	return images, nil
+}
+
+// readZipFile returns the contents of f.
+func readZipFile(f *zip.File) ([]byte, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return ioutil.ReadAll(rc)
+}
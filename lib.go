@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"path"
+	"regexp"
 	"strconv"
 	"strings"
 )
@@ -229,6 +230,36 @@ func getMaxMinFromDimensionRef(ref string) (minx, miny, maxx, maxy int, err erro
 	return
 }
 
+// parseRangeRef returns the zero based cartesian coordinates of the
+// top-left and bottom-right cells of the range ref, such as "A1:C10"
+// or "$B$2", with the corners given in either order. An error is
+// returned if ref isn't a cell reference or a range of them.
+func parseRangeRef(ref string) (minx, miny, maxx, maxy int, err error) {
+	parts := strings.SplitN(strings.ToUpper(ref), cellRangeChar, 2)
+	if len(parts) == 1 {
+		parts = append(parts, parts[0])
+	}
+	var coords [2][2]int
+	for i, part := range parts {
+		if !cellRefRegexp.MatchString(part) {
+			return -1, -1, -1, -1, fmt.Errorf("invalid range %q", ref)
+		}
+		coords[i][0], coords[i][1], err = GetCoordsFromCellIDString(part)
+		if err != nil {
+			return -1, -1, -1, -1, fmt.Errorf("invalid range %q: %w", ref, err)
+		}
+	}
+	minx, maxx = coords[0][0], coords[1][0]
+	if minx > maxx {
+		minx, maxx = maxx, minx
+	}
+	miny, maxy = coords[0][1], coords[1][1]
+	if miny > maxy {
+		miny, maxy = maxy, miny
+	}
+	return minx, miny, maxx, maxy, nil
+}
+
 // calculateMaxMinFromWorkSheet works out the dimensions of a spreadsheet
 // that doesn't have a DimensionRef set.  The only case currently
 // known where this is true is with XLSX exported from Google Docs.
@@ -317,6 +348,7 @@ func makeRowFromRaw(rawrow xlsxRow, sheet *Sheet) *Row {
 	upper++
 
 	row.OutlineLevel = rawrow.OutlineLevel
+	row.Collapsed = rawrow.Collapsed
 	row.cellCount = upper
 	row.cells = make([]*Cell, upper, upper)
 	return row
@@ -333,6 +365,11 @@ type sharedFormula struct {
 	formula string
 }
 
+// formulaForCell returns the formula of rawcell. A cell that shares
+// the formula of an earlier cell, the master cell of the shared
+// formula, gets the master's formula with its relative references
+// moved by as many columns and rows as the cell is from the master,
+// just as Excel shows it.
 func formulaForCell(rawcell xlsxC, sharedFormulas map[int]sharedFormula) string {
 	var res string
 
@@ -348,51 +385,8 @@ func formulaForCell(rawcell xlsxC, sharedFormulas map[int]sharedFormula) string
 			if f.Ref != "" {
 				res = f.Content
 				sharedFormulas[f.Si] = sharedFormula{x, y, res}
-			} else {
-				sharedFormula := sharedFormulas[f.Si]
-				dx := x - sharedFormula.x
-				dy := y - sharedFormula.y
-				orig := []byte(sharedFormula.formula)
-				var start, end int
-				var stringLiteral bool
-				for end = 0; end < len(orig); end++ {
-					c := orig[end]
-
-					if c == '"' {
-						stringLiteral = !stringLiteral
-					}
-
-					if stringLiteral {
-						continue // Skip characters in quotes
-					}
-
-					if c >= 'A' && c <= 'Z' || c == '$' {
-						res += string(orig[start:end])
-						start = end
-						end++
-						foundNum := false
-						for ; end < len(orig); end++ {
-							idc := orig[end]
-							if idc >= '0' && idc <= '9' || idc == '$' {
-								foundNum = true
-							} else if idc >= 'A' && idc <= 'Z' {
-								if foundNum {
-									break
-								}
-							} else {
-								break
-							}
-						}
-						if foundNum {
-							cellID := string(orig[start:end])
-							res += shiftCell(cellID, dx, dy)
-							start = end
-						}
-					}
-				}
-				if start < len(orig) {
-					res += string(orig[start:])
-				}
+			} else if sharedFormula, ok := sharedFormulas[f.Si]; ok {
+				res = shiftFormula(sharedFormula.formula, x-sharedFormula.x, y-sharedFormula.y)
 			}
 		}
 	} else {
@@ -401,6 +395,81 @@ func formulaForCell(rawcell xlsxC, sharedFormulas map[int]sharedFormula) string
 	return strings.Trim(res, " \t\n\r")
 }
 
+// cellRefRegexp matches a reference to a single cell, such as "B7" or
+// "$B$7".
+var cellRefRegexp = regexp.MustCompile(`^\$?[A-Z]{1,3}\$?[1-9][0-9]*$`)
+
+// shiftFormula returns formula with its relative cell references moved
+// dx columns and dy rows. Text in double quotes, sheet names in single
+// quotes, and names that only look like a cell reference, such as the
+// function LOG10 or the sheet SHEET2 in SHEET2!A1, are left as they
+// are, as are the structured references to the columns of tables, such
+// as Orders[[#This Row],[A1]].
+func shiftFormula(formula string, dx, dy int) string {
+	var b strings.Builder
+	for i := 0; i < len(formula); {
+		c := formula[i]
+		switch {
+		case c == '[':
+			end := structuredRefEnd(formula, i)
+			b.WriteString(formula[i:end])
+			i = end
+		case c == '"' || c == '\'':
+			// A quote inside is doubled, which reads as two quoted
+			// parts in a row.
+			end := strings.IndexByte(formula[i+1:], c)
+			if end < 0 {
+				b.WriteString(formula[i:])
+				return b.String()
+			}
+			b.WriteString(formula[i : i+end+2])
+			i += end + 2
+		case isFormulaNameByte(c):
+			j := i
+			for j < len(formula) && isFormulaNameByte(formula[j]) {
+				j++
+			}
+			name := formula[i:j]
+			if cellRefRegexp.MatchString(name) && (j == len(formula) || formula[j] != '(' && formula[j] != '!' && formula[j] != '[') {
+				name = shiftCell(name, dx, dy)
+			}
+			b.WriteString(name)
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// structuredRefEnd returns the index just after the bracket closing the
+// one at formula[start], allowing for nested brackets and for the
+// characters that are escaped with a single quote.
+func structuredRefEnd(formula string, start int) int {
+	depth := 0
+	for i := start; i < len(formula); i++ {
+		switch formula[i] {
+		case '\'':
+			i++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return len(formula)
+}
+
+// isFormulaNameByte reports whether c can be part of a name, number or
+// cell reference in a formula.
+func isFormulaNameByte(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '.' || c == '$' || c == '\\' || c >= 0x80
+}
+
 // shiftCell returns the cell shifted according to dx and dy taking into consideration of absolute
 // references with dollar sign ($)
 func shiftCell(cellID string, dx, dy int) string {
@@ -513,7 +582,6 @@ func fillCellDataFromInlineString(rawcell xlsxC, cell *Cell) {
 func readRowsFromSheet(Worksheet *xlsxWorksheet, file *File, sheet *Sheet, rowLimit int) error {
 	var row *Row
 	var maxCol, maxRow, colCount, rowCount int
-	var reftable *RefTable
 	var err error
 	var insertRowIndex int // , insertColIndex int
 	sharedFormulas := map[int]sharedFormula{}
@@ -523,7 +591,6 @@ func readRowsFromSheet(Worksheet *xlsxWorksheet, file *File, sheet *Sheet, rowLi
 		sheet.MaxCol = 0
 		return nil
 	}
-	reftable = file.referenceTable
 	if len(Worksheet.Dimension.Ref) > 0 && len(strings.Split(Worksheet.Dimension.Ref, cellRangeChar)) == 2 && rowLimit == NoRowLimit {
 		_, _, maxCol, maxRow, err = getMaxMinFromDimensionRef(Worksheet.Dimension.Ref)
 	} else {
@@ -537,72 +604,13 @@ func readRowsFromSheet(Worksheet *xlsxWorksheet, file *File, sheet *Sheet, rowLi
 	colCount = maxCol + 1
 
 	if Worksheet.Cols != nil {
-		// Columns can apply to a range, for convenience we expand the
-		// ranges out into individual column definitions.
-		for _, rawcol := range Worksheet.Cols.Col {
-			col := &Col{
-				Min:          rawcol.Min,
-				Max:          rawcol.Max,
-				Hidden:       rawcol.Hidden,
-				Width:        rawcol.Width,
-				OutlineLevel: rawcol.OutlineLevel,
-				BestFit:      rawcol.BestFit,
-				CustomWidth:  rawcol.CustomWidth,
-				Phonetic:     rawcol.Phonetic,
-				Collapsed:    rawcol.Collapsed,
-			}
-			if file.styles != nil {
-				col.style = file.styles.getStyle(rawcol.Style)
-				col.numFmt, col.parsedNumFmt = file.styles.getNumberFormat(rawcol.Style)
-			}
-			sheet.Cols.Add(col)
-		}
+		readColsFromSheet(Worksheet.Cols, file, sheet)
 	}
 
 	for rowIndex := 0; rowIndex < len(Worksheet.SheetData.Row); rowIndex++ {
-		rawrow := Worksheet.SheetData.Row[rowIndex]
-		// range is not empty and only one range exist
-		if len(rawrow.Spans) != 0 && strings.Count(rawrow.Spans, cellRangeChar) == 1 {
-			row = makeRowFromSpan(rawrow.Spans, sheet)
-		} else {
-			row = makeRowFromRaw(rawrow, sheet)
-		}
-		// row.num = insertRowIndex
-		row.num = rawrow.R - 1
-
-		row.Hidden = rawrow.Hidden
-		height, err := strconv.ParseFloat(rawrow.Ht, 64)
-		if err == nil {
-			row.Height = height
-		}
-		row.isCustom = rawrow.CustomHeight
-		row.OutlineLevel = rawrow.OutlineLevel
-
-		for _, rawcell := range rawrow.C {
-			h, v, err := Worksheet.MergeCells.getExtent(rawcell.R)
-			if err != nil {
-				return err
-			}
-			x, _, err := GetCoordsFromCellIDString(rawcell.R)
-			if err != nil {
-				return err
-			}
-
-			cellX := x
-
-			cell := newCell(row, cellX)
-			cell.HMerge = h
-			cell.VMerge = v
-			fillCellData(rawcell, reftable, sharedFormulas, cell)
-			if file.styles != nil {
-				cell.style = file.styles.getStyle(rawcell.S)
-				cell.NumFmt, cell.parsedNumFmt = file.styles.getNumberFormat(rawcell.S)
-			}
-			cell.date1904 = file.Date1904
-			// Cell is considered hidden if the row or the column of this cell is hidden
-			col := sheet.Cols.FindColByIndex(cellX + 1)
-			cell.Hidden = rawrow.Hidden || (col != nil && col.Hidden)
-			row.cells[cellX] = cell
+		row, err = readRowFromSheet(Worksheet.SheetData.Row[rowIndex], Worksheet.MergeCells, file, sheet, sharedFormulas)
+		if err != nil {
+			return err
 		}
 		sheet.cellStore.WriteRow(row)
 
@@ -619,6 +627,84 @@ func readRowsFromSheet(Worksheet *xlsxWorksheet, file *File, sheet *Sheet, rowLi
 	return nil
 }
 
+// readColsFromSheet adds the column definitions xCols to sheet,
+// taking their styles from file.
+func readColsFromSheet(xCols *xlsxCols, file *File, sheet *Sheet) {
+	// Columns can apply to a range, for convenience we expand the
+	// ranges out into individual column definitions.
+	for _, rawcol := range xCols.Col {
+		col := &Col{
+			Min:          rawcol.Min,
+			Max:          rawcol.Max,
+			Hidden:       rawcol.Hidden,
+			Width:        rawcol.Width,
+			OutlineLevel: rawcol.OutlineLevel,
+			BestFit:      rawcol.BestFit,
+			CustomWidth:  rawcol.CustomWidth,
+			Phonetic:     rawcol.Phonetic,
+			Collapsed:    rawcol.Collapsed,
+		}
+		if file.styles != nil {
+			col.style = file.styles.getStyle(rawcol.Style)
+			col.numFmt, col.parsedNumFmt = file.styles.getNumberFormat(rawcol.Style)
+		}
+		sheet.Cols.Add(col)
+	}
+}
+
+// readRowFromSheet returns the Row of sheet read from rawrow, with the
+// values of its cells resolved against the shared strings and styles
+// of file and their merges taken from mergeCells, which may be nil.
+// sharedFormulas holds the shared formulas of the rows read so far.
+func readRowFromSheet(rawrow xlsxRow, mergeCells *xlsxMergeCells, file *File, sheet *Sheet, sharedFormulas map[int]sharedFormula) (*Row, error) {
+	var row *Row
+	// range is not empty and only one range exist
+	if len(rawrow.Spans) != 0 && strings.Count(rawrow.Spans, cellRangeChar) == 1 {
+		row = makeRowFromSpan(rawrow.Spans, sheet)
+	} else {
+		row = makeRowFromRaw(rawrow, sheet)
+	}
+	// row.num = insertRowIndex
+	row.num = rawrow.R - 1
+
+	row.Hidden = rawrow.Hidden
+	height, err := strconv.ParseFloat(rawrow.Ht, 64)
+	if err == nil {
+		row.Height = height
+	}
+	row.isCustom = rawrow.CustomHeight
+	row.OutlineLevel = rawrow.OutlineLevel
+	row.Collapsed = rawrow.Collapsed
+
+	for _, rawcell := range rawrow.C {
+		h, v, err := mergeCells.getExtent(rawcell.R)
+		if err != nil {
+			return nil, err
+		}
+		x, _, err := GetCoordsFromCellIDString(rawcell.R)
+		if err != nil {
+			return nil, err
+		}
+
+		cellX := x
+
+		cell := newCell(row, cellX)
+		cell.HMerge = h
+		cell.VMerge = v
+		fillCellData(rawcell, file.referenceTable, sharedFormulas, cell)
+		if file.styles != nil {
+			cell.style = file.styles.getStyle(rawcell.S)
+			cell.NumFmt, cell.parsedNumFmt = file.styles.getNumberFormat(rawcell.S)
+		}
+		cell.date1904 = file.Date1904
+		// Cell is considered hidden if the row or the column of this cell is hidden
+		col := sheet.Cols.FindColByIndex(cellX + 1)
+		cell.Hidden = rawrow.Hidden || (col != nil && col.Hidden)
+		row.cells[cellX] = cell
+	}
+	return row, nil
+}
+
 type indexedSheet struct {
 	Index int
 	Sheet *Sheet
@@ -669,27 +755,35 @@ func readSheetFromFile(rsheet xlsxSheet, fi *File, sheetXMLMap map[string]string
 	// var rows []*Row
 	// rows, sheet.Cols, sheet.MaxCol, sheet.MaxRow = readRowsFromSheet(worksheet, fi, sheet, rowLimit)
 	readRowsFromSheet(worksheet, fi, sheet, rowLimit)
+	if err := readMissingMergesFromSheet(worksheet, sheet, rowLimit); err != nil {
+		return nil, err
+	}
 
 	sheet.Hidden = rsheet.State == sheetStateHidden || rsheet.State == sheetStateVeryHidden
+	sheet.veryHidden = rsheet.State == sheetStateVeryHidden
+	sheet.protection = worksheet.SheetProtection
+	if worksheet.SheetPr.TabColor != nil {
+		if fi.styles != nil {
+			sheet.TabColor = fi.styles.argbValue(*worksheet.SheetPr.TabColor)
+		} else {
+			sheet.TabColor = worksheet.SheetPr.TabColor.RGB
+		}
+	}
 	sheet.SheetViews = readSheetViews(worksheet.SheetViews)
 	if worksheet.AutoFilter != nil {
 		autoFilterBounds := strings.Split(worksheet.AutoFilter.Ref, ":")
-		sheet.AutoFilter = &AutoFilter{autoFilterBounds[0], autoFilterBounds[1]}
+		sheet.AutoFilter = &AutoFilter{autoFilterBounds[0], autoFilterBounds[len(autoFilterBounds)-1]}
+	}
+
+	worksheetRels, err := readWorksheetRelsFromZipFile(fi.worksheetRels["sheet"+rsheet.SheetId])
+	if err != nil {
+		return nil, err
 	}
 
 	// Convert xlsxHyperlinks to Hyperlinks
 	if worksheet.Hyperlinks != nil {
-
-		worksheetRelsFile := fi.worksheetRels["sheet"+rsheet.SheetId]
-		worksheetRels := new(xlsxWorksheetRels)
-		rc, err := worksheetRelsFile.Open()
-		if err != nil {
-			return nil, err
-		}
-		decoder := xml.NewDecoder(rc)
-		err = decoder.Decode(worksheetRels)
-		if err != nil {
-			return nil, err
+		if worksheetRels == nil {
+			worksheetRels = new(xlsxWorksheetRels)
 		}
 
 		for _, xlsxLink := range worksheet.Hyperlinks.HyperLinks {
@@ -702,6 +796,10 @@ func readSheetFromFile(rsheet xlsxSheet, fi *File, sheetXMLMap map[string]string
 				}
 			}
 
+			if newHyperLink.Link != "" {
+				// The relation is needed again when the sheet is saved.
+				sheet.addRelation(RelationshipTypeHyperlink, newHyperLink.Link, RelationshipTargetModeExternal)
+			}
 			if xlsxLink.Tooltip != "" {
 				newHyperLink.Tooltip = xlsxLink.Tooltip
 			}
@@ -722,6 +820,39 @@ func readSheetFromFile(rsheet xlsxSheet, fi *File, sheetXMLMap map[string]string
 		}
 	}
 
+	if worksheetRels != nil {
+		for _, rel := range worksheetRels.Relationships {
+			if rel.Type != RelationshipTypeComments && rel.Type != RelationshipTypeDrawing && rel.Type != RelationshipTypeTable {
+				continue
+			}
+			name := strings.TrimPrefix(rel.Target, "/")
+			if name == rel.Target {
+				name = path.Join("xl/worksheets", rel.Target)
+			}
+			if rel.Type == RelationshipTypeDrawing {
+				images, err := readImagesFromZipFile(name, fi.drawings)
+				if err != nil {
+					return nil, err
+				}
+				sheet.Images = append(sheet.Images, images...)
+				continue
+			}
+			if rel.Type == RelationshipTypeTable {
+				t, err := readTableFromZipFile(fi.tables[name])
+				if err != nil {
+					return nil, err
+				}
+				if t != nil {
+					sheet.Tables = append(sheet.Tables, *t)
+				}
+				continue
+			}
+			if err := readCommentsFromZipFile(fi.comments[name], sheet, rowLimit); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	sheet.SheetFormat.DefaultColWidth = worksheet.SheetFormatPr.DefaultColWidth
 	sheet.SheetFormat.DefaultRowHeight = worksheet.SheetFormatPr.DefaultRowHeight
 	sheet.SheetFormat.OutlineLevelCol = worksheet.SheetFormatPr.OutlineLevelCol
@@ -732,10 +863,97 @@ func readSheetFromFile(rsheet xlsxSheet, fi *File, sheetXMLMap map[string]string
 		}
 
 	}
+	sheet.ConditionalFormats = readConditionalFormatting(worksheet, fi.styles)
 
 	return sheet, nil
 }
 
+// readMissingMergesFromSheet adds to sheet the merges of worksheet
+// whose top-left cell isn't in its sheet data, as happens when that
+// cell is empty, so that they aren't lost. Merges starting beyond
+// rowLimit are left out.
+func readMissingMergesFromSheet(worksheet *xlsxWorksheet, sheet *Sheet, rowLimit int) error {
+	if worksheet.MergeCells == nil {
+		return nil
+	}
+	for _, mc := range worksheet.MergeCells.Cells {
+		refs := strings.Split(mc.Ref, cellRangeChar)
+		if len(refs) != 2 {
+			continue
+		}
+		x, y, err := GetCoordsFromCellIDString(refs[0])
+		if err != nil {
+			return err
+		}
+		endX, endY, err := GetCoordsFromCellIDString(refs[1])
+		if err != nil {
+			return err
+		}
+		if rowLimit != NoRowLimit && y >= rowLimit {
+			continue
+		}
+		cell, err := sheet.Cell(y, x)
+		if err != nil {
+			return err
+		}
+		if cell.HMerge == 0 && cell.VMerge == 0 {
+			cell.Merge(endX-x, endY-y)
+		}
+	}
+	return nil
+}
+
+// readWorksheetRelsFromZipFile reads the relationships of a worksheet
+// from f, which is nil if the worksheet has none.
+func readWorksheetRelsFromZipFile(f *zip.File) (*xlsxWorksheetRels, error) {
+	if f == nil {
+		return nil, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	worksheetRels := new(xlsxWorksheetRels)
+	if err := xml.NewDecoder(rc).Decode(worksheetRels); err != nil {
+		return nil, err
+	}
+	return worksheetRels, nil
+}
+
+// readCommentsFromZipFile sets the Comment of the cells of sheet that
+// have one in the comments part f, leaving out those of rows beyond
+// rowLimit.
+func readCommentsFromZipFile(f *zip.File, sheet *Sheet, rowLimit int) error {
+	if f == nil {
+		return nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	comments := new(xlsxComments)
+	if err := xml.NewDecoder(rc).Decode(comments); err != nil {
+		return err
+	}
+	for _, c := range comments.CommentList.Comment {
+		x, y, err := GetCoordsFromCellIDString(c.Ref)
+		if err != nil {
+			return err
+		}
+		if rowLimit != NoRowLimit && y >= rowLimit {
+			continue
+		}
+		row, err := sheet.Row(y)
+		if err != nil {
+			return err
+		}
+		row.GetCell(x).Comment = comments.comment(c)
+	}
+	return nil
+}
+
 func recoverPanic(into *error) {
 	v := recover()
 	if v == nil {
@@ -972,13 +1190,35 @@ func ReadZip(f *zip.ReadCloser, options ...FileOption) (*File, error) {
 // ReadZipReader() can be used to read an XLSX in memory without
 // touching the filesystem.
 func ReadZipReader(r *zip.Reader, options ...FileOption) (*File, error) {
+	file := NewFile(options...)
+	workbook, sheetXMLMap, err := readFilePartsFromZipReader(r, file)
+	if err != nil {
+		return nil, err
+	}
+	sheetsByName, sheets, err := readSheetsFromZipFile(workbook, file, sheetXMLMap, file.rowLimit)
+	if err != nil {
+		return nil, err
+	}
+	if sheets == nil {
+		readerErr := new(XLSXReaderError)
+		readerErr.Err = "No sheets found in XLSX File"
+		return nil, readerErr
+	}
+	file.Sheet = sheetsByName
+	file.Sheets = sheets
+	return file, nil
+}
+
+// readFilePartsFromZipReader finds the parts of the XLSX file r and
+// reads into file those that the sheets need, such as the shared
+// strings and the styles. It returns the workbook part, the sheets of
+// which are yet to be read, and the map of relationship ids to sheet
+// names.
+func readFilePartsFromZipReader(r *zip.Reader, file *File) (*zip.File, map[string]string, error) {
 	var err error
-	var file *File
 	var reftable *RefTable
 	var sharedStrings *zip.File
 	var sheetXMLMap map[string]string
-	var sheetsByName map[string]*Sheet
-	var sheets []*Sheet
 	var style *xlsxStyleSheet
 	var styles *zip.File
 	var themeFile *zip.File
@@ -987,10 +1227,15 @@ func ReadZipReader(r *zip.Reader, options ...FileOption) (*File, error) {
 	var workbookRels *zip.File
 	var worksheets map[string]*zip.File
 	var worksheetRels map[string]*zip.File
+	var comments map[string]*zip.File
+	var drawings map[string]*zip.File
+	var tables map[string]*zip.File
 
-	file = NewFile(options...)
 	worksheets = make(map[string]*zip.File, len(r.File))
 	worksheetRels = make(map[string]*zip.File, len(r.File))
+	comments = make(map[string]*zip.File)
+	drawings = make(map[string]*zip.File)
+	tables = make(map[string]*zip.File)
 	for _, v = range r.File {
 		switch v.Name {
 		case "xl/sharedStrings.xml" , `xl\sharedStrings.xml`:
@@ -1004,6 +1249,15 @@ func ReadZipReader(r *zip.Reader, options ...FileOption) (*File, error) {
 		case "xl/theme/theme1.xml" , `xl\theme\theme1.xml`:
 			themeFile = v
 		default:
+			if strings.HasPrefix(v.Name, "xl/comments") {
+				comments[v.Name] = v
+			}
+			if strings.HasPrefix(v.Name, "xl/drawings/") || strings.HasPrefix(v.Name, "xl/media/") {
+				drawings[v.Name] = v
+			}
+			if strings.HasPrefix(v.Name, "xl/tables/") {
+				tables[v.Name] = v
+			}
 			if len(v.Name) > 17 {
 				if v.Name[0:13] == "xl/worksheets" || v.Name[0:13] == `xl\worksheets`{
 					if v.Name[len(v.Name)-5:] == ".rels" {
@@ -1016,26 +1270,29 @@ func ReadZipReader(r *zip.Reader, options ...FileOption) (*File, error) {
 		}
 	}
 	if workbookRels == nil {
-		return nil, fmt.Errorf("xl/_rels/workbook.xml.rels not found in input xlsx.")
+		return nil, nil, fmt.Errorf("xl/_rels/workbook.xml.rels not found in input xlsx.")
 	}
 	sheetXMLMap, err = readWorkbookRelationsFromZipFile(workbookRels)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if len(worksheets) == 0 {
-		return nil, fmt.Errorf("Input xlsx contains no worksheets.")
+		return nil, nil, fmt.Errorf("Input xlsx contains no worksheets.")
 	}
 	file.worksheets = worksheets
 	file.worksheetRels = worksheetRels
+	file.comments = comments
+	file.drawings = drawings
+	file.tables = tables
 	reftable, err = readSharedStringsFromZipFile(sharedStrings)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	file.referenceTable = reftable
 	if themeFile != nil {
 		theme, err := readThemeFromZipFile(themeFile)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		file.theme = theme
@@ -1043,23 +1300,12 @@ func ReadZipReader(r *zip.Reader, options ...FileOption) (*File, error) {
 	if styles != nil {
 		style, err = readStylesFromZipFile(styles, file.theme)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		file.styles = style
 	}
-	sheetsByName, sheets, err = readSheetsFromZipFile(workbook, file, sheetXMLMap, file.rowLimit)
-	if err != nil {
-		return nil, err
-	}
-	if sheets == nil {
-		readerErr := new(XLSXReaderError)
-		readerErr.Err = "No sheets found in XLSX File"
-		return nil, readerErr
-	}
-	file.Sheet = sheetsByName
-	file.Sheets = sheets
-	return file, nil
+	return workbook, sheetXMLMap, nil
 }
 
 // truncateSheetXML will take in a reader to an XML sheet file and will return a reader that will read an equivalent
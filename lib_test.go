@@ -1303,6 +1303,35 @@ func TestLib(t *testing.T) {
 		c.Assert(formulaForCell(cell, sharedFormulas), qt.Equals, "")
 	})
 
+	// Names, numbers and quoted text that look like cell references
+	// are left alone when a shared formula is moved.
+	c.Run("SharedFormulasWithNames", func(c *qt.C) {
+		cases := []struct {
+			formula string
+			want    string
+		}{
+			{"LOG10(A1)", "LOG10(B3)"},
+			{"SUM(A1:B2)/COUNT($A$1:B2)", "SUM(B3:C4)/COUNT($A$1:C4)"},
+			{"SHEET2!A1+Sheet1!$A1", "SHEET2!B3+Sheet1!$A3"},
+			{"'Sales A1'!A1&\"A1\"", "'Sales A1'!B3&\"A1\""},
+			{"'it''s'!A1", "'it''s'!B3"},
+			{"1.5E10+A1", "1.5E10+B3"},
+			{"TAX_RATE2*A1", "TAX_RATE2*B3"},
+			{"ABCD1+A1", "ABCD1+B3"},
+			{"SUM(T1[A1])+A1", "SUM(T1[A1])+B3"},
+			{"T1[[#This Row],['[B2'] cost]]*A1", "T1[[#This Row],['[B2'] cost]]*B3"},
+		}
+		for _, tc := range cases {
+			sharedFormulas := map[int]sharedFormula{0: {x: 0, y: 0, formula: tc.formula}}
+			cell := xlsxC{R: "B3", F: &xlsxF{T: "shared", Si: 0}}
+			c.Assert(formulaForCell(cell, sharedFormulas), qt.Equals, tc.want, qt.Commentf("%s", tc.formula))
+		}
+
+		// A cell sharing a formula that was never given has none.
+		cell := xlsxC{R: "B3", F: &xlsxF{T: "shared", Si: 7}}
+		c.Assert(formulaForCell(cell, map[int]sharedFormula{}), qt.Equals, "")
+	})
+
 	csRunC(c, "RowNotOverwrittenWhenFollowedByEmptyRow", func(c *qt.C, constructor CellStoreConstructor) {
 		sheetXML := bytes.NewBufferString(`
 	<?xml version="1.0" encoding="UTF-8"?>
@@ -1459,6 +1488,19 @@ func TestReadRowsFromSheet(t *testing.T) {
 		c.Assert(cell1.HMerge, qt.Equals, 1)
 		c.Assert(cell1.VMerge, qt.Equals, 0)
 	})
+
+	csRunC(c, "WithMergeCellsMissingFromSheetData", func(c *qt.C, constructor CellStoreConstructor) {
+		worksheet := &xlsxWorksheet{MergeCells: &xlsxMergeCells{Cells: []xlsxMergeCell{
+			{Ref: "B2:C4"},
+			{Ref: "A9:B9"},
+		}}}
+		worksheet.mapMergeCells()
+		sheet, err := NewSheetWithCellStore("test", constructor)
+		c.Assert(err, qt.IsNil)
+		err = readMissingMergesFromSheet(worksheet, sheet, 5)
+		c.Assert(err, qt.IsNil)
+		c.Assert(sheet.MergedCells(), qt.DeepEquals, []MergeRange{{1, 1, 2, 3}})
+	})
 }
 
 // See issue #362
@@ -0,0 +1,82 @@
+package xlsx
+
+import "strings"
+
+// Locale gives the decimal and thousands separators of a language and
+// region, such as Locale{Decimal: ',', Group: '.'} for German.
+//
+// A number format is always saved with "." as the decimal separator
+// and "," as the thousands separator, and the program showing the
+// file displays it with the separators of the reader's own settings.
+// Locale lets number formats be given the way they are written in the
+// locale, as in "#.##0,00", and turns them into the saved form. The
+// zero Locale leaves formats as they are.
+type Locale struct {
+	Decimal rune
+	Group   rune
+}
+
+// NumberFormat returns the number format 'format', written with the
+// separators of l, as it is saved in a file. The decimal separator is
+// recognised next to a digit placeholder, one of '0', '#' and '?', and
+// the thousands separator only between two of them, so that a space
+// or dot elsewhere, as in "0,00 €" or "dd.mm.yyyy", is kept. Quoted
+// text, escaped characters and bracketed sections such as colours and
+// conditions are never changed.
+func (l Locale) NumberFormat(format string) string {
+	if l == (Locale{}) || l == (Locale{Decimal: '.', Group: ','}) {
+		return format
+	}
+	isDigit := func(r rune) bool {
+		return r == '0' || r == '#' || r == '?'
+	}
+	runes := []rune(format)
+	var b strings.Builder
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		var prev, next rune
+		if i > 0 {
+			prev = runes[i-1]
+		}
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+		switch {
+		case r == '"':
+			j := i + 1
+			for j < len(runes) && runes[j] != '"' {
+				j++
+			}
+			if j == len(runes) {
+				j--
+			}
+			b.WriteString(string(runes[i : j+1]))
+			i = j
+		case r == '[':
+			j := i + 1
+			for j < len(runes) && runes[j] != ']' {
+				j++
+			}
+			if j == len(runes) {
+				j--
+			}
+			b.WriteString(string(runes[i : j+1]))
+			i = j
+		case r == '\\' || r == '_' || r == '*':
+			// The next character is shown literally, or gives the
+			// width of a space or the fill character.
+			b.WriteRune(r)
+			if next != 0 {
+				b.WriteRune(next)
+				i++
+			}
+		case r == l.Decimal && (isDigit(prev) || isDigit(next)):
+			b.WriteRune('.')
+		case r == l.Group && isDigit(prev) && isDigit(next):
+			b.WriteRune(',')
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
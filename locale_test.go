@@ -0,0 +1,33 @@
+package xlsx
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestLocaleNumberFormat(t *testing.T) {
+	c := qt.New(t)
+	german := Locale{Decimal: ',', Group: '.'}
+	french := Locale{Decimal: ',', Group: ' '}
+
+	cases := []struct {
+		locale Locale
+		format string
+		want   string
+	}{
+		{Locale{}, "#,##0.00", "#,##0.00"},
+		{Locale{Decimal: '.', Group: ','}, "#,##0.00", "#,##0.00"},
+		{german, "#.##0,00", "#,##0.00"},
+		{german, "0,0%", "0.0%"},
+		{german, "#.##0,00 €;[Red]-#.##0,00 €", "#,##0.00 €;[Red]-#,##0.00 €"},
+		{german, `#.##0,00 "Stk. 1,5"`, `#,##0.00 "Stk. 1,5"`},
+		{german, `0\,0`, `0\,0`},
+		{german, "dd.mm.yyyy", "dd.mm.yyyy"},
+		{french, "# ##0,00 €", "#,##0.00 €"},
+		{french, "0 _€", "0 _€"},
+	}
+	for _, tc := range cases {
+		c.Assert(tc.locale.NumberFormat(tc.format), qt.Equals, tc.want, qt.Commentf("%+v %q", tc.locale, tc.format))
+	}
+}
@@ -0,0 +1,140 @@
+package xlsx
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/binary"
+	"unicode/utf16"
+)
+
+// protectionSpinCount is the number of times a sheet's password is
+// hashed, as Excel does.
+const protectionSpinCount = 100000
+
+// ProtectionOptions are the options of a protected sheet, see Protect.
+// Once a sheet is protected its locked cells can't be changed; the
+// Allow fields give back the things users can otherwise do to the
+// sheet, which are all forbidden apart from selecting cells.
+type ProtectionOptions struct {
+	// Password, if not empty, must be given to unprotect the sheet.
+	// Only a hash of it is saved, so that it is empty for sheets read
+	// from a file.
+	Password string
+
+	AllowFormatCells        bool
+	AllowFormatColumns      bool
+	AllowFormatRows         bool
+	AllowInsertColumns      bool
+	AllowInsertRows         bool
+	AllowInsertHyperlinks   bool
+	AllowDeleteColumns      bool
+	AllowDeleteRows         bool
+	AllowSort               bool
+	AllowAutoFilter         bool
+	AllowPivotTables        bool
+	AllowEditObjects        bool
+	AllowEditScenarios      bool
+	DenySelectLockedCells   bool
+	DenySelectUnlockedCells bool
+}
+
+// Protect protects the sheet with the options opts, replacing any
+// protection it already has. Cells are locked unless their style says
+// otherwise, so the cells users should still fill in are unlocked with
+// Cell.SetLocked. The sheet's protection is saved and read along with
+// it. An error is returned if a salt for the password can't be made.
+func (s *Sheet) Protect(opts ProtectionOptions) error {
+	allow := func(allowed bool) *bool {
+		if !allowed {
+			return nil
+		}
+		protected := false
+		return &protected
+	}
+	protection := &xlsxSheetProtection{
+		Sheet:               true,
+		Objects:             !opts.AllowEditObjects,
+		Scenarios:           !opts.AllowEditScenarios,
+		FormatCells:         allow(opts.AllowFormatCells),
+		FormatColumns:       allow(opts.AllowFormatColumns),
+		FormatRows:          allow(opts.AllowFormatRows),
+		InsertColumns:       allow(opts.AllowInsertColumns),
+		InsertRows:          allow(opts.AllowInsertRows),
+		InsertHyperlinks:    allow(opts.AllowInsertHyperlinks),
+		DeleteColumns:       allow(opts.AllowDeleteColumns),
+		DeleteRows:          allow(opts.AllowDeleteRows),
+		SelectLockedCells:   opts.DenySelectLockedCells,
+		Sort:                allow(opts.AllowSort),
+		AutoFilter:          allow(opts.AllowAutoFilter),
+		PivotTables:         allow(opts.AllowPivotTables),
+		SelectUnlockedCells: opts.DenySelectUnlockedCells,
+	}
+	if opts.Password != "" {
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return err
+		}
+		protection.AlgorithmName = "SHA-512"
+		protection.SaltValue = base64.StdEncoding.EncodeToString(salt)
+		protection.SpinCount = protectionSpinCount
+		protection.HashValue = base64.StdEncoding.EncodeToString(hashPassword(opts.Password, salt, protectionSpinCount))
+	}
+	s.protection = protection
+	return nil
+}
+
+// Unprotect removes the protection of the sheet, if it has any.
+func (s *Sheet) Unprotect() {
+	s.protection = nil
+}
+
+// Protection returns the options the sheet is protected with, with an
+// empty Password, and whether it is protected at all.
+func (s *Sheet) Protection() (ProtectionOptions, bool) {
+	p := s.protection
+	if p == nil || !p.Sheet {
+		return ProtectionOptions{}, false
+	}
+	allowed := func(protected *bool) bool {
+		return protected != nil && !*protected
+	}
+	return ProtectionOptions{
+		AllowFormatCells:        allowed(p.FormatCells),
+		AllowFormatColumns:      allowed(p.FormatColumns),
+		AllowFormatRows:         allowed(p.FormatRows),
+		AllowInsertColumns:      allowed(p.InsertColumns),
+		AllowInsertRows:         allowed(p.InsertRows),
+		AllowInsertHyperlinks:   allowed(p.InsertHyperlinks),
+		AllowDeleteColumns:      allowed(p.DeleteColumns),
+		AllowDeleteRows:         allowed(p.DeleteRows),
+		AllowSort:               allowed(p.Sort),
+		AllowAutoFilter:         allowed(p.AutoFilter),
+		AllowPivotTables:        allowed(p.PivotTables),
+		AllowEditObjects:        !p.Objects,
+		AllowEditScenarios:      !p.Scenarios,
+		DenySelectLockedCells:   p.SelectLockedCells,
+		DenySelectUnlockedCells: p.SelectUnlockedCells,
+	}, true
+}
+
+// hashPassword returns the SHA-512 hash of password, salted with salt
+// and hashed again spinCount times, as Excel hashes the passwords of
+// protected sheets.
+func hashPassword(password string, salt []byte, spinCount int) []byte {
+	h := sha512.New()
+	h.Write(salt)
+	for _, u := range utf16.Encode([]rune(password)) {
+		h.Write([]byte{byte(u), byte(u >> 8)})
+	}
+	hash := h.Sum(nil)
+	iterator := make([]byte, 4)
+	for i := 0; i < spinCount; i++ {
+		binary.LittleEndian.PutUint32(iterator, uint32(i))
+		h.Reset()
+		h.Write(hash)
+		h.Write(iterator)
+		hash = h.Sum(hash[:0])
+	}
+	return hash
+}
@@ -1,18 +1,60 @@
 package xlsx
 
 import (
+	"database/sql"
 	"errors"
+	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
+
+	"github.com/gobuffalo/nulls"
 )
 
 var (
-	errNilInterface     = errors.New("nil pointer is not a valid argument")
-	errNotStructPointer = errors.New("argument must be a pointer to struct")
-	errInvalidTag       = errors.New(`invalid tag: must have the format xlsx:idx`)
+	errNilInterface    = errors.New("nil pointer is not a valid argument")
+	errNotSlicePointer = errors.New("argument must be a pointer to slice")
+
+	// ErrNotStructPointer is returned when a struct, or a pointer to
+	// one, is needed and something else is given.
+	ErrNotStructPointer = errors.New("argument must be a pointer to struct")
+
+	// ErrInvalidTag is returned, wrapped together with the name of the
+	// field, for an xlsx struct tag that can't be parsed.
+	ErrInvalidTag = errors.New(`invalid tag: must have the format xlsx:idx`)
+
+	// ErrDuplicatePosition is returned, wrapped together with the names
+	// of the fields, when two fields are tagged with the same position
+	// and the WriterOptions ask for StrictPositions.
+	ErrDuplicatePosition = errors.New("duplicate tag position")
+
+	// ErrMissingHeader is returned, wrapped together with the name of
+	// the field, when a field is mapped to a header that isn't in the
+	// header row and the ReaderOptions ask for StrictHeaders.
+	ErrMissingHeader = errors.New("header not found")
+
+	// ErrInexactNumber is returned, wrapped together with the name of
+	// the field and the value, when a number can't be stored exactly in
+	// a cell and the WriterOptions ask for StrictNumbers.
+	ErrInexactNumber = errors.New("number can't be stored exactly")
+
+	// ErrEmptyCell is returned by the getters of a Cell, such as
+	// Cell.Int and Cell.Time, when the cell has no value.
+	ErrEmptyCell = errors.New("cell is empty")
+
+	// ErrCellType is returned, wrapped together with the value, by the
+	// getters of a Cell when the cell holds a value of another type,
+	// such as a string read as a time.
+	ErrCellType = errors.New("cell holds a value of another type")
 )
 
+// invalidTagError wraps err, returned by parseTag for the tag of
+// field, with the name of the field.
+func invalidTagError(field reflect.StructField, err error) error {
+	return fmt.Errorf("xlsx: invalid tag on field %s: %w", field.Name, err)
+}
+
 //XLSXUnmarshaler is the interface implemented for types that can unmarshal a Row
 //as a representation of themselves.
 type XLSXUnmarshaler interface {
@@ -21,9 +63,73 @@ type XLSXUnmarshaler interface {
 
 //ReadStruct reads a struct from r to ptr. Accepts a ptr
 //to struct. This code expects a tag xlsx:"N", where N is the index
-//of the cell to be used. Basic types like int,string,float64 and bool
-//are supported
+//or the column letters of the cell to be used. Basic types like int,string,float32,float64,
+//bool and complex128 are supported, as are time.Time and the sql.Null* and
+//nulls.* types. A null type is left invalid when its cell is empty, and
+//any other field is given its zero value.
+//A string field, or a string null type, is read as its cell is shown,
+//with the cell's number format applied; tagged with the "text" option,
+//as in xlsx:"3,text", it is read as the cell stores it instead, so a
+//zip code held as the text "02134" keeps its leading zero whatever the
+//format. A zip code stored as the number 2134 reads as "2134", as the
+//zero isn't kept anywhere in the file.
+//Fields mapped to a header with xlsx:"name=Label" can only be read
+//with ReadStructsWithOptions. A field whose type has a CellDecoder,
+//see RegisterCellDecoder, is read by it instead.
 func (r *Row) ReadStruct(ptr interface{}) error {
+	return r.readStruct(ptr, nil)
+}
+
+// headerColumns maps the labels of a header row to their columns, for
+// the fields tagged with xlsx:"name=Label".
+type headerColumns struct {
+	cols   map[string]int // keyed by headerKey
+	strict bool           // fail on a label that isn't in the row
+}
+
+// newHeaderColumns returns the headerColumns of the header row r. The
+// first of several columns with the same label is used.
+func newHeaderColumns(r *Row, strict bool) *headerColumns {
+	h := &headerColumns{cols: make(map[string]int), strict: strict}
+	r.ForEachCell(func(c *Cell) error {
+		label, err := c.FormattedValue()
+		if err != nil {
+			label = c.Value
+		}
+		key := headerKey(label)
+		if _, ok := h.cols[key]; !ok && key != "" {
+			h.cols[key] = c.num
+		}
+		return nil
+	})
+	return h
+}
+
+// headerKey returns the key under which the header 'label' is looked
+// up. Labels are matched ignoring case and surrounding spaces, as they
+// are typed in by hand.
+func headerKey(label string) string {
+	return strings.ToLower(strings.TrimSpace(label))
+}
+
+// column returns the column of the field tagged with ft, reporting
+// false when the field should be left alone.
+func (h *headerColumns) column(field reflect.StructField, ft fieldTag) (int, bool, error) {
+	if ft.name == "" {
+		return ft.pos, true, nil
+	}
+	if h == nil {
+		return 0, false, fmt.Errorf("xlsx: field %s is mapped to header %q, but there is no header row", field.Name, ft.name)
+	}
+	col, ok := h.cols[headerKey(ft.name)]
+	if !ok && h.strict {
+		return 0, false, fmt.Errorf("xlsx: no column headed %q for field %s: %w", ft.name, field.Name, ErrMissingHeader)
+	}
+	return col, ok, nil
+}
+
+// readStruct is ReadStruct, with the header columns h, which may be nil.
+func (r *Row) readStruct(ptr interface{}, h *headerColumns) error {
 	if ptr == nil {
 		return errNilInterface
 	}
@@ -35,11 +141,11 @@ func (r *Row) ReadStruct(ptr interface{}) error {
 	}
 	v := reflect.ValueOf(ptr)
 	if v.Kind() != reflect.Ptr {
-		return errNotStructPointer
+		return ErrNotStructPointer
 	}
 	v = v.Elem()
 	if v.Kind() != reflect.Struct {
-		return errNotStructPointer
+		return ErrNotStructPointer
 	}
 	n := v.NumField()
 	for i := 0; i < n; i++ {
@@ -49,10 +155,12 @@ func (r *Row) ReadStruct(ptr interface{}) error {
 		//even if it doesn't have a tag
 		//ignore if it has a - or empty tag
 		isTime := false
+		isNull := isNullType(field.Type)
+		dec := lookupCellDecoder(field.Type)
 		switch {
 		case idx == "-":
 			continue
-		case field.Type.Kind() == reflect.Ptr || field.Type.Kind() == reflect.Struct:
+		case dec == nil && !isNull && (field.Type.Kind() == reflect.Ptr || field.Type.Kind() == reflect.Struct):
 			var structPtr interface{}
 			if !v.Field(i).CanSet() {
 				continue
@@ -67,7 +175,7 @@ func (r *Row) ReadStruct(ptr interface{}) error {
 			if isTime {
 				break
 			}
-			err := r.ReadStruct(structPtr)
+			err := r.readStruct(structPtr, h)
 			if err != nil {
 				return err
 			}
@@ -75,19 +183,45 @@ func (r *Row) ReadStruct(ptr interface{}) error {
 		case len(idx) == 0:
 			continue
 		}
-		pos, err := strconv.Atoi(idx)
+		tag, err := parseTag(idx)
+		if err == nil && tag.auto {
+			err = ErrInvalidTag
+		}
 		if err != nil {
-			return errInvalidTag
+			return invalidTagError(field, err)
 		}
 
-		cell := r.GetCell(pos)
+		col, ok, err := h.column(field, tag)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		cell := r.GetCell(col)
 		fieldV := v.Field(i)
 		//continue if the field is not settable
 		if !fieldV.CanSet() {
 			continue
 		}
+		if dec != nil {
+			if err := decodeCell(dec, cell, fieldV); err != nil {
+				return err
+			}
+			continue
+		}
+		if isNull {
+			if err := readNullable(cell, fieldV, tag.text); err != nil {
+				return err
+			}
+			continue
+		}
+		if cell.Value == "" {
+			fieldV.Set(reflect.Zero(fieldV.Type()))
+			continue
+		}
 		if isTime {
-			t, err := cell.GetTime(false)
+			t, err := cell.Time()
 			if err != nil {
 				return err
 			}
@@ -100,7 +234,7 @@ func (r *Row) ReadStruct(ptr interface{}) error {
 		}
 		switch field.Type.Kind() {
 		case reflect.String:
-			value, err := cell.FormattedValue()
+			value, err := cellString(cell, tag.text)
 			if err != nil {
 				return err
 			}
@@ -111,7 +245,7 @@ func (r *Row) ReadStruct(ptr interface{}) error {
 				return err
 			}
 			fieldV.SetInt(value)
-		case reflect.Float64:
+		case reflect.Float32, reflect.Float64:
 			value, err := cell.Float()
 			if err != nil {
 				return err
@@ -120,9 +254,311 @@ func (r *Row) ReadStruct(ptr interface{}) error {
 		case reflect.Bool:
 			value := cell.Bool()
 			fieldV.SetBool(value)
+		case reflect.Complex64, reflect.Complex128:
+			value, err := parseComplex(cell.Value, fieldV.Type().Bits()/2)
+			if err != nil {
+				return err
+			}
+			fieldV.SetComplex(value)
 		}
 	}
 	value := v.Interface()
 	ptr = &value
 	return nil
 }
+
+// decodeCell sets fieldV to the value dec reads from cell.
+func decodeCell(dec CellDecoder, cell *Cell, fieldV reflect.Value) error {
+	value, err := dec(cell)
+	if err != nil || value == nil {
+		return err
+	}
+	v := reflect.ValueOf(value)
+	if !v.Type().AssignableTo(fieldV.Type()) {
+		return fmt.Errorf("xlsx: cell decoder for %s returned a %s", fieldV.Type(), v.Type())
+	}
+	fieldV.Set(v)
+	return nil
+}
+
+// ReaderOptions control how ReadStructsWithOptions reads a sheet. The
+// zero value gives the behaviour of ReadStructs.
+type ReaderOptions struct {
+	// HeaderRows is the number of rows at the top of the sheet, such as
+	// a row of column headers, that are skipped. The last of them is
+	// the header row in which fields tagged with xlsx:"name=Label" look
+	// up their column; a field whose label isn't found is left with
+	// its zero value.
+	HeaderRows int
+	// StrictHeaders makes reading fail with ErrMissingHeader when a
+	// field is mapped to a label that isn't in the header row.
+	StrictHeaders bool
+}
+
+// ReadStructs reads each row of sheet s into a new struct, as by
+// ReadStruct, and appends it to the slice pointed to by 'out'. The
+// slice may hold structs or pointers to structs. Rows that aren't in
+// the file at all are skipped, while an empty row that is gives a
+// struct of zero values. If a row can't be read, the error is returned
+// together with the number of the row, and the slice is left as it was.
+func (s *Sheet) ReadStructs(out interface{}) error {
+	return s.ReadStructsWithOptions(out, ReaderOptions{})
+}
+
+// ReadStructsWithOptions is like ReadStructs, but reads the sheet as
+// set out by 'options'.
+func (s *Sheet) ReadStructsWithOptions(out interface{}, options ReaderOptions) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return ErrNotStructPointer
+	}
+	slice := v.Elem()
+	elemType := slice.Type().Elem()
+	structType := elemType
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return ErrNotStructPointer
+	}
+
+	result := slice
+	var header *headerColumns
+	err := s.ForEachRow(func(r *Row) error {
+		if r.num < options.HeaderRows {
+			if r.num == options.HeaderRows-1 {
+				header = newHeaderColumns(r, options.StrictHeaders)
+			}
+			return nil
+		}
+		if header == nil && options.HeaderRows > 0 {
+			// The header row isn't in the file, so has no labels.
+			header = &headerColumns{strict: options.StrictHeaders}
+		}
+		elem := reflect.New(structType)
+		if err := r.readStruct(elem.Interface(), header); err != nil {
+			return fmt.Errorf("xlsx: reading row %d: %w", r.num+1, err)
+		}
+		if elemType.Kind() != reflect.Ptr {
+			elem = elem.Elem()
+		}
+		result = reflect.Append(result, elem)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	slice.Set(result)
+	return nil
+}
+
+// isNullType reports whether t is one of the nullable wrapper types
+// from database/sql or github.com/gobuffalo/nulls that ReadStruct
+// knows how to populate.
+func isNullType(t reflect.Type) bool {
+	switch reflect.Zero(t).Interface().(type) {
+	case sql.NullString, sql.NullBool, sql.NullInt64, sql.NullFloat64,
+		nulls.String, nulls.Bool, nulls.Int, nulls.Int64, nulls.Float64,
+		nulls.Time, nulls.UUID:
+		return true
+	}
+	return false
+}
+
+// cellString returns the text of cell that a string field is read
+// from: the value as shown, with the cell's number format applied, or
+// the value as stored when raw is set by the "text" option.
+func cellString(cell *Cell, raw bool) (string, error) {
+	if raw {
+		return cell.Value, nil
+	}
+	return cell.FormattedValue()
+}
+
+// readNullable sets the nullable value held by fieldV from cell.  An
+// empty cell results in a value with Valid set to false. A string type
+// is read as by cellString.
+func readNullable(cell *Cell, fieldV reflect.Value, raw bool) error {
+	valid := cell.Value != ""
+	var err error
+	switch t := fieldV.Addr().Interface().(type) {
+	case *sql.NullString:
+		if t.Valid = valid; valid {
+			t.String, err = cellString(cell, raw)
+		}
+	case *sql.NullBool:
+		if t.Valid = valid; valid {
+			t.Bool = cell.Bool()
+		}
+	case *sql.NullInt64:
+		if t.Valid = valid; valid {
+			t.Int64, err = cell.Int64()
+		}
+	case *sql.NullFloat64:
+		if t.Valid = valid; valid {
+			t.Float64, err = cell.Float()
+		}
+	case *nulls.String:
+		if t.Valid = valid; valid {
+			t.String, err = cellString(cell, raw)
+		}
+	case *nulls.Bool:
+		if t.Valid = valid; valid {
+			t.Bool = cell.Bool()
+		}
+	case *nulls.Int:
+		if t.Valid = valid; valid {
+			t.Int, err = cell.Int()
+		}
+	case *nulls.Int64:
+		if t.Valid = valid; valid {
+			t.Int64, err = cell.Int64()
+		}
+	case *nulls.Float64:
+		if t.Valid = valid; valid {
+			t.Float64, err = cell.Float()
+		}
+	case *nulls.Time:
+		if t.Valid = valid; valid {
+			t.Time, err = cell.Time()
+		}
+	case *nulls.UUID:
+		t.Valid = false
+		if valid {
+			err = t.UnmarshalText([]byte(cell.Value))
+		}
+	}
+	return err
+}
+
+// ReadSlice reads the cells of row r into the slice pointed to by 'e'.
+// Slices of string, the int and float kinds, bool and time.Time are
+// supported. 'cols' limits the number of cells read; if 'cols' is < 0,
+// the entire row will be read. The slice is grown if it is shorter
+// than the number of cells read, and any trailing elements beyond that
+// are left untouched. Reading stops at the first cell whose value
+// cannot be converted to the element type. Returns -1 if 'e' doesn't
+// point to a slice of a supported type, otherwise the number of cells
+// read.
+func (r *Row) ReadSlice(e interface{}, cols int) int {
+	v := reflect.ValueOf(e)
+	if v.Kind() != reflect.Ptr {
+		return -1
+	}
+	v = v.Elem()
+	if v.Kind() != reflect.Slice {
+		return -1
+	}
+
+	elemType := v.Type().Elem()
+	isTime := elemType == timeType
+	if !isTime {
+		switch elemType.Kind() {
+		case reflect.String, reflect.Bool,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Float32, reflect.Float64:
+		default:
+			return -1
+		}
+	}
+
+	n := r.cellCount
+	if cols >= 0 && cols < n {
+		n = cols
+	}
+	if v.Len() < n {
+		grown := reflect.MakeSlice(v.Type(), n, n)
+		reflect.Copy(grown, v)
+		v.Set(grown)
+	}
+
+	var i int
+	for i = 0; i < n; i++ {
+		cell := r.GetCell(i)
+		elem := v.Index(i)
+		if isTime {
+			t, err := cell.Time()
+			if err != nil {
+				return i
+			}
+			elem.Set(reflect.ValueOf(t))
+			continue
+		}
+		switch elemType.Kind() {
+		case reflect.String:
+			value, err := cell.FormattedValue()
+			if err != nil {
+				return i
+			}
+			elem.SetString(value)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			value, err := cell.Int64()
+			if err != nil {
+				return i
+			}
+			elem.SetInt(value)
+		case reflect.Float32, reflect.Float64:
+			value, err := cell.Float()
+			if err != nil {
+				return i
+			}
+			elem.SetFloat(value)
+		case reflect.Bool:
+			elem.SetBool(cell.Bool())
+		}
+	}
+	return i
+}
+
+// parseComplex parses s, a complex number such as "1.5+2i", "-3i" or
+// "4", optionally in parentheses, as written by formatComplex. Each
+// part is parsed by strconv.ParseFloat with bitSize.
+func parseComplex(s string, bitSize int) (complex128, error) {
+	fail := func(err error) (complex128, error) {
+		if numErr, ok := err.(*strconv.NumError); ok {
+			err = numErr.Err
+		}
+		return 0, &strconv.NumError{Func: "parseComplex", Num: s, Err: err}
+	}
+	t := s
+	if len(t) >= 2 && t[0] == '(' && t[len(t)-1] == ')' {
+		t = t[1 : len(t)-1]
+	}
+	if !strings.HasSuffix(t, "i") {
+		re, err := strconv.ParseFloat(t, bitSize)
+		if err != nil {
+			return fail(err)
+		}
+		return complex(re, 0), nil
+	}
+	t = t[:len(t)-1]
+	// The imaginary part starts at the last sign that isn't that of an
+	// exponent.
+	split := 0
+	for i := len(t) - 1; i > 0; i-- {
+		if t[i] == '+' || t[i] == '-' {
+			if prev := t[i-1]; prev != 'e' && prev != 'E' && prev != 'p' && prev != 'P' {
+				split = i
+				break
+			}
+		}
+	}
+	var re, im float64
+	var err error
+	if split > 0 {
+		if re, err = strconv.ParseFloat(t[:split], bitSize); err != nil {
+			return fail(err)
+		}
+	}
+	switch t[split:] {
+	case "", "+":
+		im = 1
+	case "-":
+		im = -1
+	default:
+		if im, err = strconv.ParseFloat(t[split:], bitSize); err != nil {
+			return fail(err)
+		}
+	}
+	return complex(re, im), nil
+}
@@ -1,12 +1,14 @@
 package xlsx
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"testing"
 	"time"
 
 	qt "github.com/frankban/quicktest"
+	"github.com/gobuffalo/nulls"
 )
 
 var (
@@ -107,6 +109,33 @@ func TestRead(t *testing.T) {
 		c.Assert(t2.Final, qt.Equals, t.Final)
 	})
 
+	// Times are read from the ISO 8601 dates of date cells, and from
+	// numbers with the epoch of the cell's file.
+	csRunO(c, "TestTimeDateCellsAnd1904", func(c *qt.C, option FileOption) {
+		type Timer struct {
+			Initial time.Time `xlsx:"0"`
+			Final   time.Time `xlsx:"1"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("TestReadTime")
+		row := sheet.AddRow()
+		iso := row.AddCell()
+		iso.Value = "2020-03-01T10:30:00Z"
+		iso.cellType = CellTypeDate
+		number := row.AddCell()
+		number.SetFloat(1)
+		f.SetDate1904(true)
+
+		var t Timer
+		c.Assert(row.ReadStruct(&t), qt.IsNil)
+		c.Assert(t.Initial, qt.Equals, time.Date(2020, 3, 1, 10, 30, 0, 0, time.UTC))
+		c.Assert(t.Final, qt.Equals, time.Date(1904, 1, 2, 0, 0, 0, 0, time.UTC))
+
+		var times []time.Time
+		c.Assert(row.ReadSlice(&times, -1), qt.Equals, 2)
+		c.Assert(times, qt.DeepEquals, []time.Time{t.Initial, t.Final})
+	})
+
 	csRunO(c, "TestEmbedStruct", func(c *qt.C, option FileOption) {
 		type Embed struct {
 			privateVal bool   `xlsx:"0"`
@@ -230,4 +259,187 @@ func TestRead(t *testing.T) {
 		c.Assert(readStruct.BoolVal, qt.Equals, structVal.BoolVal)
 	})
 
+	// The "text" option reads string fields as the cells store them,
+	// rather than as their number formats show them.
+	csRunO(c, "TestReadStructText", func(c *qt.C, option FileOption) {
+		type zip struct {
+			Shown   string         `xlsx:"0"`
+			Stored  string         `xlsx:"0,text"`
+			Text    string         `xlsx:"1,text"`
+			Amount  string         `xlsx:"2"`
+			Raw     string         `xlsx:"2,text"`
+			NullRaw sql.NullString `xlsx:"2,text"`
+			Nulls   nulls.String   `xlsx:"3,text"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("TestRead")
+		row := sheet.AddRow()
+		row.AddCell().SetFloatWithFormat(2134, "00000")
+		text := row.AddCell()
+		text.SetString("02134")
+		text.NumFmt = "0.00"
+		row.AddCell().SetFloatWithFormat(1234.5, "#,##0.00")
+
+		var z zip
+		c.Assert(row.ReadStruct(&z), qt.IsNil)
+		c.Assert(z.Shown, qt.Equals, "02134")
+		c.Assert(z.Stored, qt.Equals, "2134")
+		c.Assert(z.Text, qt.Equals, "02134")
+		c.Assert(z.Amount, qt.Equals, "1234.50")
+		c.Assert(z.Raw, qt.Equals, "1234.5")
+		c.Assert(z.NullRaw, qt.Equals, sql.NullString{String: "1234.5", Valid: true})
+		c.Assert(z.Nulls.Valid, qt.Equals, false)
+	})
+
+	csRunO(c, "TestReadStructNullTypes", func(c *qt.C, option FileOption) {
+		type structTest struct {
+			Float32Val  float32         `xlsx:"0"`
+			SQLString   sql.NullString  `xlsx:"1"`
+			SQLInt      sql.NullInt64   `xlsx:"2"`
+			SQLFloat    sql.NullFloat64 `xlsx:"3"`
+			SQLBool     sql.NullBool    `xlsx:"4"`
+			NullsString nulls.String    `xlsx:"5"`
+			NullsInt    nulls.Int       `xlsx:"6"`
+			NullsBool   nulls.Bool      `xlsx:"7"`
+			EmptyString sql.NullString  `xlsx:"8"`
+			EmptyInt    nulls.Int64     `xlsx:"9"`
+		}
+		structVal := structTest{
+			Float32Val:  1.5,
+			SQLString:   sql.NullString{String: "Smith", Valid: true},
+			SQLInt:      sql.NullInt64{Int64: 42, Valid: true},
+			SQLFloat:    sql.NullFloat64{Float64: 0.25, Valid: true},
+			SQLBool:     sql.NullBool{Bool: true, Valid: true},
+			NullsString: nulls.NewString("Eric"),
+			NullsInt:    nulls.NewInt(7),
+			NullsBool:   nulls.NewBool(true),
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("TestRead")
+		row := sheet.AddRow()
+		_, err := row.WriteStruct(&structVal, -1)
+		c.Assert(err, qt.IsNil)
+
+		readStruct := &structTest{
+			EmptyString: sql.NullString{String: "stale", Valid: true},
+		}
+		err = row.ReadStruct(readStruct)
+		c.Assert(err, qt.IsNil)
+		c.Assert(readStruct.Float32Val, qt.Equals, structVal.Float32Val)
+		c.Assert(readStruct.SQLString, qt.Equals, structVal.SQLString)
+		c.Assert(readStruct.SQLInt, qt.Equals, structVal.SQLInt)
+		c.Assert(readStruct.SQLFloat, qt.Equals, structVal.SQLFloat)
+		c.Assert(readStruct.SQLBool, qt.Equals, structVal.SQLBool)
+		c.Assert(readStruct.NullsString, qt.Equals, structVal.NullsString)
+		c.Assert(readStruct.NullsInt, qt.Equals, structVal.NullsInt)
+		c.Assert(readStruct.NullsBool, qt.Equals, structVal.NullsBool)
+		c.Assert(readStruct.EmptyString.Valid, qt.Equals, false)
+		c.Assert(readStruct.EmptyInt.Valid, qt.Equals, false)
+	})
+
+	csRunO(c, "TestReadSlice", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("TestRead")
+
+		strs := []string{"a", "b", "c"}
+		row := sheet.AddRow()
+		row.WriteSlice(&strs, -1)
+		readStrs := []string{}
+		c.Assert(row.ReadSlice(&readStrs, -1), qt.Equals, 3)
+		c.Assert(readStrs, qt.DeepEquals, strs)
+
+		ints := []int{1, 2, 3}
+		row = sheet.AddRow()
+		row.WriteSlice(&ints, -1)
+		readInts := []int{0, 0, 0, 0, 99}
+		c.Assert(row.ReadSlice(&readInts, 2), qt.Equals, 2)
+		c.Assert(readInts, qt.DeepEquals, []int{1, 2, 0, 0, 99})
+
+		floats := []float64{1.5, 2.25}
+		row = sheet.AddRow()
+		row.WriteSlice(&floats, -1)
+		var readFloats []float64
+		c.Assert(row.ReadSlice(&readFloats, -1), qt.Equals, 2)
+		c.Assert(readFloats, qt.DeepEquals, floats)
+
+		times := []time.Time{time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)}
+		row = sheet.AddRow()
+		row.WriteSlice(&times, -1)
+		var readTimes []time.Time
+		c.Assert(row.ReadSlice(&readTimes, -1), qt.Equals, 1)
+		c.Assert(readTimes[0].Equal(times[0]), qt.Equals, true)
+
+		c.Assert(row.ReadSlice(readTimes, -1), qt.Equals, -1)
+		var notSlice int
+		c.Assert(row.ReadSlice(&notSlice, -1), qt.Equals, -1)
+	})
+
+	csRunO(c, "TestReadStructs", func(c *qt.C, option FileOption) {
+		type person struct {
+			Name string         `xlsx:"0"`
+			Age  int            `xlsx:"1"`
+			Nick sql.NullString `xlsx:"2"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		sheet.AddRowValues("Name", "Age", "Nick")
+		sheet.AddRowValues("Eric", 20, "E")
+		sheet.AddRowValues("Anna", 30)
+		sheet.AddRow()
+		sheet.AddRowValues("Bob", "", "B")
+
+		var people []person
+		err := sheet.ReadStructsWithOptions(&people, ReaderOptions{HeaderRows: 1})
+		c.Assert(err, qt.IsNil)
+		c.Assert(people, qt.DeepEquals, []person{
+			{"Eric", 20, sql.NullString{String: "E", Valid: true}},
+			{"Anna", 30, sql.NullString{}},
+			{},
+			{"Bob", 0, sql.NullString{String: "B", Valid: true}},
+		})
+
+		var ptrs []*person
+		c.Assert(sheet.ReadStructsWithOptions(&ptrs, ReaderOptions{HeaderRows: 2}), qt.IsNil)
+		c.Assert(ptrs, qt.HasLen, 3)
+		c.Assert(ptrs[0].Name, qt.Equals, "Anna")
+
+		// The header row can't be read as a person.
+		people = people[:1]
+		err = sheet.ReadStructs(&people)
+		c.Assert(err, qt.ErrorMatches, "xlsx: reading row 1: .*")
+		c.Assert(people, qt.HasLen, 1)
+
+		c.Assert(sheet.ReadStructs(people), qt.Equals, ErrNotStructPointer)
+		var ints []int
+		c.Assert(sheet.ReadStructs(&ints), qt.Equals, ErrNotStructPointer)
+	})
+
+	csRunO(c, "TestReadStructsByHeaderName", func(c *qt.C, option FileOption) {
+		type contact struct {
+			Email string `xlsx:"name=Email"`
+			Name  string `xlsx:"name=Full Name"`
+			Age   int    `xlsx:"2"`
+			Phone string `xlsx:"name=Phone"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		sheet.AddRowValues(" full name", "EMAIL", "Age")
+		sheet.AddRowValues("Eric", "eric@example.com", 20)
+
+		var contacts []contact
+		c.Assert(sheet.ReadStructsWithOptions(&contacts, ReaderOptions{HeaderRows: 1}), qt.IsNil)
+		c.Assert(contacts, qt.DeepEquals, []contact{{"eric@example.com", "Eric", 20, ""}})
+
+		err := sheet.ReadStructsWithOptions(&contacts, ReaderOptions{HeaderRows: 1, StrictHeaders: true})
+		c.Assert(errors.Is(err, ErrMissingHeader), qt.Equals, true)
+		c.Assert(err, qt.ErrorMatches, `xlsx: reading row 2: xlsx: no column headed "Phone" for field Phone: header not found`)
+
+		row, err := sheet.Row(1)
+		c.Assert(err, qt.IsNil)
+		err = row.ReadStruct(&contact{})
+		c.Assert(err, qt.ErrorMatches, `xlsx: field Email is mapped to header "Email", but there is no header row`)
+
+		_, err = NewStructWriter(contact{})
+		c.Assert(errors.Is(err, ErrInvalidTag), qt.Equals, true)
+	})
 }
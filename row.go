@@ -10,6 +10,7 @@ type Row struct {
 	Sheet        *Sheet  // Sheet is a reference back to the Sheet that this Row is within.
 	Height       float64 // Height is the current height of the Row in PostScript Points
 	OutlineLevel uint8   // OutlineLevel contains the outline level of this Row.  Used for collapsing.
+	Collapsed    bool    // Collapsed is set on the Row after a collapsed group of Rows, see Sheet.GroupRows.
 	isCustom     bool    // isCustom is a flag that is set to true when the Row has been modified
 	num          int     // Num hold the positional number of the Row in the Sheet
 	cellCount    int     // The current number of cells
@@ -31,8 +32,12 @@ func (r *Row) SetHeightCM(ht float64) {
 // AddCell adds a new Cell to the Row
 func (r *Row) AddCell() *Cell {
 	cell := newCell(r, r.cellCount)
+	if r.cellCount < len(r.cells) {
+		r.cells[r.cellCount] = cell
+	} else {
+		r.cells = append(r.cells, cell)
+	}
 	r.cellCount++
-	r.cells = append(r.cells, cell)
 	return cell
 }
 
@@ -58,11 +63,16 @@ func (r *Row) growCellsSlice(newSize int) {
 		newSlice := make([]*Cell, newCap, newCap)
 		copy(newSlice, r.cells)
 		r.cells = newSlice
+	} else if newSize > len(r.cells) {
+		r.cells = r.cells[:newSize]
 	}
 }
 
 // GetCell returns the Cell at a given column index, creating it if it doesn't exist.
 func (r *Row) GetCell(colIdx int) *Cell {
+	if colIdx >= r.cellCount {
+		r.cellCount = colIdx + 1
+	}
 	if colIdx >= len(r.cells) {
 		cell := newCell(r, colIdx)
 		r.growCellsSlice(colIdx + 1)
@@ -79,6 +89,69 @@ func (r *Row) GetCell(colIdx int) *Cell {
 	return cell
 }
 
+// writtenCell returns the cell at colIdx, or an empty cell that isn't
+// added to the Row if colIdx is beyond the cells written.
+func (r *Row) writtenCell(colIdx int) *Cell {
+	if colIdx >= r.cellCount {
+		return newCell(r, colIdx)
+	}
+	return r.GetCell(colIdx)
+}
+
+// insertCell moves the cells from colIdx on right by one, leaving no
+// cell at colIdx and dropping any cell moved beyond the last column a
+// sheet can have.
+func (r *Row) insertCell(colIdx int) {
+	if colIdx >= len(r.cells) {
+		return
+	}
+	r.cells = append(r.cells, nil)
+	copy(r.cells[colIdx+1:], r.cells[colIdx:])
+	r.cells[colIdx] = nil
+	if len(r.cells) > Excel2006MaxColCount {
+		r.cells = r.cells[:Excel2006MaxColCount]
+	}
+	if colIdx < r.cellCount && r.cellCount < Excel2006MaxColCount {
+		r.cellCount++
+	}
+	r.renumberCells(colIdx + 1)
+}
+
+// removeCell removes the cell at colIdx, moving the cells right of it
+// left by one.
+func (r *Row) removeCell(colIdx int) {
+	if colIdx >= len(r.cells) {
+		return
+	}
+	r.cells = append(r.cells[:colIdx], r.cells[colIdx+1:]...)
+	if colIdx < r.cellCount {
+		r.cellCount--
+	}
+	r.renumberCells(colIdx)
+}
+
+// renumberCells sets the column numbers of the cells from colIdx on to
+// their places in the Row.
+func (r *Row) renumberCells(colIdx int) {
+	for i := colIdx; i < len(r.cells); i++ {
+		if r.cells[i] != nil {
+			r.cells[i].num = i
+		}
+	}
+}
+
+// Clear empties every cell in the Row with Cell.Clear, so that the
+// Row can be written again. The cells themselves, and with them their
+// styles and any merges starting in them, stay in place, as do the
+// height and other properties of the Row.
+func (r *Row) Clear() {
+	for _, cell := range r.cells {
+		if cell != nil {
+			cell.Clear()
+		}
+	}
+}
+
 // ForEachCell will call the provided CellVisitorFunc for each
 // currently defined cell in the Row.
 func (r *Row) ForEachCell(cvf CellVisitorFunc) error {
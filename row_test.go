@@ -33,4 +33,37 @@ func TestRow(t *testing.T) {
 		c.Assert(cell.Value, qt.Equals, cell2.Value)
 	})
 
+	csRunO(c, "TestClear", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("MySheet")
+		row := sheet.AddRow()
+		row.SetHeight(30)
+		style := NewStyle()
+		style.Font.Bold = true
+		number := row.AddCell()
+		number.SetFloatWithFormat(1.5, "0.00")
+		number.SetStyle(style)
+		formula := row.AddCell()
+		formula.SetFormula("A1*2")
+		formula.Merge(1, 0)
+		rich := row.AddCell()
+		rich.SetRichText([]RichTextRun{{Text: "bold"}})
+
+		row.Clear()
+		c.Assert(row.cellCount, qt.Equals, 3)
+		c.Assert(row.Height, qt.Equals, 30.0)
+		for i := 0; i < 3; i++ {
+			cell := row.GetCell(i)
+			c.Assert(cell.Value, qt.Equals, "", qt.Commentf("cell %d", i))
+			c.Assert(cell.Type(), qt.Equals, CellTypeString, qt.Commentf("cell %d", i))
+			c.Assert(cell.Formula(), qt.Equals, "", qt.Commentf("cell %d", i))
+			c.Assert(cell.RichText, qt.IsNil, qt.Commentf("cell %d", i))
+		}
+		c.Assert(row.GetCell(0).GetStyle().Font.Bold, qt.Equals, true)
+		c.Assert(row.GetCell(0).NumFmt, qt.Equals, "0.00")
+		c.Assert(row.GetCell(1).HMerge, qt.Equals, 1)
+
+		row.GetCell(0).SetInt(7)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "7")
+	})
 }
@@ -4,7 +4,10 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"math"
 	"strconv"
+	"strings"
+	"time"
 )
 
 // Sheet is a high level structure intended to provide user access to
@@ -25,8 +28,41 @@ type Sheet struct {
 	cellStore        CellStore
 	streamedRowCount int
 	currentRow       *Row
+
+	// ConditionalFormats are the conditional formatting rules of the
+	// sheet, see AddConditionalFormat.
+	ConditionalFormats []ConditionalFormat
+	// Images are the images placed on the sheet, see AddImage.
+	Images []Image
+	// Tables are the tables of the sheet, see AddTable.
+	Tables []Table
+	// TabColor is the ARGB color of the sheet's tab, such as
+	// "FF00B050", or empty for the default, see SetTabColor.
+	TabColor string
+	// veryHidden is set, along with Hidden, for a sheet that can only be
+	// shown again by a macro, see SetVisible.
+	veryHidden bool
+	// protection is the protection of the sheet, see Protect.
+	protection *xlsxSheetProtection
+	// lockedStyles are the copies of styles made by Cell.SetLocked.
+	lockedStyles map[lockedStyleKey]*Style
 }
 
+// SheetVisibility is whether the tab of a sheet is shown, see
+// SetVisible.
+type SheetVisibility string
+
+const (
+	// SheetVisible sheets have their tab shown.
+	SheetVisible SheetVisibility = sheetStateVisible
+	// SheetHidden sheets have their tab hidden, and can be shown again
+	// with the Unhide command.
+	SheetHidden SheetVisibility = sheetStateHidden
+	// SheetVeryHidden sheets have their tab hidden, and can only be shown
+	// again by a macro.
+	SheetVeryHidden SheetVisibility = sheetStateVeryHidden
+)
+
 // NewSheet constructs a Sheet with the default CellStore and returns
 // a pointer to it.
 func NewSheet(name string) (*Sheet, error) {
@@ -172,9 +208,11 @@ func (s *Sheet) AddRowAtIndex(index int) (*Row, error) {
 
 	if s.currentRow != nil {
 		s.cellStore.WriteRow(s.currentRow)
+		s.currentRow = nil
 	}
 
-	for i := index; i < s.MaxRow; i++ {
+	// Move the last row first, so that each row moves into a free place.
+	for i := s.MaxRow - 1; i >= index; i-- {
 		nRow, err := s.cellStore.ReadRow(makeRowKey(s, i))
 		if err != nil {
 			continue
@@ -196,6 +234,73 @@ func (s *Sheet) AddDataValidation(dv *xlsxDataValidation) {
 	s.DataValidations = append(s.DataValidations, dv)
 }
 
+// AddListValidation restricts the cells of the range rangeRef, such as
+// "C2:C100", to the given values, which spreadsheet programs offer in
+// a drop-down list. Any other value entered is rejected; the cells may
+// be left blank. The values are joined into a single formula, which
+// can't be longer than 255 characters, and so none of them may contain
+// a comma.
+func (s *Sheet) AddListValidation(rangeRef string, values []string) error {
+	if err := checkRangeRef(rangeRef); err != nil {
+		return err
+	}
+	for _, value := range values {
+		if strings.Contains(value, ",") {
+			return fmt.Errorf("list validation value %q contains a comma", value)
+		}
+	}
+	dv := &xlsxDataValidation{AllowBlank: true, Sqref: rangeRef}
+	if err := dv.SetDropList(values); err != nil {
+		return err
+	}
+	dv.SetError(StyleStop, nil, nil)
+	s.AddDataValidation(dv)
+	return nil
+}
+
+// AddNumberRangeValidation restricts the cells of the range rangeRef
+// to numbers from min to max inclusive, rejecting anything else
+// entered; the cells may be left blank.
+func (s *Sheet) AddNumberRangeValidation(rangeRef string, min, max float64) error {
+	if min > max {
+		min, max = max, min
+	}
+	return s.addRangeValidation(rangeRef, DataValidationTypeDecimal,
+		strconv.FormatFloat(min, 'f', -1, 64), strconv.FormatFloat(max, 'f', -1, 64))
+}
+
+// AddDateRangeValidation restricts the cells of the range rangeRef to
+// dates from 'from' to 'to' inclusive, rejecting anything else
+// entered; the cells may be left blank.
+func (s *Sheet) AddDateRangeValidation(rangeRef string, from, to time.Time) error {
+	if from.After(to) {
+		from, to = to, from
+	}
+	date1904 := s.File != nil && s.File.Date1904
+	return s.addRangeValidation(rangeRef, DataValidationTypeDate,
+		strconv.FormatFloat(TimeToExcelTime(from, date1904), 'f', -1, 64),
+		strconv.FormatFloat(TimeToExcelTime(to, date1904), 'f', -1, 64))
+}
+
+// addRangeValidation adds a validation of type t between the formulas
+// min and max to the cells of the range rangeRef.
+func (s *Sheet) addRangeValidation(rangeRef string, t DataValidationType, min, max string) error {
+	if err := checkRangeRef(rangeRef); err != nil {
+		return err
+	}
+	dv := &xlsxDataValidation{
+		AllowBlank: true,
+		Sqref:      rangeRef,
+		Type:       convDataValidationType(t),
+		Operator:   convDataValidationOperatior(DataValidationOperatorBetween),
+		Formula1:   min,
+		Formula2:   max,
+	}
+	dv.SetError(StyleStop, nil, nil)
+	s.AddDataValidation(dv)
+	return nil
+}
+
 // Removes a row at a specific index
 func (s *Sheet) RemoveRowAtIndex(index int) error {
 	if index < 0 || index >= s.MaxRow {
@@ -207,6 +312,7 @@ func (s *Sheet) RemoveRowAtIndex(index int) error {
 		} else {
 			s.cellStore.WriteRow(s.currentRow)
 		}
+		s.currentRow = nil
 	}
 	err := s.cellStore.RemoveRow(makeRowKey(s, index))
 	if err != nil {
@@ -228,9 +334,7 @@ func (s *Sheet) RemoveRowAtIndex(index int) error {
 // Make sure we always have as many Rows as we do cells.
 func (s *Sheet) maybeAddRow(rowCount int) {
 	if rowCount > s.MaxRow {
-		loopCnt := rowCount - s.MaxRow
-		for i := 0; i < loopCnt; i++ {
-
+		for i := s.MaxRow; i < rowCount; i++ {
 			row := &Row{Sheet: s, num: i, cells: make([]*Cell, 0)}
 			s.setCurrentRow(row)
 		}
@@ -259,6 +363,84 @@ func (s *Sheet) Row(idx int) (*Row, error) {
 	return r, nil
 }
 
+// CellByRef returns the cell at ref, given in A1 style such as "B7",
+// see ParseCellRef. Unlike Cell, it doesn't add rows or cells to the
+// sheet: a cell beyond those written is returned empty, so that its
+// getters return ErrEmptyCell and the like, but isn't part of the
+// sheet; SetCellByRef adds cells. An error is returned if ref is
+// malformed.
+func (s *Sheet) CellByRef(ref string) (*Cell, error) {
+	cellRef, err := ParseCellRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	row, err := s.writtenRow(cellRef.Row)
+	if err != nil {
+		return nil, err
+	}
+	return row.writtenCell(cellRef.Col), nil
+}
+
+// GetRange returns the cells from column startCol and row startRow to
+// column endCol and row endRow, all counted from zero and inclusive, as
+// a slice of rows of cells. As with CellByRef, no rows or cells are
+// added to the sheet, the cells beyond those written being returned
+// empty. An error is returned if the range is backwards or negative.
+func (s *Sheet) GetRange(startCol, startRow, endCol, endRow int) ([][]*Cell, error) {
+	if startCol < 0 || startRow < 0 || endCol < startCol || endRow < startRow {
+		return nil, fmt.Errorf("invalid range %d,%d to %d,%d", startCol, startRow, endCol, endRow)
+	}
+	cells := make([][]*Cell, 0, endRow-startRow+1)
+	for y := startRow; y <= endRow; y++ {
+		row, err := s.writtenRow(y)
+		if err != nil {
+			return nil, err
+		}
+		rowCells := make([]*Cell, 0, endCol-startCol+1)
+		for x := startCol; x <= endCol; x++ {
+			rowCells = append(rowCells, row.writtenCell(x))
+		}
+		cells = append(cells, rowCells)
+	}
+	return cells, nil
+}
+
+// GetRangeByRef is like GetRange, but takes the range in A1 style, such
+// as "A1:D10".
+func (s *Sheet) GetRangeByRef(ref string) ([][]*Cell, error) {
+	minx, miny, maxx, maxy, err := parseRangeRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	return s.GetRange(minx, miny, maxx, maxy)
+}
+
+// writtenRow returns the row at idx, or an empty row that isn't added
+// to the sheet if idx is beyond the rows written.
+func (s *Sheet) writtenRow(idx int) (*Row, error) {
+	if idx >= s.MaxRow {
+		return &Row{Sheet: s, num: idx}, nil
+	}
+	return s.Row(idx)
+}
+
+// SetCellByRef sets the value of the cell at ref, given in A1 style
+// such as "B7", to value as Cell.SetValue does, adding the cell to the
+// sheet if need be, and returns the cell. An error is returned if ref
+// is malformed.
+func (s *Sheet) SetCellByRef(ref string, value interface{}) (*Cell, error) {
+	cellRef, err := ParseCellRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	cell, err := s.Cell(cellRef.Row, cellRef.Col)
+	if err != nil {
+		return nil, err
+	}
+	cell.SetValue(value)
+	return cell, nil
+}
+
 // Return the Col that applies to this Column index, or return nil if no such Col exists
 func (s *Sheet) Col(idx int) *Col {
 	if s.Cols == nil {
@@ -338,7 +520,9 @@ func (s *Sheet) setCol(min, max int, setter func(col *Col)) {
 			setter(newCol)
 			s.Cols.Add(newCol)
 		default:
-			newCol := col.copyToRange(min, max)
+			// The column lies within the range, so it keeps
+			// its own bounds.
+			newCol := col.copyToRange(col.Min, col.Max)
 			setter(newCol)
 			s.Cols.Add(newCol)
 
@@ -347,13 +531,197 @@ func (s *Sheet) setCol(min, max int, setter func(col *Col)) {
 	return
 }
 
-// Set the width of a range of columns.
+// Set the width of a range of columns. The columns are numbered from
+// one, so SetColWidth(2, 4, 20) sets columns B to D. Columns in the
+// range that are already defined keep their other settings, and any
+// definition reaching outside the range is split, so that only its
+// part within the range changes.
 func (s *Sheet) SetColWidth(min, max int, width float64) {
 	s.setCol(min, max, func(col *Col) {
 		col.SetWidth(width)
 	})
 }
 
+// SetRowHeight sets the height, in points, of the row at index 'row',
+// counted from zero as for Row, adding the row if the sheet doesn't
+// reach it yet.
+func (s *Sheet) SetRowHeight(row int, height float64) error {
+	r, err := s.Row(row)
+	if err != nil {
+		return err
+	}
+	r.SetHeight(height)
+	return nil
+}
+
+// MergeRange is a range of merged cells, from the top-left cell at
+// StartCol, StartRow to the bottom-right one at EndCol, EndRow, all
+// counted from zero.
+type MergeRange struct {
+	StartCol, StartRow, EndCol, EndRow int
+}
+
+// Ref returns the range as a cell reference such as "A1:C2".
+func (m MergeRange) Ref() string {
+	return GetCellIDStringFromCoords(m.StartCol, m.StartRow) + cellRangeChar + GetCellIDStringFromCoords(m.EndCol, m.EndRow)
+}
+
+func (m MergeRange) overlaps(other MergeRange) bool {
+	return m.StartCol <= other.EndCol && other.StartCol <= m.EndCol &&
+		m.StartRow <= other.EndRow && other.StartRow <= m.EndRow
+}
+
+// MergeCells merges the cells from column startCol and row startRow to
+// column endCol and row endRow, inclusive and counted from zero, so
+// that they show as one cell with the value and style of the top-left
+// cell, as Cell.Merge on that cell does. An error is returned if the
+// range is reversed or a single cell, or if it overlaps a merge the
+// sheet already has.
+func (s *Sheet) MergeCells(startCol, startRow, endCol, endRow int) error {
+	m := MergeRange{startCol, startRow, endCol, endRow}
+	if startCol < 0 || startRow < 0 || endCol < startCol || endRow < startRow {
+		return fmt.Errorf("invalid merge range %d,%d to %d,%d", startCol, startRow, endCol, endRow)
+	}
+	if startCol == endCol && startRow == endRow {
+		return fmt.Errorf("merge range %s is a single cell", m.Ref())
+	}
+	for _, other := range s.MergedCells() {
+		if m.overlaps(other) {
+			return fmt.Errorf("merge range %s overlaps %s", m.Ref(), other.Ref())
+		}
+	}
+	cell, err := s.Cell(startRow, startCol)
+	if err != nil {
+		return err
+	}
+	cell.Merge(endCol-startCol, endRow-startRow)
+	return nil
+}
+
+// MergedCells returns the ranges of merged cells in the sheet, in the
+// order of their top-left cells, whether they were merged with
+// MergeCells or Cell.Merge or read from a file.
+func (s *Sheet) MergedCells() []MergeRange {
+	var merged []MergeRange
+	s.ForEachRow(func(row *Row) error {
+		return row.ForEachCell(func(cell *Cell) error {
+			if cell.HMerge > 0 || cell.VMerge > 0 {
+				merged = append(merged, MergeRange{
+					StartCol: cell.num,
+					StartRow: row.num,
+					EndCol:   cell.num + cell.HMerge,
+					EndRow:   row.num + cell.VMerge,
+				})
+			}
+			return nil
+		})
+	})
+	return merged
+}
+
+// FreezePane freezes the top 'rows' rows and the left 'cols' columns
+// of sheet s, so that they stay in view while the rest of the sheet is
+// scrolled. Freezing no rows and no columns removes the pane.
+func (s *Sheet) FreezePane(rows, cols int) {
+	if len(s.SheetViews) == 0 {
+		s.SheetViews = []SheetView{{}}
+	}
+	if rows <= 0 && cols <= 0 {
+		s.SheetViews[0].Pane = nil
+		return
+	}
+	if rows < 0 {
+		rows = 0
+	}
+	if cols < 0 {
+		cols = 0
+	}
+	pane := &Pane{
+		XSplit:      float64(cols),
+		YSplit:      float64(rows),
+		TopLeftCell: GetCellIDStringFromCoords(cols, rows),
+		State:       "frozen",
+	}
+	switch {
+	case rows > 0 && cols > 0:
+		pane.ActivePane = "bottomRight"
+	case rows > 0:
+		pane.ActivePane = "bottomLeft"
+	default:
+		pane.ActivePane = "topRight"
+	}
+	s.SheetViews[0].Pane = pane
+}
+
+// FreezeRows freezes the top n rows of sheet s, such as a header row,
+// keeping any columns that are frozen already.
+func (s *Sheet) FreezeRows(n int) {
+	_, cols := s.frozen()
+	s.FreezePane(n, cols)
+}
+
+// FreezeColumns freezes the left n columns of sheet s, keeping any rows
+// that are frozen already.
+func (s *Sheet) FreezeColumns(n int) {
+	rows, _ := s.frozen()
+	s.FreezePane(rows, n)
+}
+
+// frozen returns the number of rows and columns frozen in sheet s.
+func (s *Sheet) frozen() (rows, cols int) {
+	if len(s.SheetViews) == 0 || s.SheetViews[0].Pane == nil || s.SheetViews[0].Pane.State != "frozen" {
+		return 0, 0
+	}
+	pane := s.SheetViews[0].Pane
+	return int(pane.YSplit), int(pane.XSplit)
+}
+
+// defaultAutoSizeMaxWidth is the widest AutoSizeColumns makes a column.
+const defaultAutoSizeMaxWidth = 60
+
+// AutoSizeColumns sets the width of each column of sheet s that holds
+// a value to fit the widest value in it, header rows included, but to
+// no more than 60 characters. See AutoSizeColumnsWithMax.
+func (s *Sheet) AutoSizeColumns() error {
+	return s.AutoSizeColumnsWithMax(defaultAutoSizeMaxWidth)
+}
+
+// AutoSizeColumnsWithMax is like AutoSizeColumns, but limits the width
+// of a column to maxWidth, in the units of Col.Width, about the width
+// of a digit. The width of a value is worked out from the number of
+// characters it is shown with and the size of its font, so it's only
+// an estimate. Cells merged across columns are left out, as are
+// columns with no values, whose width is left alone.
+func (s *Sheet) AutoSizeColumnsWithMax(maxWidth float64) error {
+	widths := make(map[int]float64)
+	err := s.ForEachRow(func(r *Row) error {
+		for col, cell := range r.cells {
+			if cell == nil || cell.HMerge > 0 {
+				continue
+			}
+			if w := cell.displayWidth(); w > widths[col] {
+				widths[col] = w
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for col, w := range widths {
+		if w == 0 {
+			continue
+		}
+		// Leave room for the margins of the cell.
+		w = math.Ceil(w) + 1
+		if w > maxWidth {
+			w = maxWidth
+		}
+		s.SetColWidth(col+1, col+1, w)
+	}
+	return nil
+}
+
 // Set the outline level for a range of columns.
 func (s *Sheet) SetOutlineLevel(minCol, maxCol int, outlineLevel uint8) {
 	s.setCol(minCol, maxCol, func(col *Col) {
@@ -361,6 +729,79 @@ func (s *Sheet) SetOutlineLevel(minCol, maxCol int, outlineLevel uint8) {
 	})
 }
 
+// maxOutlineLevel is the deepest level of grouping Excel allows.
+const maxOutlineLevel = 7
+
+// GroupRows groups the rows from start to end, counted from zero and
+// inclusive, at the outline level 'level', from 1 to 7, so that Excel
+// shows the controls that expand and collapse them. Groups are nested
+// by grouping some of the rows of a group again at a deeper level. If
+// collapsed is true the rows are hidden, and the row after them, which
+// holds the control, is marked as collapsed.
+func (s *Sheet) GroupRows(start, end, level int, collapsed bool) error {
+	if err := checkGroup("row", start, end, Excel2006MaxRowIndex, level); err != nil {
+		return err
+	}
+	for i := start; i <= end; i++ {
+		row, err := s.Row(i)
+		if err != nil {
+			return err
+		}
+		row.OutlineLevel = uint8(level)
+		if collapsed {
+			row.Hidden = true
+		}
+	}
+	if collapsed && end < Excel2006MaxRowIndex {
+		row, err := s.Row(end + 1)
+		if err != nil {
+			return err
+		}
+		row.Collapsed = true
+	}
+	return nil
+}
+
+// GroupColumns groups the columns from start to end, counted from zero
+// and inclusive, at the outline level 'level' in the same way as
+// GroupRows groups rows, the column after them holding the control.
+func (s *Sheet) GroupColumns(start, end, level int, collapsed bool) error {
+	if err := checkGroup("column", start, end, Excel2006MaxColIndex, level); err != nil {
+		return err
+	}
+	s.setCol(start+1, end+1, func(col *Col) {
+		col.OutlineLevel = uint8(level)
+		if col.Width == 0 {
+			col.Width = ColWidth
+		}
+		if collapsed {
+			col.Hidden = true
+		}
+	})
+	if collapsed && end < Excel2006MaxColIndex {
+		s.setCol(end+2, end+2, func(col *Col) {
+			if col.Width == 0 {
+				col.Width = ColWidth
+			}
+			col.Collapsed = true
+		})
+	}
+	return nil
+}
+
+// checkGroup returns an error if start to end isn't a range of the
+// kind of line named by kind, up to max, or level isn't an outline
+// level.
+func checkGroup(kind string, start, end, max, level int) error {
+	if start < 0 || end < start || end > max {
+		return fmt.Errorf("invalid %s group %d to %d", kind, start, end)
+	}
+	if level < 1 || level > maxOutlineLevel {
+		return fmt.Errorf("invalid outline level %d, must be from 1 to %d", level, maxOutlineLevel)
+	}
+	return nil
+}
+
 // Set the type for a range of columns.
 func (s *Sheet) SetType(minCol, maxCol int, cellType CellType) {
 	s.setCol(minCol, maxCol, func(col *Col) {
@@ -369,6 +810,90 @@ func (s *Sheet) SetType(minCol, maxCol int, cellType CellType) {
 
 }
 
+// SetAutoFilter adds filter buttons to the header of the range of cells
+// rangeRef, such as "A1:C10", whose first row is the header and the
+// rest the rows filtered, replacing any auto filter the sheet had. An
+// empty rangeRef filters the sheet's used range, from A1 to the last
+// column and row written, which suits a sheet written by WriteStructs
+// with a header row. An error is returned if rangeRef isn't a range,
+// or is empty for an empty sheet.
+func (s *Sheet) SetAutoFilter(rangeRef string) error {
+	if rangeRef == "" {
+		maxCol := 0
+		err := s.ForEachRow(func(r *Row) error {
+			if r.cellCount > maxCol {
+				maxCol = r.cellCount
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if s.MaxRow == 0 || maxCol == 0 {
+			return errors.New("auto filter: the sheet is empty")
+		}
+		s.AutoFilter = &AutoFilter{TopLeftCell: "A1", BottomRightCell: GetCellIDStringFromCoords(maxCol-1, s.MaxRow-1)}
+		return nil
+	}
+	minx, miny, maxx, maxy, err := parseRangeRef(rangeRef)
+	if err != nil {
+		return fmt.Errorf("auto filter: %w", err)
+	}
+	s.AutoFilter = &AutoFilter{
+		TopLeftCell:     GetCellIDStringFromCoords(minx, miny),
+		BottomRightCell: GetCellIDStringFromCoords(maxx, maxy),
+	}
+	return nil
+}
+
+// SetTabColor sets the color of the sheet's tab to hex, given as
+// "RRGGBB" or "AARRGGBB", with or without a leading '#'. An empty hex
+// restores the default color. An error is returned, and the color left
+// as it was, if hex is neither.
+func (s *Sheet) SetTabColor(hex string) error {
+	color := strings.TrimPrefix(hex, "#")
+	if color == "" {
+		s.TabColor = ""
+		return nil
+	}
+	if len(color) != 6 && len(color) != 8 {
+		return fmt.Errorf("invalid tab color %q", hex)
+	}
+	if _, err := strconv.ParseUint(color, 16, 32); err != nil {
+		return fmt.Errorf("invalid tab color %q", hex)
+	}
+	if len(color) == 6 {
+		color = "FF" + color
+	}
+	s.TabColor = strings.ToUpper(color)
+	return nil
+}
+
+// SetVisible sets whether the sheet's tab is shown. Hidden is kept in
+// step, being true for both SheetHidden and SheetVeryHidden. An error is
+// returned if v isn't one of the SheetVisibility constants.
+func (s *Sheet) SetVisible(v SheetVisibility) error {
+	switch v {
+	case SheetVisible, SheetHidden, SheetVeryHidden:
+	default:
+		return fmt.Errorf("invalid sheet visibility %q", v)
+	}
+	s.Hidden = v != SheetVisible
+	s.veryHidden = v == SheetVeryHidden
+	return nil
+}
+
+// Visibility returns whether the sheet's tab is shown, see SetVisible.
+func (s *Sheet) Visibility() SheetVisibility {
+	switch {
+	case !s.Hidden:
+		return SheetVisible
+	case s.veryHidden:
+		return SheetVeryHidden
+	}
+	return SheetHidden
+}
+
 // When merging cells, the cell may be the 'original' or the 'covered'.
 // First, figure out which cells are merge starting points. Then create
 // the necessary cells underlying the merge area.
@@ -406,6 +931,9 @@ func (s *Sheet) handleMerged() {
 
 func (s *Sheet) makeSheetView(worksheet *xlsxWorksheet) {
 	for index, sheetView := range s.SheetViews {
+		if index >= len(worksheet.SheetViews.SheetView) {
+			break
+		}
 		if sheetView.Pane != nil {
 			worksheet.SheetViews.SheetView[index].Pane = &xlsxPane{
 				XSplit:      sheetView.Pane.XSplit,
@@ -414,7 +942,18 @@ func (s *Sheet) makeSheetView(worksheet *xlsxWorksheet) {
 				ActivePane:  sheetView.Pane.ActivePane,
 				State:       sheetView.Pane.State,
 			}
-
+			// The selection belongs in the pane that scrolls, or Excel
+			// starts out with the cursor hidden behind the frozen part.
+			if sheetView.Pane.ActivePane != "" {
+				for i := range worksheet.SheetViews.SheetView[index].Selection {
+					selection := &worksheet.SheetViews.SheetView[index].Selection[i]
+					selection.Pane = sheetView.Pane.ActivePane
+					if sheetView.Pane.TopLeftCell != "" {
+						selection.ActiveCell = sheetView.Pane.TopLeftCell
+						selection.SQRef = sheetView.Pane.TopLeftCell
+					}
+				}
+			}
 		}
 	}
 	if s.Selected {
@@ -500,7 +1039,9 @@ func (s *Sheet) makeRows(worksheet *xlsxWorksheet, styles *xlsxStyleSheet, refTa
 			xRow.CustomHeight = true
 			xRow.Ht = fmt.Sprintf("%g", row.Height)
 		}
+		xRow.Hidden = row.Hidden
 		xRow.OutlineLevel = row.OutlineLevel
+		xRow.Collapsed = row.Collapsed
 		if row.OutlineLevel > maxLevelRow {
 			maxLevelRow = row.OutlineLevel
 		}
@@ -519,7 +1060,7 @@ func (s *Sheet) makeRows(worksheet *xlsxWorksheet, styles *xlsxStyleSheet, refTa
 			style := cell.style
 			switch {
 			case style != nil:
-				XfId = handleStyleForXLSX(style, xNumFmt.NumFmtId, styles)
+				XfId = styles.styleXf(style, xNumFmt.NumFmtId)
 			case len(cell.NumFmt) == 0:
 				// Do nothing
 			case col == nil:
@@ -540,16 +1081,24 @@ func (s *Sheet) makeRows(worksheet *xlsxWorksheet, styles *xlsxStyleSheet, refTa
 			}
 			switch cell.cellType {
 			case CellTypeInline:
+				if len(cell.Value) == 0 && len(cell.RichText) == 0 {
+					// An empty inline string is kept, so that the cell
+					// holds an empty string rather than being blank.
+					xC.T = "inlineStr"
+					xC.Is = &xlsxSI{T: &xlsxT{}}
+					break
+				}
 				// Inline strings are turned into shared strings since they are more efficient.
 				// This is what Excel does as well.
 				fallthrough
 			case CellTypeString:
 				if len(cell.Value) > 0 {
 					xC.V = strconv.Itoa(refTable.AddString(cell.Value))
+					xC.T = "s"
 				} else if len(cell.RichText) > 0 {
 					xC.V = strconv.Itoa(refTable.AddRichText(cell.RichText))
+					xC.T = "s"
 				}
-				xC.T = "s"
 			case CellTypeNumeric:
 				// Numeric is the default, so the type can be left blank
 				xC.V = cell.Value
@@ -602,6 +1151,13 @@ func (s *Sheet) makeRows(worksheet *xlsxWorksheet, styles *xlsxStyleSheet, refTa
 				}
 			}
 
+			if cell.Comment != (Comment{}) {
+				if worksheet.comments == nil {
+					worksheet.comments = &xlsxComments{}
+				}
+				worksheet.comments.add(xC.R, cell.Comment)
+			}
+
 			if cell.HMerge > 0 || cell.VMerge > 0 {
 				// r == rownum, c == colnum
 				mc := xlsxMergeCell{}
@@ -666,10 +1222,15 @@ func (s *Sheet) makeXLSXSheet(refTable *RefTable, styles *xlsxStyleSheet, relati
 	// phantom cells underlying the area covered by the merged cell
 	s.handleMerged()
 
+	if s.TabColor != "" {
+		worksheet.SheetPr.TabColor = &xlsxColor{RGB: s.TabColor}
+	}
+	worksheet.SheetProtection = s.protection
 	s.makeSheetView(worksheet)
 	s.makeSheetFormatPr(worksheet)
 	maxLevelCol := s.makeCols(worksheet, styles)
 	s.makeDataValidations(worksheet)
+	s.makeConditionalFormatting(worksheet, styles)
 	s.makeRows(worksheet, styles, refTable, relations, maxLevelCol)
 
 	return worksheet
@@ -2,8 +2,11 @@ package xlsx
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/xml"
+	"strings"
 	"testing"
+	"time"
 
 	qt "github.com/frankban/quicktest"
 )
@@ -528,6 +531,962 @@ func TestMakeXLSXSheet(t *testing.T) {
 		c.Assert(sheet.Cols.FindColByIndex(2).Min, qt.Equals, 2)
 	})
 
+	csRunO(c, "SetColWidthOverlapping", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Sheet1")
+		sheet.SetColWidth(2, 6, 11)
+		sheet.SetColParameters(&Col{Min: 7, Max: 7, Hidden: true})
+		sheet.SetColWidth(4, 7, 20)
+
+		worksheet := sheet.makeXLSXSheet(NewSharedStringRefTable(), newXlsxStyleSheet(nil), nil)
+		type span struct {
+			Min, Max int
+			Width    float64
+		}
+		var got []span
+		for _, col := range worksheet.Cols.Col {
+			got = append(got, span{col.Min, col.Max, col.Width})
+		}
+		c.Assert(got, qt.DeepEquals, []span{{2, 3, 11}, {4, 6, 20}, {7, 7, 20}})
+		c.Assert(sheet.Col(6).Hidden, qt.Equals, true)
+	})
+
+	csRunO(c, "SetRowHeight", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Sheet1")
+		sheet.AddRow().AddCell().SetString("Title")
+		c.Assert(sheet.SetRowHeight(0, 30), qt.IsNil)
+		c.Assert(sheet.SetRowHeight(3, 12.5), qt.IsNil)
+		c.Assert(sheet.MaxRow, qt.Equals, 4)
+
+		worksheet := sheet.makeXLSXSheet(NewSharedStringRefTable(), newXlsxStyleSheet(nil), nil)
+		rows := worksheet.SheetData.Row
+		c.Assert(rows[0].Ht, qt.Equals, "30")
+		c.Assert(rows[0].CustomHeight, qt.Equals, true)
+		c.Assert(rows[3].Ht, qt.Equals, "12.5")
+		c.Assert(rows[1].CustomHeight, qt.Equals, false)
+	})
+
+	csRunO(c, "MergeCells", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Sheet1")
+		sheet.AddRow().AddCell().SetString("Quarterly report")
+		c.Assert(sheet.MergeCells(0, 0, 3, 0), qt.IsNil)
+		c.Assert(sheet.MergeCells(1, 2, 2, 4), qt.IsNil)
+
+		c.Assert(sheet.MergeCells(2, 4, 3, 5), qt.ErrorMatches, `merge range C5:D6 overlaps B3:C5`)
+		c.Assert(sheet.MergeCells(5, 5, 5, 5), qt.ErrorMatches, `merge range F6:F6 is a single cell`)
+		c.Assert(sheet.MergeCells(3, 0, 1, 0), qt.ErrorMatches, `invalid merge range 3,0 to 1,0`)
+
+		want := []MergeRange{{0, 0, 3, 0}, {1, 2, 2, 4}}
+		c.Assert(sheet.MergedCells(), qt.DeepEquals, want)
+		c.Assert(want[1].Ref(), qt.Equals, "B3:C5")
+
+		parts, err := file.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<mergeCells count="2"><mergeCell ref="A1:D1"></mergeCell><mergeCell ref="B3:C5"></mergeCell></mergeCells>`)
+
+		var buf bytes.Buffer
+		c.Assert(file.Write(&buf), qt.IsNil)
+		file, err = OpenBinary(buf.Bytes(), option)
+		c.Assert(err, qt.IsNil)
+		c.Assert(file.Sheets[0].MergedCells(), qt.DeepEquals, want)
+	})
+
+	csRunO(c, "AddImage", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Sheet1")
+		img := []byte("\x89PNG")
+		anchor := Anchor{FromCol: 1, FromRow: 1, ToCol: 2, ToRow: 3}
+		c.Assert(sheet.AddImage(img, "gif", anchor), qt.IsNil)
+		c.Assert(sheet.AddImage(img, "bmp", anchor), qt.ErrorMatches, `image: unsupported format "bmp"`)
+		c.Assert(sheet.AddImage(nil, "png", anchor), qt.ErrorMatches, `image: no image data`)
+		c.Assert(sheet.AddImage(img, "png", Anchor{FromCol: -1}), qt.ErrorMatches, `image: negative anchor`)
+		c.Assert(sheet.AddImage(img, "png", Anchor{FromCol: 2, FromRow: 1, ToCol: 1, ToRow: 3}), qt.ErrorMatches,
+			`image: anchor ends at column 1, row 3 before it starts at column 2, row 1`)
+		c.Assert(sheet.Images, qt.DeepEquals, []Image{{Data: img, Format: "gif", Anchor: anchor}})
+	})
+
+	csRunO(c, "TabColorAndVisibility", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		dashboard, _ := file.AddSheet("Dashboard")
+		dashboard.AddRow().AddCell().SetString("total")
+		data, _ := file.AddSheet("Data")
+		data.AddRow().AddCell().SetInt(1)
+		secret, _ := file.AddSheet("Secret")
+		secret.AddRow().AddCell().SetInt(2)
+
+		c.Assert(dashboard.SetTabColor("#00b050"), qt.IsNil)
+		c.Assert(dashboard.TabColor, qt.Equals, "FF00B050")
+		c.Assert(data.SetTabColor("80FF0000"), qt.IsNil)
+		c.Assert(data.TabColor, qt.Equals, "80FF0000")
+		c.Assert(data.SetTabColor("red"), qt.ErrorMatches, `invalid tab color "red"`)
+		c.Assert(data.SetTabColor("#GG0000"), qt.ErrorMatches, `invalid tab color "#GG0000"`)
+		c.Assert(data.TabColor, qt.Equals, "80FF0000")
+
+		c.Assert(data.SetVisible(SheetHidden), qt.IsNil)
+		c.Assert(secret.SetVisible(SheetVeryHidden), qt.IsNil)
+		c.Assert(secret.SetVisible("invisible"), qt.ErrorMatches, `invalid sheet visibility "invisible"`)
+		c.Assert(secret.Visibility(), qt.Equals, SheetVeryHidden)
+		c.Assert(secret.Hidden, qt.Equals, true)
+
+		parts, err := file.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<sheetPr filterMode="false"><tabColor rgb="FF00B050"></tabColor><pageSetUpPr`)
+		c.Assert(parts["xl/worksheets/sheet3.xml"], qt.Not(qt.Contains), `tabColor`)
+		c.Assert(parts["xl/workbook.xml"], qt.Contains, `<sheet name="Data" sheetId="2" r:id="rId2" state="hidden">`)
+		c.Assert(parts["xl/workbook.xml"], qt.Contains, `<sheet name="Secret" sheetId="3" r:id="rId3" state="veryHidden">`)
+
+		b, err := file.Bytes()
+		c.Assert(err, qt.IsNil)
+		file, err = OpenBinary(b, option)
+		c.Assert(err, qt.IsNil)
+		for _, want := range []struct {
+			name       string
+			color      string
+			visibility SheetVisibility
+		}{
+			{"Dashboard", "FF00B050", SheetVisible},
+			{"Data", "80FF0000", SheetHidden},
+			{"Secret", "", SheetVeryHidden},
+		} {
+			sheet := file.Sheet[want.name]
+			c.Assert(sheet.TabColor, qt.Equals, want.color, qt.Commentf(want.name))
+			c.Assert(sheet.Visibility(), qt.Equals, want.visibility, qt.Commentf(want.name))
+		}
+
+		c.Assert(file.Sheet["Secret"].SetVisible(SheetVisible), qt.IsNil)
+		c.Assert(file.Sheet["Secret"].Hidden, qt.Equals, false)
+		c.Assert(file.Sheet["Data"].SetTabColor(""), qt.IsNil)
+		c.Assert(file.Sheet["Data"].TabColor, qt.Equals, "")
+	})
+
+	csRunO(c, "GroupRowsAndColumns", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Sheet1")
+		for i := 0; i < 6; i++ {
+			row := sheet.AddRow()
+			for j := 0; j < 4; j++ {
+				row.AddCell().SetInt(i*4 + j)
+			}
+		}
+		c.Assert(sheet.GroupRows(1, 4, 1, false), qt.IsNil)
+		c.Assert(sheet.GroupRows(2, 3, 2, true), qt.IsNil)
+		c.Assert(sheet.GroupColumns(1, 2, 1, true), qt.IsNil)
+		c.Assert(sheet.GroupRows(3, 2, 1, false), qt.ErrorMatches, "invalid row group 3 to 2")
+		c.Assert(sheet.GroupRows(-1, 2, 1, false), qt.ErrorMatches, "invalid row group -1 to 2")
+		c.Assert(sheet.GroupColumns(0, Excel2006MaxColCount, 1, false), qt.ErrorMatches, "invalid column group 0 to 16384")
+		c.Assert(sheet.GroupColumns(0, 1, 8, false), qt.ErrorMatches, "invalid outline level 8, must be from 1 to 7")
+
+		parts, err := file.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		sheetXML := parts["xl/worksheets/sheet1.xml"]
+		c.Assert(sheetXML, qt.Contains, `outlineLevelCol="1" outlineLevelRow="2"`)
+		c.Assert(sheetXML, qt.Contains, `<row r="3" hidden="true" outlineLevel="2">`)
+		c.Assert(sheetXML, qt.Contains, `<row r="5" outlineLevel="1" collapsed="true">`)
+		c.Assert(sheetXML, qt.Contains, `<col collapsed="false" hidden="true" max="3" min="2" style="0" width="9.5" outlineLevel="1"></col>`)
+		c.Assert(sheetXML, qt.Contains, `<col collapsed="true" hidden="false" max="4" min="4" style="0" width="9.5"></col>`)
+
+		b, err := file.Bytes()
+		c.Assert(err, qt.IsNil)
+		file, err = OpenBinary(b, option)
+		c.Assert(err, qt.IsNil)
+		sheet = file.Sheets[0]
+		for i, want := range []struct {
+			level     uint8
+			hidden    bool
+			collapsed bool
+		}{
+			{0, false, false},
+			{1, false, false},
+			{2, true, false},
+			{2, true, false},
+			{1, false, true},
+			{0, false, false},
+		} {
+			row, err := sheet.Row(i)
+			c.Assert(err, qt.IsNil)
+			c.Assert(row.OutlineLevel, qt.Equals, want.level, qt.Commentf("row %d", i))
+			c.Assert(row.Hidden, qt.Equals, want.hidden, qt.Commentf("row %d", i))
+			c.Assert(row.Collapsed, qt.Equals, want.collapsed, qt.Commentf("row %d", i))
+		}
+		col := sheet.Cols.FindColByIndex(2)
+		c.Assert(col.OutlineLevel, qt.Equals, uint8(1))
+		c.Assert(col.Hidden, qt.Equals, true)
+		c.Assert(sheet.Cols.FindColByIndex(4).Collapsed, qt.Equals, true)
+	})
+
+	csRunO(c, "Protect", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Template")
+		row := sheet.AddRow()
+		row.AddCell().SetString("Name")
+		input := row.AddCell()
+		input.SetString("")
+		input.SetLocked(false)
+		hidden := row.AddCell()
+		hidden.SetFormula("1+1")
+		hidden.GetStyle().Protection.Hidden = true
+		hidden.GetStyle().ApplyProtection = true
+		c.Assert(row.GetCell(0).Locked(), qt.Equals, true)
+		c.Assert(input.Locked(), qt.Equals, false)
+
+		// Cells that share a style share its unlocked copy too.
+		style := NewStyle()
+		style.Font.Bold = true
+		first, second := sheet.AddRow().AddCell(), sheet.AddRow().AddCell()
+		first.SetStyle(style)
+		second.SetStyle(style)
+		first.SetLocked(false)
+		second.SetLocked(false)
+		c.Assert(first.GetStyle(), qt.Equals, second.GetStyle())
+		c.Assert(first.GetStyle(), qt.Not(qt.Equals), style)
+		c.Assert(first.GetStyle().Font.Bold, qt.Equals, true)
+		c.Assert(style.ApplyProtection, qt.Equals, false)
+		first.SetLocked(true)
+		c.Assert(first.Locked(), qt.Equals, true)
+		c.Assert(second.Locked(), qt.Equals, false)
+
+		_, ok := sheet.Protection()
+		c.Assert(ok, qt.Equals, false)
+		opts := ProtectionOptions{Password: "secret", AllowFormatColumns: true, AllowSort: true, DenySelectLockedCells: true}
+		c.Assert(sheet.Protect(opts), qt.IsNil)
+
+		parts, err := file.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Matches, `(?s).*</sheetData><sheetProtection algorithmName="SHA-512" hashValue="[^"]+" saltValue="[^"]+" spinCount="100000" sheet="true" objects="true" scenarios="true" formatColumns="false" selectLockedCells="true" sort="false"></sheetProtection>.*`)
+		c.Assert(parts["xl/styles.xml"], qt.Contains, `<protection locked="0" hidden="0"/>`)
+		c.Assert(parts["xl/styles.xml"], qt.Contains, `<protection locked="1" hidden="1"/>`)
+
+		b, err := file.Bytes()
+		c.Assert(err, qt.IsNil)
+		file, err = OpenBinary(b, option)
+		c.Assert(err, qt.IsNil)
+		sheet = file.Sheets[0]
+		got, ok := sheet.Protection()
+		c.Assert(ok, qt.Equals, true)
+		opts.Password = ""
+		c.Assert(got, qt.Equals, opts)
+		salt, err := base64.StdEncoding.DecodeString(sheet.protection.SaltValue)
+		c.Assert(err, qt.IsNil)
+		c.Assert(sheet.protection.HashValue, qt.Equals, base64.StdEncoding.EncodeToString(hashPassword("secret", salt, protectionSpinCount)))
+
+		cell, err := sheet.Cell(0, 0)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.Locked(), qt.Equals, true)
+		cell, err = sheet.Cell(0, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.Locked(), qt.Equals, false)
+		cell, err = sheet.Cell(0, 2)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.Locked(), qt.Equals, true)
+		c.Assert(cell.GetStyle().Protection.Hidden, qt.Equals, true)
+
+		sheet.Unprotect()
+		_, ok = sheet.Protection()
+		c.Assert(ok, qt.Equals, false)
+	})
+
+	csRunO(c, "SetAutoFilter", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Bob's Sheet")
+		c.Assert(sheet.SetAutoFilter(""), qt.ErrorMatches, "auto filter: the sheet is empty")
+		type item struct {
+			Name  string `xlsx:"0"`
+			Count int    `xlsx:"1"`
+			Price int    `xlsx:"2"`
+		}
+		sheet.AddRow().WriteStructHeader(item{})
+		_, err := sheet.WriteStructs([]item{{"hops", 1, 2}, {"malt", 3, 4}})
+		c.Assert(err, qt.IsNil)
+		sheet.SheetViews = []SheetView{{Pane: &Pane{YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft", State: "frozen"}}}
+
+		c.Assert(sheet.SetAutoFilter(""), qt.IsNil)
+		c.Assert(*sheet.AutoFilter, qt.Equals, AutoFilter{TopLeftCell: "A1", BottomRightCell: "C3"})
+		c.Assert(sheet.SetAutoFilter("$b$3:A1"), qt.IsNil)
+		c.Assert(*sheet.AutoFilter, qt.Equals, AutoFilter{TopLeftCell: "A1", BottomRightCell: "B3"})
+		c.Assert(sheet.SetAutoFilter("A1:nope"), qt.ErrorMatches, `auto filter: invalid range "A1:nope"`)
+		c.Assert(*sheet.AutoFilter, qt.Equals, AutoFilter{TopLeftCell: "A1", BottomRightCell: "B3"})
+
+		parts, err := file.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<autoFilter ref="A1:B3"></autoFilter>`)
+		c.Assert(parts["xl/workbook.xml"], qt.Contains, `<definedName name="_xlnm._FilterDatabase" localSheetId="0" hidden="true">&#39;Bob&#39;&#39;s Sheet&#39;!$A$1:$B$3</definedName>`)
+
+		b, err := file.Bytes()
+		c.Assert(err, qt.IsNil)
+		file, err = OpenBinary(b, option)
+		c.Assert(err, qt.IsNil)
+		sheet = file.Sheets[0]
+		c.Assert(*sheet.AutoFilter, qt.Equals, AutoFilter{TopLeftCell: "A1", BottomRightCell: "B3"})
+		c.Assert(sheet.SheetViews[0].Pane.State, qt.Equals, "frozen")
+
+		c.Assert(sheet.SetAutoFilter("A1:C3"), qt.IsNil)
+		parts, err = file.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(strings.Count(parts["xl/workbook.xml"], "_xlnm._FilterDatabase"), qt.Equals, 1)
+		c.Assert(parts["xl/workbook.xml"], qt.Contains, `s Sheet&#39;!$A$1:$C$3</definedName>`)
+	})
+
+	csRunO(c, "AddTable", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Orders")
+		type order struct {
+			Item  string `xlsx:"0"`
+			Count int    `xlsx:"1"`
+			Price int    `xlsx:"2"`
+		}
+		sheet.AddRow().WriteStructHeader(order{})
+		_, err := sheet.WriteStructs([]order{{"hops", 1, 2}, {"malt", 3, 4}})
+		c.Assert(err, qt.IsNil)
+		total := sheet.AddRow()
+		total.AddCell().SetString("Total")
+		total.AddCell().SetFormula("SUM(Orders[Count])")
+
+		opts := TableOptions{ShowRowStripes: true}
+		c.Assert(sheet.AddTable("Orders", "A1:C3", opts), qt.IsNil)
+		c.Assert(sheet.Tables, qt.DeepEquals, []Table{{Name: "Orders", Ref: "A1:C3", Columns: []string{"Item", "Count", "Price"}, Options: opts}})
+
+		c.Assert(sheet.AddTable("orders", "E1:F3", opts), qt.ErrorMatches, `table name "orders" is already used`)
+		c.Assert(sheet.AddTable("Two Words", "E1:F3", opts), qt.ErrorMatches, `invalid table name "Two Words"`)
+		c.Assert(sheet.AddTable("Other", "B2:D5", opts), qt.ErrorMatches, `table: range "B2:D5" overlaps table "Orders"`)
+		c.Assert(sheet.AddTable("Other", "A5:B5", opts), qt.ErrorMatches, `table: range "A5:B5" has no rows below its header`)
+		c.Assert(sheet.AddTable("Other", "A4:C5", opts), qt.ErrorMatches, `table: header cell B4 is empty`)
+		c.Assert(file.SetDefinedName("ORDERS", "Orders!$A$1", ""), qt.ErrorMatches, `defined name "ORDERS" is the name of a table`)
+		c.Assert(sheet.Tables, qt.HasLen, 1)
+
+		other, _ := file.AddSheet("Other")
+		header := other.AddRow()
+		header.AddCell().SetString("Name")
+		header.AddCell().SetString("name")
+		other.AddRow().AddCell().SetString("x")
+		c.Assert(other.AddTable("Orders", "A1:A2", TableOptions{}), qt.ErrorMatches, `table name "Orders" is already used`)
+		c.Assert(other.AddTable("People", "A1:B2", TableOptions{}), qt.ErrorMatches, `table: header "name" is repeated`)
+		c.Assert(other.AddTable("People", "A1:A2", TableOptions{StyleName: "TableStyleLight9", ShowFirstColumn: true}), qt.IsNil)
+
+		parts, err := file.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<tableParts count="1"><tablePart r:id="rId1"></tablePart></tableParts></worksheet>`)
+		c.Assert(parts["xl/worksheets/_rels/sheet1.xml.rels"], qt.Contains, `Target="../tables/table1.xml"`)
+		c.Assert(parts["xl/tables/table1.xml"], qt.Contains, `<table xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" id="1" name="Orders" displayName="Orders" ref="A1:C3" totalsRowShown="false"><autoFilter ref="A1:C3"></autoFilter><tableColumns count="3"><tableColumn id="1" name="Item"></tableColumn>`)
+		c.Assert(parts["xl/tables/table1.xml"], qt.Contains, `<tableStyleInfo name="TableStyleMedium2" showFirstColumn="false" showLastColumn="false" showRowStripes="true" showColumnStripes="false"></tableStyleInfo>`)
+		c.Assert(parts["xl/tables/table2.xml"], qt.Contains, `id="2" name="People"`)
+		c.Assert(parts["[Content_Types].xml"], qt.Contains, `<Override PartName="/xl/tables/table2.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.table+xml">`)
+
+		b, err := file.Bytes()
+		c.Assert(err, qt.IsNil)
+		file, err = OpenBinary(b, option)
+		c.Assert(err, qt.IsNil)
+		opts.StyleName = "TableStyleMedium2"
+		c.Assert(file.Sheets[0].Tables, qt.DeepEquals, []Table{{Name: "Orders", Ref: "A1:C3", Columns: []string{"Item", "Count", "Price"}, Options: opts}})
+		c.Assert(file.Sheets[1].Tables, qt.DeepEquals, []Table{{Name: "People", Ref: "A1:A2", Columns: []string{"Name"}, Options: TableOptions{StyleName: "TableStyleLight9", ShowFirstColumn: true}}})
+		cell, err := file.Sheets[0].Cell(3, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.Formula(), qt.Equals, "SUM(Orders[Count])")
+	})
+
+	csRunO(c, "CellByRef", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Sheet1")
+		row := sheet.AddRow()
+		row.AddCell().SetString("a")
+		row.AddCell().SetInt(7)
+
+		cell, err := sheet.CellByRef("B1")
+		c.Assert(err, qt.IsNil)
+		n, err := cell.Int()
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 7)
+		cell, err = sheet.CellByRef("$a$1")
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.Value, qt.Equals, "a")
+
+		for _, ref := range []string{"C1", "B9"} {
+			cell, err = sheet.CellByRef(ref)
+			c.Assert(err, qt.IsNil)
+			c.Assert(cell.Ref().String(), qt.Equals, ref)
+			_, err = cell.Int()
+			c.Assert(err, qt.Equals, ErrEmptyCell)
+		}
+		c.Assert(sheet.MaxRow, qt.Equals, 1)
+		c.Assert(row.cellCount, qt.Equals, 2)
+
+		for _, ref := range []string{"", "B", "7", "B0", "1B", "B7:C8", "XFE1", "A1048577"} {
+			_, err = sheet.CellByRef(ref)
+			c.Assert(err, qt.Not(qt.IsNil), qt.Commentf("%q", ref))
+		}
+		_, err = sheet.CellByRef("B 7")
+		c.Assert(err, qt.ErrorMatches, `invalid cell reference "B 7"`)
+
+		cell, err = sheet.SetCellByRef("C3", 1.5)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.Ref().String(), qt.Equals, "C3")
+		c.Assert(sheet.MaxRow, qt.Equals, 3)
+		cell, err = sheet.CellByRef("C3")
+		c.Assert(err, qt.IsNil)
+		f, err := cell.Float()
+		c.Assert(err, qt.IsNil)
+		c.Assert(f, qt.Equals, 1.5)
+		_, err = sheet.SetCellByRef("C", 1)
+		c.Assert(err, qt.ErrorMatches, `invalid cell reference "C"`)
+	})
+
+	csRunO(c, "GetRange", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Sheet1")
+		for i := 0; i < 3; i++ {
+			row := sheet.AddRow()
+			for j := 0; j <= i; j++ {
+				row.AddCell().SetString(GetCellIDStringFromCoords(j, i))
+			}
+		}
+
+		values := func(cells [][]*Cell) [][]string {
+			var out [][]string
+			for _, row := range cells {
+				var line []string
+				for _, cell := range row {
+					line = append(line, cell.Value)
+				}
+				out = append(out, line)
+			}
+			return out
+		}
+		cells, err := sheet.GetRange(1, 0, 2, 3)
+		c.Assert(err, qt.IsNil)
+		c.Assert(values(cells), qt.DeepEquals, [][]string{
+			{"", ""},
+			{"B2", ""},
+			{"B3", "C3"},
+			{"", ""},
+		})
+		c.Assert(cells[3][1].Ref().String(), qt.Equals, "C4")
+		c.Assert(sheet.MaxRow, qt.Equals, 3)
+
+		cells, err = sheet.GetRangeByRef("B3:A2")
+		c.Assert(err, qt.IsNil)
+		c.Assert(values(cells), qt.DeepEquals, [][]string{{"A2", "B2"}, {"A3", "B3"}})
+		cells, err = sheet.GetRangeByRef("C3")
+		c.Assert(err, qt.IsNil)
+		c.Assert(values(cells), qt.DeepEquals, [][]string{{"C3"}})
+
+		_, err = sheet.GetRange(2, 0, 1, 0)
+		c.Assert(err, qt.ErrorMatches, "invalid range 2,0 to 1,0")
+		_, err = sheet.GetRangeByRef("A1:")
+		c.Assert(err, qt.ErrorMatches, `invalid range "A1:"`)
+	})
+
+	csRunO(c, "InsertAndRemoveRowShiftRefs", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		data, err := f.AddSheet("Data")
+		c.Assert(err, qt.IsNil)
+		summary, err := f.AddSheet("Summary")
+		c.Assert(err, qt.IsNil)
+		for i := 1; i <= 5; i++ {
+			data.AddRow().AddCell().SetInt(i)
+		}
+		formulas := map[string]string{
+			"B6": "SUM(A1:A5)",
+			"C1": "$A$3*2",
+			"C2": "A5+Data!A4",
+			"D1": `"A3"&A3`,
+		}
+		for ref, formula := range formulas {
+			cell, err := data.SetCellByRef(ref, nil)
+			c.Assert(err, qt.IsNil)
+			cell.SetFormula(formula)
+		}
+		c.Assert(data.MergeCells(1, 1, 2, 3), qt.IsNil)
+		c.Assert(data.MergeCells(4, 4, 5, 4), qt.IsNil)
+		c.Assert(data.SetAutoFilter("A1:A5"), qt.IsNil)
+		c.Assert(data.AddNumberRangeValidation("A2:A5", 0, 10), qt.IsNil)
+		summaryRow := summary.AddRow()
+		for _, formula := range []string{"Data!A3+A3", "SUM('Data'!$A$2:$A$4)", "SUM(Data!2:3)", "SUM(Data!A:A)"} {
+			summaryRow.AddCell().SetFormula(formula)
+		}
+		c.Assert(f.SetDefinedName("Top", "Data!$A$1:$A$2", ""), qt.IsNil)
+
+		formulaAt := func(sheet *Sheet, ref string) string {
+			cell, err := sheet.CellByRef(ref)
+			c.Assert(err, qt.IsNil)
+			return cell.Formula()
+		}
+		summaryFormulas := func() []string {
+			var got []string
+			for _, ref := range []string{"A1", "B1", "C1", "D1"} {
+				got = append(got, formulaAt(summary, ref))
+			}
+			return got
+		}
+
+		row, err := data.InsertRow(2)
+		c.Assert(err, qt.IsNil)
+		row.AddCell().SetString("new")
+		c.Assert(data.MaxRow, qt.Equals, 7)
+		for ref, want := range map[string]string{"A3": "new", "A4": "3", "A6": "5"} {
+			cell, err := data.CellByRef(ref)
+			c.Assert(err, qt.IsNil)
+			c.Assert(cell.Value, qt.Equals, want, qt.Commentf(ref))
+		}
+		c.Assert(formulaAt(data, "B7"), qt.Equals, "SUM(A1:A6)")
+		c.Assert(formulaAt(data, "C1"), qt.Equals, "$A$4*2")
+		c.Assert(formulaAt(data, "C2"), qt.Equals, "A6+Data!A5")
+		c.Assert(formulaAt(data, "D1"), qt.Equals, `"A3"&A4`)
+		c.Assert(summaryFormulas(), qt.DeepEquals, []string{"Data!A4+A3", "SUM('Data'!$A$2:$A$5)", "SUM(Data!2:4)", "SUM(Data!A:A)"})
+		c.Assert(data.MergedCells(), qt.DeepEquals, []MergeRange{{1, 1, 2, 4}, {4, 5, 5, 5}})
+		c.Assert(*data.AutoFilter, qt.Equals, AutoFilter{"A1", "A6"})
+		c.Assert(data.DataValidations[0].Sqref, qt.Equals, "A2:A6")
+		refersTo, _ := f.DefinedName("Top", "")
+		c.Assert(refersTo, qt.Equals, "Data!$A$1:$A$2")
+
+		c.Assert(data.RemoveRow(3), qt.IsNil)
+		c.Assert(data.MaxRow, qt.Equals, 6)
+		c.Assert(formulaAt(data, "B6"), qt.Equals, "SUM(A1:A5)")
+		c.Assert(formulaAt(data, "C1"), qt.Equals, "#REF!*2")
+		c.Assert(formulaAt(data, "C2"), qt.Equals, "A5+Data!A4")
+		c.Assert(summaryFormulas(), qt.DeepEquals, []string{"Data!#REF!+A3", "SUM('Data'!$A$2:$A$4)", "SUM(Data!2:3)", "SUM(Data!A:A)"})
+		c.Assert(data.MergedCells(), qt.DeepEquals, []MergeRange{{1, 1, 2, 3}, {4, 4, 5, 4}})
+
+		c.Assert(data.RemoveRow(0), qt.IsNil)
+		c.Assert(data.MergedCells(), qt.DeepEquals, []MergeRange{{1, 0, 2, 2}, {4, 3, 5, 3}})
+		c.Assert(*data.AutoFilter, qt.Equals, AutoFilter{"A1", "A4"})
+		refersTo, _ = f.DefinedName("Top", "")
+		c.Assert(refersTo, qt.Equals, "Data!$A$1:$A$1")
+		c.Assert(data.RemoveRow(0), qt.IsNil)
+		c.Assert(data.MergedCells(), qt.DeepEquals, []MergeRange{{1, 0, 2, 1}, {4, 2, 5, 2}})
+		refersTo, _ = f.DefinedName("Top", "")
+		c.Assert(refersTo, qt.Equals, "Data!#REF!")
+
+		c.Assert(data.RemoveRow(data.MaxRow), qt.Not(qt.IsNil))
+		_, err = data.InsertRow(data.MaxRow + 1)
+		c.Assert(err, qt.Not(qt.IsNil))
+	})
+
+	csRunO(c, "InsertAndRemoveRowMoveTables", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, err := f.AddSheet("Orders")
+		c.Assert(err, qt.IsNil)
+		sheet.AddRow().WriteSlice(&[]string{"Heading"}, -1)
+		sheet.AddRow().WriteSlice(&[]string{"Item", "Count"}, -1)
+		sheet.AddRow().WriteSlice(&[]interface{}{"a", 1}, -1)
+		sheet.AddRow().WriteSlice(&[]interface{}{"b", 2}, -1)
+		c.Assert(sheet.AddTable("Orders", "A2:B4", TableOptions{}), qt.IsNil)
+		anchor := Anchor{FromCol: 3, FromRow: 1, FromRowOffset: 10, ToCol: 5, ToRow: 3, ToRowOffset: 20}
+		c.Assert(sheet.AddImage([]byte("GIF89a"), "gif", anchor), qt.IsNil)
+
+		// A row inserted into the table's body grows it, and one above
+		// the table moves it down with its header.
+		_, err = sheet.InsertRow(3)
+		c.Assert(err, qt.IsNil)
+		c.Assert(sheet.Tables[0].Ref, qt.Equals, "A2:B5")
+		c.Assert(sheet.Images[0].Anchor, qt.Equals, Anchor{FromCol: 3, FromRow: 1, FromRowOffset: 10, ToCol: 5, ToRow: 4, ToRowOffset: 20})
+		_, err = sheet.InsertRow(0)
+		c.Assert(err, qt.IsNil)
+		c.Assert(sheet.Tables[0].Ref, qt.Equals, "A3:B6")
+		c.Assert(sheet.Tables[0].Columns, qt.DeepEquals, []string{"Item", "Count"})
+		c.Assert(sheet.Images[0].Anchor, qt.Equals, Anchor{FromCol: 3, FromRow: 2, FromRowOffset: 10, ToCol: 5, ToRow: 5, ToRowOffset: 20})
+		header, err := sheet.Cell(2, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(header.Value, qt.Equals, "Count")
+
+		b, err := f.Bytes()
+		c.Assert(err, qt.IsNil)
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/tables/table1.xml"], qt.Contains, `ref="A3:B6"`)
+		f2, err := OpenBinary(b, option)
+		c.Assert(err, qt.IsNil)
+		c.Assert(f2.Sheets[0].Tables[0].Ref, qt.Equals, "A3:B6")
+
+		// Removing the image's last row moves its corner to the start
+		// of the next, and removing the table's header removes it.
+		c.Assert(sheet.RemoveRow(5), qt.IsNil)
+		c.Assert(sheet.Tables[0].Ref, qt.Equals, "A3:B5")
+		c.Assert(sheet.Images[0].Anchor, qt.Equals, Anchor{FromCol: 3, FromRow: 2, FromRowOffset: 10, ToCol: 5, ToRow: 5})
+		c.Assert(sheet.RemoveRow(2), qt.IsNil)
+		c.Assert(sheet.Tables, qt.HasLen, 0)
+		c.Assert(sheet.Images[0].Anchor, qt.Equals, Anchor{FromCol: 3, FromRow: 2, ToCol: 5, ToRow: 4})
+	})
+
+	csRunO(c, "InsertAndRemoveColumnShiftRefs", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		data, err := f.AddSheet("Data")
+		c.Assert(err, qt.IsNil)
+		summary, err := f.AddSheet("Summary")
+		c.Assert(err, qt.IsNil)
+		data.AddRow().WriteSlice(&[]int{1, 2, 3, 4, 5}, -1)
+		formulas := map[string]string{
+			"A2": "SUM(A1:E1)",
+			"B2": "$C$1*2",
+			"C2": "A1+Data!D1",
+			"D2": "SUM($A:$C)",
+			"E2": "SUM(1:1)",
+			"F2": "VLOOKUP(C1,Summary!A1:C3,2)",
+			"A6": "B1+C1",
+		}
+		for ref, formula := range formulas {
+			cell, err := data.SetCellByRef(ref, nil)
+			c.Assert(err, qt.IsNil)
+			cell.SetFormula(formula)
+		}
+		c.Assert(data.MergeCells(1, 2, 3, 2), qt.IsNil)
+		c.Assert(data.MergeCells(4, 3, 5, 4), qt.IsNil)
+		data.SetColWidth(2, 3, 20)
+		data.SetColWidth(5, 5, 30)
+		c.Assert(data.AddListValidation("C1:C10", []string{"a", "b"}), qt.IsNil)
+		c.Assert(data.AddNumberRangeValidation("E1:E5", 0, 10), qt.IsNil)
+		rule := ConditionalRule{Type: ConditionalCellValue, Operator: ConditionalOperatorGreaterThan, Formulas: []string{"$A$1"}, FillColor: "FFFF0000"}
+		c.Assert(data.AddConditionalFormat("B1:D1 E1", rule), qt.IsNil)
+		summaryRow := summary.AddRow()
+		summaryRow.AddCell().SetFormula("SUM(Data!B1:D1)")
+		summaryRow.AddCell().SetFormula("SUM(Data!C:C)")
+
+		formulaAt := func(sheet *Sheet, ref string) string {
+			cell, err := sheet.CellByRef(ref)
+			c.Assert(err, qt.IsNil)
+			return cell.Formula()
+		}
+		valuesOf := func(row int) []string {
+			var got []string
+			for col := 0; col < 6; col++ {
+				cell, err := data.Cell(row, col)
+				c.Assert(err, qt.IsNil)
+				got = append(got, cell.Value)
+			}
+			return got
+		}
+		width := func(col int) float64 {
+			if col := data.Cols.FindColByIndex(col); col != nil {
+				return col.Width
+			}
+			return 0
+		}
+		ranges := func() []string {
+			var got []string
+			for _, dv := range data.DataValidations {
+				got = append(got, dv.Sqref)
+			}
+			for _, cf := range data.ConditionalFormats {
+				got = append(got, cf.Ref)
+			}
+			return got
+		}
+
+		c.Assert(data.InsertColumn(2), qt.IsNil)
+		c.Assert(valuesOf(0), qt.DeepEquals, []string{"1", "2", "", "3", "4", "5"})
+		for ref, want := range map[string]string{
+			"A2": "SUM(A1:F1)",
+			"B2": "$D$1*2",
+			"D2": "A1+Data!E1",
+			"E2": "SUM($A:$D)",
+			"F2": "SUM(1:1)",
+			"G2": "VLOOKUP(D1,Summary!A1:C3,2)",
+			"A6": "B1+D1",
+		} {
+			c.Assert(formulaAt(data, ref), qt.Equals, want, qt.Commentf(ref))
+		}
+		c.Assert(formulaAt(summary, "A1"), qt.Equals, "SUM(Data!B1:E1)")
+		c.Assert(formulaAt(summary, "B1"), qt.Equals, "SUM(Data!D:D)")
+		c.Assert(data.MergedCells(), qt.DeepEquals, []MergeRange{{1, 2, 4, 2}, {5, 3, 6, 4}})
+		c.Assert([]float64{width(1), width(2), width(3), width(4), width(5), width(6)}, qt.DeepEquals, []float64{0, 20, 20, 20, 0, 30})
+		c.Assert(ranges(), qt.DeepEquals, []string{"D1:D10", "F1:F5", "B1:E1 F1"})
+		c.Assert(data.ConditionalFormats[0].Rule.Formulas, qt.DeepEquals, []string{"$A$1"})
+
+		c.Assert(data.RemoveColumn(1), qt.IsNil)
+		c.Assert(valuesOf(0), qt.DeepEquals, []string{"1", "", "3", "4", "5", ""})
+		for ref, want := range map[string]string{
+			"A2": "SUM(A1:E1)",
+			"C2": "A1+Data!D1",
+			"D2": "SUM($A:$C)",
+			"A6": "#REF!+C1",
+		} {
+			c.Assert(formulaAt(data, ref), qt.Equals, want, qt.Commentf(ref))
+		}
+		c.Assert(formulaAt(summary, "A1"), qt.Equals, "SUM(Data!B1:D1)")
+		c.Assert(data.MergedCells(), qt.DeepEquals, []MergeRange{{1, 2, 3, 2}, {4, 3, 5, 4}})
+		c.Assert([]float64{width(1), width(2), width(3), width(4), width(5)}, qt.DeepEquals, []float64{0, 20, 20, 0, 30})
+		c.Assert(ranges(), qt.DeepEquals, []string{"C1:C10", "E1:E5", "B1:D1 E1"})
+
+		// A range of the removed column alone goes with it.
+		c.Assert(data.RemoveColumn(2), qt.IsNil)
+		c.Assert(ranges(), qt.DeepEquals, []string{"D1:D5", "B1:C1 D1"})
+
+		c.Assert(data.InsertColumn(-1), qt.Not(qt.IsNil))
+		c.Assert(data.RemoveColumn(Excel2006MaxColCount), qt.Not(qt.IsNil))
+
+		b, err := f.Bytes()
+		c.Assert(err, qt.IsNil)
+		f, err = OpenBinary(b, option)
+		c.Assert(err, qt.IsNil)
+		data = f.Sheet["Data"]
+		c.Assert(valuesOf(0), qt.DeepEquals, []string{"1", "", "4", "5", "", ""})
+		c.Assert(formulaAt(data, "A2"), qt.Equals, "SUM(A1:D1)")
+	})
+
+	csRunO(c, "InsertAndRemoveColumnMoveTables", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, err := f.AddSheet("Orders")
+		c.Assert(err, qt.IsNil)
+		sheet.AddRow().WriteSlice(&[]string{"Item", "Count", "Price"}, -1)
+		sheet.AddRow().WriteSlice(&[]interface{}{"a", 1, 2.5}, -1)
+		c.Assert(sheet.AddTable("Orders", "A1:C2", TableOptions{}), qt.IsNil)
+		anchor := Anchor{FromCol: 4, FromRow: 0, ToCol: 5, ToRow: 2}
+		c.Assert(sheet.AddImage([]byte("GIF89a"), "gif", anchor), qt.IsNil)
+		headers := func() []string {
+			var got []string
+			for col := 0; col < 6; col++ {
+				cell, err := sheet.Cell(0, col)
+				c.Assert(err, qt.IsNil)
+				got = append(got, cell.Value)
+			}
+			return got
+		}
+
+		c.Assert(sheet.InsertColumn(1), qt.IsNil)
+		c.Assert(sheet.Tables[0].Ref, qt.Equals, "A1:D2")
+		c.Assert(sheet.Tables[0].Columns, qt.DeepEquals, []string{"Item", "Column1", "Count", "Price"})
+		c.Assert(headers(), qt.DeepEquals, []string{"Item", "Column1", "Count", "Price", "", ""})
+		c.Assert(sheet.InsertColumn(1), qt.IsNil)
+		c.Assert(sheet.Tables[0].Columns, qt.DeepEquals, []string{"Item", "Column2", "Column1", "Count", "Price"})
+		c.Assert(sheet.Images[0].Anchor, qt.Equals, Anchor{FromCol: 6, FromRow: 0, ToCol: 7, ToRow: 2})
+
+		// A column inserted before a table moves it, and removing its
+		// columns removes them from its Columns.
+		c.Assert(sheet.InsertColumn(0), qt.IsNil)
+		c.Assert(sheet.Tables[0].Ref, qt.Equals, "B1:F2")
+		c.Assert(sheet.Tables[0].Columns, qt.DeepEquals, []string{"Item", "Column2", "Column1", "Count", "Price"})
+		c.Assert(sheet.RemoveColumn(2), qt.IsNil)
+		c.Assert(sheet.RemoveColumn(2), qt.IsNil)
+		c.Assert(sheet.RemoveColumn(0), qt.IsNil)
+		c.Assert(sheet.Tables[0].Ref, qt.Equals, "A1:C2")
+		c.Assert(sheet.Tables[0].Columns, qt.DeepEquals, []string{"Item", "Count", "Price"})
+		c.Assert(headers(), qt.DeepEquals, []string{"Item", "Count", "Price", "", "", ""})
+		c.Assert(sheet.Images[0].Anchor, qt.Equals, Anchor{FromCol: 4, FromRow: 0, ToCol: 5, ToRow: 2})
+
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/tables/table1.xml"], qt.Contains, `ref="A1:C2"`)
+
+		for i := 0; i < 3; i++ {
+			c.Assert(sheet.RemoveColumn(0), qt.IsNil)
+		}
+		c.Assert(sheet.Tables, qt.HasLen, 0)
+		c.Assert(sheet.RemoveColumn(1), qt.IsNil)
+		c.Assert(sheet.RemoveColumn(1), qt.IsNil)
+		c.Assert(sheet.Images, qt.HasLen, 0)
+	})
+
+	csRunO(c, "CloneSheet", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		src, err := f.AddSheet("Template")
+		c.Assert(err, qt.IsNil)
+		bold := NewStyle()
+		bold.Font.Bold = true
+		row := src.AddRow()
+		for _, header := range []string{"Name", "Count"} {
+			cell := row.AddCell()
+			cell.SetString(header)
+			cell.SetStyle(bold)
+		}
+		src.AddRow().WriteSlice(&[]interface{}{"a", 1}, -1)
+		c.Assert(src.MergeCells(0, 3, 1, 3), qt.IsNil)
+		src.SetColWidth(1, 1, 25)
+		c.Assert(src.AddListValidation("A2:A10", []string{"a", "b"}), qt.IsNil)
+		c.Assert(src.AddTable("Orders", "A1:B2", TableOptions{}), qt.IsNil)
+		c.Assert(src.SetTabColor("FF00B050"), qt.IsNil)
+		c.Assert(src.Protect(ProtectionOptions{}), qt.IsNil)
+
+		clone, err := f.CloneSheet(src, "Copy")
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.Sheets, qt.HasLen, 2)
+		c.Assert(f.Sheet["Copy"], qt.Equals, clone)
+		c.Assert(clone.Selected, qt.Equals, false)
+		c.Assert(clone.MaxRow, qt.Equals, src.MaxRow)
+		c.Assert(clone.MergedCells(), qt.DeepEquals, []MergeRange{{0, 3, 1, 3}})
+		c.Assert(clone.Cols.FindColByIndex(1).Width, qt.Equals, 25.0)
+		c.Assert(clone.DataValidations, qt.HasLen, 1)
+		c.Assert(clone.DataValidations[0].Sqref, qt.Equals, "A2:A10")
+		c.Assert(clone.TabColor, qt.Equals, "FF00B050")
+		_, protected := clone.Protection()
+		c.Assert(protected, qt.Equals, true)
+		c.Assert(clone.Tables, qt.HasLen, 1)
+		c.Assert(clone.Tables[0].Name, qt.Equals, "Orders2")
+		c.Assert(clone.Tables[0].Columns, qt.DeepEquals, []string{"Name", "Count"})
+
+		// Changing the clone leaves the source as it was.
+		cell, err := clone.Cell(1, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.Value, qt.Equals, "1")
+		cell.SetInt(2)
+		cell, err = clone.Cell(0, 0)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.GetStyle().Font.Bold, qt.Equals, true)
+		cell.GetStyle().Font.Italic = true
+		clone.DataValidations[0].Sqref = "A2:A5"
+		clone.Cols.FindColByIndex(1).Width = 10
+		clone.Tables[0].Columns[0] = "Item"
+		cell, err = src.Cell(1, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.Value, qt.Equals, "1")
+		cell, err = src.Cell(0, 0)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.GetStyle().Font.Italic, qt.Equals, false)
+		c.Assert(src.DataValidations[0].Sqref, qt.Equals, "A2:A10")
+		c.Assert(src.Cols.FindColByIndex(1).Width, qt.Equals, 25.0)
+		c.Assert(src.Tables[0].Columns[0], qt.Equals, "Name")
+
+		_, err = f.CloneSheet(src, "Copy")
+		c.Assert(err, qt.Not(qt.IsNil))
+		_, err = f.CloneSheet(src, "Bad/Name")
+		c.Assert(err, qt.Not(qt.IsNil))
+		_, err = f.CloneSheet(nil, "Other")
+		c.Assert(err, qt.Not(qt.IsNil))
+		c.Assert(f.Sheets, qt.HasLen, 2)
+
+		clone, err = f.CloneSheet(src, "Second copy")
+		c.Assert(err, qt.IsNil)
+		c.Assert(clone.Tables[0].Name, qt.Equals, "Orders3")
+
+		b, err := f.Bytes()
+		c.Assert(err, qt.IsNil)
+		f, err = OpenBinary(b, option)
+		c.Assert(err, qt.IsNil)
+		c.Assert(f.Sheets, qt.HasLen, 3)
+		clone = f.Sheet["Copy"]
+		cell, err = clone.Cell(1, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.Value, qt.Equals, "2")
+		c.Assert(clone.MergedCells(), qt.DeepEquals, []MergeRange{{0, 3, 1, 3}})
+		c.Assert(clone.Tables[0].Name, qt.Equals, "Orders2")
+	})
+
+	csRunO(c, "AutoSizeColumns", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Sheet1")
+		header := sheet.AddRow()
+		header.AddCell().SetString("Name")
+		header.AddCell().SetString("Quantity")
+		header.AddCell().SetString("Notes")
+		header.AddCell().SetString("When")
+		row := sheet.AddRow()
+		row.AddCell().SetString("A rather longer name")
+		row.AddCell().SetInt(12)
+		row.AddCell().SetString(strings.Repeat("x", 100))
+		row.AddCell().SetDate(time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC))
+		row = sheet.AddRow()
+		row.AddCell().SetString("两个字")
+		big := row.AddCell()
+		big.SetString("Bold")
+		big.GetStyle().Font.Size = 24
+		big.GetStyle().Font.Bold = true
+
+		c.Assert(sheet.AutoSizeColumns(), qt.IsNil)
+		c.Assert(sheet.Cols.FindColByIndex(1).Width, qt.Equals, float64(21))
+		c.Assert(sheet.Cols.FindColByIndex(1).CustomWidth, qt.Equals, true)
+		c.Assert(sheet.Cols.FindColByIndex(2).Width, qt.Equals, float64(10))
+		c.Assert(sheet.Cols.FindColByIndex(3).Width, qt.Equals, float64(60))
+		c.Assert(sheet.Cols.FindColByIndex(4).Width, qt.Equals, float64(9))
+		c.Assert(sheet.Cols.FindColByIndex(5), qt.IsNil)
+
+		c.Assert(sheet.AutoSizeColumnsWithMax(15), qt.IsNil)
+		c.Assert(sheet.Cols.FindColByIndex(1).Width, qt.Equals, float64(15))
+		c.Assert(sheet.Cols.FindColByIndex(3).Width, qt.Equals, float64(15))
+	})
+
+	csRunO(c, "FreezePane", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Sheet1")
+		sheet.AddRow().AddCell().SetString("Header")
+		sheet.FreezeRows(1)
+		c.Assert(*sheet.SheetViews[0].Pane, qt.Equals, Pane{YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft", State: "frozen"})
+		sheet.FreezeColumns(2)
+		c.Assert(*sheet.SheetViews[0].Pane, qt.Equals, Pane{XSplit: 2, YSplit: 1, TopLeftCell: "C2", ActivePane: "bottomRight", State: "frozen"})
+
+		parts, err := file.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<pane xSplit="2" ySplit="1" topLeftCell="C2" activePane="bottomRight" state="frozen"></pane><selection pane="bottomRight" activeCell="C2" activeCellId="0" sqref="C2"></selection>`)
+
+		var buf bytes.Buffer
+		c.Assert(file.Write(&buf), qt.IsNil)
+		file, err = OpenBinary(buf.Bytes(), option)
+		c.Assert(err, qt.IsNil)
+		sheet = file.Sheets[0]
+		c.Assert(*sheet.SheetViews[0].Pane, qt.Equals, Pane{XSplit: 2, YSplit: 1, TopLeftCell: "C2", ActivePane: "bottomRight", State: "frozen"})
+
+		sheet.FreezeRows(0)
+		c.Assert(*sheet.SheetViews[0].Pane, qt.Equals, Pane{XSplit: 2, TopLeftCell: "C1", ActivePane: "topRight", State: "frozen"})
+		sheet.FreezePane(0, 0)
+		c.Assert(sheet.SheetViews[0].Pane, qt.IsNil)
+	})
+
+	csRunO(c, "ConditionalFormat", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Sheet1")
+		for i := 0; i < 5; i++ {
+			sheet.AddRow().AddCell().SetInt(i * 50)
+		}
+		formats := []ConditionalFormat{
+			{"A1:A5", ConditionalRule{Type: ConditionalCellValue, Operator: ConditionalOperatorGreaterThan, Formulas: []string{"100"}, FontColor: "FF9C0006", FillColor: "FFFFC7CE", Bold: true}},
+			{"A1:A5", ConditionalRule{Type: ConditionalCellValue, Operator: ConditionalOperatorBetween, Formulas: []string{"10", "$B$1"}, FillColor: "FFC6EFCE"}},
+			{"B1:B5 D1", ConditionalRule{Type: ConditionalDataBar, Colors: []string{"FF638EC6"}}},
+			{"C1:C5", ConditionalRule{Type: ConditionalColorScale, Colors: []string{"FFF8696B", "FFFFEB84", "FF63BE7B"}}},
+			{"E1:E5", ConditionalRule{Type: ConditionalColorScale, Colors: []string{"FFFFFFFF", "FF63BE7B"}}},
+		}
+		for _, cf := range formats {
+			c.Assert(sheet.AddConditionalFormat(cf.Ref, cf.Rule), qt.IsNil)
+		}
+
+		err := sheet.AddConditionalFormat("A1:", ConditionalRule{Type: ConditionalDataBar, Colors: []string{"FF638EC6"}})
+		c.Assert(err, qt.ErrorMatches, `invalid range "A1:": .*`)
+		err = sheet.AddConditionalFormat("A1", ConditionalRule{Type: ConditionalCellValue, Operator: ConditionalOperatorNotBetween, Formulas: []string{"1"}})
+		c.Assert(err, qt.ErrorMatches, `conditional format: operator notBetween needs 2 formulas, got 1`)
+		err = sheet.AddConditionalFormat("A1", ConditionalRule{Type: ConditionalColorScale, Colors: []string{"FF000000"}})
+		c.Assert(err, qt.ErrorMatches, `conditional format: a color scale needs 2 or 3 colors, got 1`)
+		c.Assert(sheet.ConditionalFormats, qt.HasLen, len(formats))
+
+		parts, err := file.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<conditionalFormatting sqref="A1:A5"><cfRule type="cellIs" dxfId="0" priority="1" operator="greaterThan"><formula>100</formula></cfRule></conditionalFormatting>`)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<cfRule type="colorScale" priority="4"><colorScale><cfvo type="min"></cfvo><cfvo type="percentile" val="50"></cfvo><cfvo type="max"></cfvo>`)
+		c.Assert(parts["xl/styles.xml"], qt.Contains, `<dxfs count="2"><dxf><font><color rgb="FF9C0006"/><b/></font><fill><patternFill patternType="solid"><fgColor rgb="FFFFC7CE"/><bgColor rgb="FFFFC7CE"/></patternFill></fill></dxf>`)
+
+		var buf bytes.Buffer
+		c.Assert(file.Write(&buf), qt.IsNil)
+		file, err = OpenBinary(buf.Bytes(), option)
+		c.Assert(err, qt.IsNil)
+		c.Assert(file.Sheets[0].ConditionalFormats, qt.DeepEquals, formats)
+	})
+
+	csRunO(c, "AddListValidation", func(c *qt.C, option FileOption) {
+		file := NewFile(option)
+		sheet, _ := file.AddSheet("Sheet1")
+
+		c.Assert(sheet.AddListValidation("B2:B100", []string{"Open", "Closed"}), qt.IsNil)
+		c.Assert(sheet.AddNumberRangeValidation("C2:C100", 100, 0.5), qt.IsNil)
+		c.Assert(sheet.AddDateRangeValidation("D2", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2020, 12, 31, 0, 0, 0, 0, time.UTC)), qt.IsNil)
+		c.Assert(sheet.AddListValidation("B2:", []string{"Open"}), qt.ErrorMatches, `invalid range "B2:": .*`)
+		c.Assert(sheet.AddListValidation("B2", []string{"a,b"}), qt.ErrorMatches, `list validation value "a,b" contains a comma`)
+		c.Assert(sheet.DataValidations, qt.HasLen, 3)
+
+		parts, err := file.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		xSheet := parts["xl/worksheets/sheet1.xml"]
+		c.Assert(xSheet, qt.Contains, `<dataValidation allowBlank="true" showErrorMessage="true" errorStyle="stop" type="list" sqref="B2:B100"><formula1>&#34;Open,Closed&#34;</formula1>`)
+		c.Assert(xSheet, qt.Contains, `type="decimal" sqref="C2:C100"><formula1>0.5</formula1><formula2>100</formula2>`)
+		c.Assert(xSheet, qt.Contains, `type="date" sqref="D2"><formula1>43831</formula1><formula2>44196</formula2>`)
+
+		var buf bytes.Buffer
+		c.Assert(file.Write(&buf), qt.IsNil)
+		file, err = OpenBinary(buf.Bytes(), option)
+		c.Assert(err, qt.IsNil)
+		dvs := file.Sheets[0].DataValidations
+		c.Assert(dvs, qt.HasLen, 3)
+		c.Assert(dvs[0].Sqref, qt.Equals, "B2:B100")
+		c.Assert(dvs[0].Type, qt.Equals, "list")
+		c.Assert(dvs[0].Formula1, qt.Equals, `"Open,Closed"`)
+		c.Assert(dvs[1].Operator, qt.Equals, "between")
+	})
+
 	csRunO(c, "SetDataValidation", func(c *qt.C, option FileOption) {
 		file := NewFile(option)
 		sheet, _ := file.AddSheet("Sheet1")
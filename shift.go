@@ -0,0 +1,572 @@
+package xlsx
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// refError is what a reference becomes once the cells it refers to
+// have all been removed.
+const refError = "#REF!"
+
+var (
+	// rowRefRegexp matches a whole row in a range such as "$2:$5".
+	rowRefRegexp = regexp.MustCompile(`^\$?[1-9][0-9]*$`)
+	// colRefRegexp matches a whole column in a range such as "B:D".
+	colRefRegexp = regexp.MustCompile(`^\$?[A-Z]{1,3}$`)
+)
+
+// InsertRow inserts an empty row into the sheet at index, counted from
+// zero, moving the rows from index on down by one, and returns it. As
+// in Excel, the references that formulas and defined names make to
+// the rows that moved follow them, whether they are relative or
+// absolute such as $A$1, ranges that span the new row grow to take it
+// in, and so do merged cells, the sheet's auto filter, its tables and
+// images, and the ranges of its data validations and conditional
+// formats. An error is returned if index is beyond one past the last
+// row.
+func (s *Sheet) InsertRow(index int) (*Row, error) {
+	merged := s.MergedCells()
+	row, err := s.AddRowAtIndex(index)
+	if err != nil {
+		return nil, err
+	}
+	return row, s.shift(refShift{sheet: s.Name, at: index, n: 1}, merged)
+}
+
+// RemoveRow removes the row at index, counted from zero, from the
+// sheet, moving the rows below it up by one. References to the rows
+// that moved follow them as they do for InsertRow, ranges that span
+// the row shrink, and a reference to a cell of the row becomes #REF!,
+// as does a range that was only the row. A table whose header row is
+// removed, or that is left with no rows below its header, is removed
+// with it, and so is an image that was only over the row. An error is
+// returned if there is no row at index.
+func (s *Sheet) RemoveRow(index int) error {
+	merged := s.MergedCells()
+	if err := s.RemoveRowAtIndex(index); err != nil {
+		return err
+	}
+	return s.shift(refShift{sheet: s.Name, at: index, n: -1}, merged)
+}
+
+// InsertColumn inserts an empty column into the sheet at index, counted
+// from zero, moving the cells from that column on right by one, as
+// InsertRow does for rows: references to the columns that moved follow
+// them, and ranges that span the new column, including those of column
+// widths and styles, grow to take it in. A column inserted into a table
+// is named "Column1", or the next such name not already taken, in the
+// table's header. Cells pushed beyond the last column a sheet can have
+// are dropped. An error is returned if index is beyond that column.
+func (s *Sheet) InsertColumn(index int) error {
+	if index < 0 || index > Excel2006MaxColIndex {
+		return fmt.Errorf("cannot insert column: index out of range: %d", index)
+	}
+	merged := s.MergedCells()
+	err := s.ForEachRow(func(row *Row) error {
+		row.insertCell(index)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if index < s.MaxCol {
+		s.MaxCol++
+	}
+	return s.shift(refShift{sheet: s.Name, cols: true, at: index, n: 1}, merged)
+}
+
+// RemoveColumn removes the column at index, counted from zero, from
+// the sheet, moving the cells right of it left by one, as RemoveRow
+// does for rows: ranges that span the column shrink, and references to
+// its cells alone become #REF!. The column is removed from a table that
+// spans it, and a table or image that was only over the column is
+// removed with it. An error is returned if index is beyond the last
+// column a sheet can have.
+func (s *Sheet) RemoveColumn(index int) error {
+	if index < 0 || index > Excel2006MaxColIndex {
+		return fmt.Errorf("cannot remove column: index out of range: %d", index)
+	}
+	merged := s.MergedCells()
+	err := s.ForEachRow(func(row *Row) error {
+		row.removeCell(index)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if index < s.MaxCol {
+		s.MaxCol--
+	}
+	return s.shift(refShift{sheet: s.Name, cols: true, at: index, n: -1}, merged)
+}
+
+// refShift is a number of rows or columns inserted into, or removed
+// from, a sheet, for moving the references made to them.
+type refShift struct {
+	sheet string // name of the sheet
+	cols  bool   // whether columns, rather than rows, move
+	at    int    // the first row or column inserted or removed
+	n     int    // the number inserted, or minus the number removed
+}
+
+// max returns the last row or column a sheet can have.
+func (rs refShift) max() int {
+	if rs.cols {
+		return Excel2006MaxColIndex
+	}
+	return Excel2006MaxRowIndex
+}
+
+// move returns where the row or column i goes, or false if it is
+// removed.
+func (rs refShift) move(i int) (int, bool) {
+	switch {
+	case i < rs.at:
+		return i, true
+	case rs.n < 0 && i < rs.at-rs.n:
+		return 0, false
+	}
+	return i + rs.n, true
+}
+
+// moveRange returns where the rows or columns from lo to hi go, which
+// grow when some are inserted between them and shrink when some of
+// them are removed, or false if they are all removed.
+func (rs refShift) moveRange(lo, hi int) (int, int, bool) {
+	if rs.n < 0 {
+		end := rs.at - rs.n
+		if lo >= rs.at && hi < end {
+			return 0, 0, false
+		}
+		if lo >= rs.at && lo < end {
+			lo = end
+		}
+		if hi >= rs.at && hi < end {
+			hi = rs.at - 1
+		}
+	}
+	lo, _ = rs.move(lo)
+	hi, _ = rs.move(hi)
+	if lo > rs.max() {
+		return 0, 0, false
+	}
+	if hi > rs.max() {
+		hi = rs.max()
+	}
+	return lo, hi, true
+}
+
+// shift moves the references made to the cells of s by rs, which the
+// rows or columns of s have already been moved by, along with the
+// ranges in merged, which are those s had before.
+func (s *Sheet) shift(rs refShift, merged []MergeRange) error {
+	if err := s.shiftMerges(rs, merged); err != nil {
+		return err
+	}
+	sheets := []*Sheet{s}
+	if s.File != nil {
+		sheets = s.File.Sheets
+		for _, dn := range s.File.DefinedNames {
+			dn.Data = rs.formula(dn.Data, false)
+		}
+	}
+	for _, sheet := range sheets {
+		local := sheet == s
+		err := sheet.ForEachRow(func(row *Row) error {
+			return row.ForEachCell(func(cell *Cell) error {
+				if cell.formula != "" {
+					cell.formula = rs.formula(cell.formula, local)
+				}
+				return nil
+			})
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if rs.cols {
+		s.shiftCols(rs)
+	}
+	validations := s.DataValidations[:0]
+	for _, dv := range s.DataValidations {
+		if dv.Sqref = rs.sqref(dv.Sqref); dv.Sqref != "" {
+			dv.Formula1 = rs.formula(dv.Formula1, true)
+			dv.Formula2 = rs.formula(dv.Formula2, true)
+			validations = append(validations, dv)
+		}
+	}
+	s.DataValidations = validations
+	formats := s.ConditionalFormats[:0]
+	for _, cf := range s.ConditionalFormats {
+		if cf.Ref = rs.sqref(cf.Ref); cf.Ref != "" {
+			for i, formula := range cf.Rule.Formulas {
+				cf.Rule.Formulas[i] = rs.formula(formula, true)
+			}
+			formats = append(formats, cf)
+		}
+	}
+	s.ConditionalFormats = formats
+	if af := s.AutoFilter; af != nil {
+		ref, _ := rs.ref(af.TopLeftCell + cellRangeChar + af.BottomRightCell)
+		if parts := strings.Split(ref, cellRangeChar); len(parts) == 2 {
+			af.TopLeftCell, af.BottomRightCell = parts[0], parts[1]
+		} else {
+			s.AutoFilter = nil
+		}
+	}
+	if err := s.shiftTables(rs); err != nil {
+		return err
+	}
+	s.shiftImages(rs)
+	return nil
+}
+
+// shiftTables moves the ranges of the tables of s by rs. A table whose
+// header row was removed, or that has no rows left below its header, is
+// dropped. The columns removed from a table are removed from its
+// Columns, and those inserted into it are named as Excel names them,
+// "Column1" and so on, in their header cells as well.
+func (s *Sheet) shiftTables(rs refShift) error {
+	tables := s.Tables[:0]
+	for _, t := range s.Tables {
+		minx, top, _, _, err := parseRangeRef(t.Ref)
+		if err != nil {
+			continue
+		}
+		if _, ok := rs.move(top); !ok && !rs.cols {
+			continue
+		}
+		ref, _ := rs.ref(t.Ref)
+		newMinx, miny, maxx, maxy, err := parseRangeRef(ref)
+		if err != nil || miny == maxy {
+			continue
+		}
+		t.Ref = ref
+		if rs.cols {
+			if err := s.shiftTableColumns(&t, rs, minx, miny, maxx-newMinx+1); err != nil {
+				return err
+			}
+		}
+		tables = append(tables, t)
+	}
+	s.Tables = tables
+	return nil
+}
+
+// shiftTableColumns updates the Columns of t, which started at column
+// minx before rs, has its header in row header, and is now width
+// columns wide.
+func (s *Sheet) shiftTableColumns(t *Table, rs refShift, minx, header, width int) error {
+	columns := make([]string, 0, width)
+	for i, name := range t.Columns {
+		if _, ok := rs.move(minx + i); ok {
+			columns = append(columns, name)
+		}
+	}
+	if rs.n > 0 && rs.at > minx && rs.at < minx+len(t.Columns) {
+		at := rs.at - minx
+		inserted := make([]string, 0, rs.n)
+		for i, n := 0, 1; i < rs.n; i++ {
+			name := ""
+			for ; name == "" || tableColumnTaken(columns, name) || tableColumnTaken(inserted, name); n++ {
+				name = fmt.Sprintf("Column%d", n)
+			}
+			cell, err := s.Cell(header, rs.at+i)
+			if err != nil {
+				return err
+			}
+			cell.SetString(name)
+			inserted = append(inserted, name)
+		}
+		columns = append(columns[:at], append(inserted, columns[at:]...)...)
+	}
+	if len(columns) > width {
+		columns = columns[:width]
+	}
+	t.Columns = columns
+	return nil
+}
+
+// tableColumnTaken reports whether name is one of columns, which are
+// the names of the columns of a table, ignoring case.
+func tableColumnTaken(columns []string, name string) bool {
+	for _, column := range columns {
+		if strings.EqualFold(column, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// shiftImages moves the anchors of the images of s by rs, so that each
+// image stays over the same cells, stretching over any inserted among
+// them. A corner in a removed row or column moves to the start of the
+// one after, and an image that was only over removed cells is dropped.
+func (s *Sheet) shiftImages(rs refShift) {
+	images := s.Images[:0]
+	for _, img := range s.Images {
+		a := &img.Anchor
+		from, fromOffset, to, toOffset := &a.FromRow, &a.FromRowOffset, &a.ToRow, &a.ToRowOffset
+		if rs.cols {
+			from, fromOffset, to, toOffset = &a.FromCol, &a.FromColOffset, &a.ToCol, &a.ToColOffset
+		}
+		lo, hi, ok := rs.moveRange(*from, *to)
+		if !ok {
+			continue
+		}
+		if _, ok := rs.move(*from); !ok {
+			*fromOffset = 0
+		}
+		if _, ok := rs.move(*to); !ok {
+			if hi < rs.max() {
+				hi++
+			}
+			*toOffset = 0
+		}
+		*from, *to = lo, hi
+		images = append(images, img)
+	}
+	s.Images = images
+}
+
+// shiftMerges replaces the merges of s with those of merged moved by
+// rs, dropping those that were removed or are left a single cell.
+func (s *Sheet) shiftMerges(rs refShift, merged []MergeRange) error {
+	err := s.ForEachRow(func(row *Row) error {
+		return row.ForEachCell(func(cell *Cell) error {
+			cell.Merge(0, 0)
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	for _, m := range merged {
+		lo, hi := &m.StartRow, &m.EndRow
+		if rs.cols {
+			lo, hi = &m.StartCol, &m.EndCol
+		}
+		var ok bool
+		if *lo, *hi, ok = rs.moveRange(*lo, *hi); !ok {
+			continue
+		}
+		if m.StartCol == m.EndCol && m.StartRow == m.EndRow {
+			continue
+		}
+		cell, err := s.Cell(m.StartRow, m.StartCol)
+		if err != nil {
+			return err
+		}
+		cell.Merge(m.EndCol-m.StartCol, m.EndRow-m.StartRow)
+	}
+	return nil
+}
+
+// shiftCols moves the column definitions of s, with their widths and
+// styles, by rs.
+func (s *Sheet) shiftCols(rs refShift) {
+	if s.Cols == nil {
+		return
+	}
+	cols := &ColStore{}
+	s.Cols.ForEach(func(_ int, col *Col) {
+		if min, max, ok := rs.moveRange(col.Min-1, col.Max-1); ok {
+			cols.Add(col.copyToRange(min+1, max+1))
+		}
+	})
+	s.Cols = cols
+}
+
+// sqref returns the space separated references sqref, as they are given
+// for the ranges of data validations and conditional formats, moved by
+// rs. References to cells that were all removed are dropped.
+func (rs refShift) sqref(sqref string) string {
+	var refs []string
+	for _, ref := range strings.Fields(sqref) {
+		moved, ok := rs.ref(ref)
+		switch {
+		case !ok:
+			refs = append(refs, ref)
+		case moved != refError:
+			refs = append(refs, moved)
+		}
+	}
+	return strings.Join(refs, " ")
+}
+
+// formula returns formula with the references it makes to the sheet of
+// rs moved by rs, as Excel moves them when rows or columns are inserted
+// or removed. References without a sheet name are taken to be to the
+// sheet of rs when local is set. Text in double quotes, names of
+// functions and structured references to tables are left alone.
+func (rs refShift) formula(formula string, local bool) string {
+	var b strings.Builder
+	qualifier, qualified := "", false
+	for i := 0; i < len(formula); {
+		c := formula[i]
+		switch {
+		case c == '[':
+			end := structuredRefEnd(formula, i)
+			b.WriteString(formula[i:end])
+			i = end
+		case c == '"' || c == '\'':
+			// A quote inside is doubled, which reads as two quoted
+			// parts in a row.
+			end := i + 1
+			for {
+				next := strings.IndexByte(formula[end:], c)
+				if next < 0 {
+					end = len(formula)
+					break
+				}
+				end += next + 1
+				if end == len(formula) || formula[end] != c {
+					break
+				}
+				end++
+			}
+			b.WriteString(formula[i:end])
+			if c == '\'' && end < len(formula) && formula[end] == '!' {
+				b.WriteByte('!')
+				qualifier = strings.Replace(formula[i+1:end-1], "''", "'", -1)
+				qualified = true
+				i = end + 1
+				continue
+			}
+			i = end
+		case isFormulaNameByte(c):
+			j := formulaNameEnd(formula, i)
+			if j < len(formula) && formula[j] == '!' {
+				b.WriteString(formula[i : j+1])
+				qualifier, qualified = formula[i:j], true
+				i = j + 1
+				continue
+			}
+			end := j
+			if j+1 < len(formula) && formula[j] == ':' && isFormulaNameByte(formula[j+1]) {
+				end = formulaNameEnd(formula, j+1)
+			}
+			moved, isRef := rs.ref(formula[i:end])
+			if !isRef && end != j {
+				end = j
+				moved, isRef = rs.ref(formula[i:end])
+			}
+			isName := end < len(formula) && (formula[end] == '(' || formula[end] == '[')
+			switch {
+			case isName || !isRef:
+				end = j
+				b.WriteString(formula[i:end])
+			case qualified && strings.EqualFold(qualifier, rs.sheet) || !qualified && local:
+				b.WriteString(moved)
+			default:
+				b.WriteString(formula[i:end])
+			}
+			i = end
+		default:
+			b.WriteByte(c)
+			i++
+		}
+		qualified = false
+	}
+	return b.String()
+}
+
+// formulaNameEnd returns the index just after the name, number or cell
+// reference that starts at formula[start].
+func formulaNameEnd(formula string, start int) int {
+	j := start
+	for j < len(formula) && isFormulaNameByte(formula[j]) {
+		j++
+	}
+	return j
+}
+
+// refPart is a cell, or a whole row or column, at one end of a range.
+type refPart struct {
+	CellRef
+	hasCol, hasRow bool
+}
+
+// parseRefPart returns the refPart s, or false if s isn't one.
+func parseRefPart(s string) (refPart, bool) {
+	fixed := strings.HasPrefix(s, fixedCellRefChar)
+	switch {
+	case cellRefRegexp.MatchString(s):
+		ref, err := ParseCellRef(s)
+		return refPart{CellRef: ref, hasCol: true, hasRow: true}, err == nil
+	case rowRefRegexp.MatchString(s):
+		row, err := strconv.Atoi(strings.TrimPrefix(s, fixedCellRefChar))
+		return refPart{CellRef: CellRef{Row: row - 1, FixedRow: fixed}, hasRow: true}, err == nil && row <= Excel2006MaxRowCount
+	case colRefRegexp.MatchString(s):
+		col := ColLettersToIndex(strings.TrimPrefix(s, fixedCellRefChar))
+		return refPart{CellRef: CellRef{Col: col, FixedCol: fixed}, hasCol: true}, col <= Excel2006MaxColIndex
+	}
+	return refPart{}, false
+}
+
+// String returns the part as it is written in a reference.
+func (p refPart) String() string {
+	fixed := ""
+	switch {
+	case p.hasCol && p.hasRow:
+		return p.CellRef.String()
+	case p.hasRow:
+		if p.FixedRow {
+			fixed = fixedCellRefChar
+		}
+		return fixed + strconv.Itoa(p.Row+1)
+	}
+	if p.FixedCol {
+		fixed = fixedCellRefChar
+	}
+	return fixed + ColIndexToLetters(p.Col)
+}
+
+// ref returns the reference ref, to a cell such as "B2" or a range such
+// as "B2:C5", "2:5" or "B:C", moved by rs, or false if ref isn't such a
+// reference.
+func (rs refShift) ref(ref string) (string, bool) {
+	first, last := ref, ""
+	if i := strings.Index(ref, cellRangeChar); i >= 0 {
+		first, last = ref[:i], ref[i+1:]
+	}
+	from, ok := parseRefPart(first)
+	if !ok {
+		return "", false
+	}
+	index := func(p *refPart) *int {
+		if rs.cols {
+			return &p.Col
+		}
+		return &p.Row
+	}
+	if last == "" {
+		if !from.hasCol || !from.hasRow {
+			return "", false
+		}
+		i, ok := rs.move(*index(&from))
+		if !ok || i > rs.max() {
+			return refError, true
+		}
+		*index(&from) = i
+		return from.String(), true
+	}
+	to, ok := parseRefPart(last)
+	if !ok || to.hasCol != from.hasCol || to.hasRow != from.hasRow {
+		return "", false
+	}
+	if rs.cols && !from.hasCol || !rs.cols && !from.hasRow {
+		return ref, true
+	}
+	lo, hi := index(&from), index(&to)
+	if *lo > *hi {
+		lo, hi = hi, lo
+	}
+	if *lo, *hi, ok = rs.moveRange(*lo, *hi); !ok {
+		return refError, true
+	}
+	return fmt.Sprintf("%s%s%s", from, cellRangeChar, to), true
+}
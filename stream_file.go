@@ -338,7 +338,7 @@ func (sf *StreamFile) Error() error {
 }
 
 func (sf *StreamFile) Flush() {
-	if sf.err != nil {
+	if sf.err == nil {
 		sf.err = sf.zipWriter.Flush()
 	}
 }
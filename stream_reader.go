@@ -0,0 +1,140 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// StreamReader reads the sheets of an XLSX file a row at a time, for
+// files too large to read whole with OpenFile and friends. Create one
+// with OpenReaderStream.
+type StreamReader struct {
+	file        *File
+	workbook    *xlsxWorkbook
+	sheetXMLMap map[string]string
+}
+
+// OpenReaderStream opens the XLSX file r, of size bytes, for reading
+// its sheets row by row with Rows. Only the parts of the file that
+// every sheet depends on, such as the shared strings and the styles,
+// are read up front.
+func OpenReaderStream(r io.ReaderAt, size int64) (*StreamReader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	file := NewFile()
+	workbookFile, sheetXMLMap, err := readFilePartsFromZipReader(zr, file)
+	if err != nil {
+		return nil, err
+	}
+	if workbookFile == nil {
+		return nil, errors.New("xl/workbook.xml not found in input xlsx.")
+	}
+	rc, err := workbookFile.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	workbook := new(xlsxWorkbook)
+	if err := xml.NewDecoder(rc).Decode(workbook); err != nil {
+		return nil, err
+	}
+	file.Date1904 = workbook.WorkbookPr.Date1904
+	return &StreamReader{file: file, workbook: workbook, sheetXMLMap: sheetXMLMap}, nil
+}
+
+// SheetNames returns the names of the sheets of the file, in order.
+func (sr *StreamReader) SheetNames() []string {
+	names := make([]string, 0, len(sr.workbook.Sheets.Sheet))
+	for _, sheet := range sr.workbook.Sheets.Sheet {
+		names = append(names, sheet.Name)
+	}
+	return names
+}
+
+// Rows calls fn with each row of the sheet named sheet as it is read,
+// in order, stopping at the first error fn returns, which Rows then
+// returns. The cells of a row hold their values, formulas and styles
+// just as they do when the whole file is read, so that Row.ReadStruct
+// and the Cell getters can be used on them.
+//
+// Random access isn't available in this mode: each row is let go of
+// once fn returns, and the Sheet of a row holds only the sheet's name
+// and columns, so Sheet.Row, Sheet.Cell and the like don't reach the
+// other rows. Rows that have nothing in them may be left out of the
+// file, in which case fn isn't called for them; Cell.Ref gives the
+// position of a cell. Merges are saved after the rows, so the cells
+// of a row have no HMerge or VMerge.
+func (sr *StreamReader) Rows(sheet string, fn func(r *Row) error) error {
+	var f *zip.File
+	for _, rawsheet := range sr.workbook.Sheets.Sheet {
+		if rawsheet.Name == sheet {
+			f = worksheetFileForSheet(rawsheet, sr.file.worksheets, sr.sheetXMLMap)
+			break
+		}
+	}
+	if f == nil {
+		return fmt.Errorf("Unable to find sheet '%s'", sheet)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	s, err := NewSheet(sheet)
+	if err != nil {
+		return err
+	}
+	s.File = sr.file
+	sharedFormulas := map[int]sharedFormula{}
+	decoder := xml.NewDecoder(rc)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "cols":
+				xCols := new(xlsxCols)
+				if err := decoder.DecodeElement(xCols, &t); err != nil {
+					return err
+				}
+				readColsFromSheet(xCols, sr.file, s)
+			case "row":
+				var rawrow xlsxRow
+				if err := decoder.DecodeElement(&rawrow, &t); err != nil {
+					return err
+				}
+				row, err := sr.readRow(rawrow, s, sharedFormulas)
+				if err != nil {
+					return err
+				}
+				if err := fn(row); err != nil {
+					return err
+				}
+			}
+		case xml.EndElement:
+			// Nothing after the rows is needed.
+			if t.Name.Local == "sheetData" {
+				return nil
+			}
+		}
+	}
+}
+
+// readRow returns the Row of sheet read from rawrow, turning the
+// panics of malformed rows into errors.
+func (sr *StreamReader) readRow(rawrow xlsxRow, sheet *Sheet, sharedFormulas map[int]sharedFormula) (row *Row, err error) {
+	defer recoverPanic(&err)
+	return readRowFromSheet(rawrow, nil, sr.file, sheet, sharedFormulas)
+}
@@ -0,0 +1,134 @@
+package xlsx
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestStreamReader(t *testing.T) {
+	c := qt.New(t)
+
+	type order struct {
+		Item  string    `xlsx:"0"`
+		Count int       `xlsx:"1"`
+		Due   time.Time `xlsx:"2"`
+		Done  bool      `xlsx:"3"`
+	}
+	due := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	makeFile := func() []byte {
+		f := NewFile()
+		sheet, err := f.AddSheet("Orders")
+		c.Assert(err, qt.IsNil)
+		for i, item := range []string{"hops", "malt", "yeast"} {
+			row := sheet.AddRow()
+			row.AddCell().SetString(item)
+			row.AddCell().SetInt(i + 1)
+			row.AddCell().SetDate(due)
+			row.AddCell().SetBool(i == 1)
+			row.AddCell().SetFormula("B" + RowIndexToString(i) + "*2")
+		}
+		row, err := sheet.Row(5)
+		c.Assert(err, qt.IsNil)
+		row.AddCell().SetString("after a gap")
+		sheet.SetColParameters(&Col{Min: 2, Max: 2, Width: 20, Hidden: true, CustomWidth: true})
+		other, err := f.AddSheet("Other")
+		c.Assert(err, qt.IsNil)
+		other.AddRow().AddCell().SetString("x")
+		b, err := f.Bytes()
+		c.Assert(err, qt.IsNil)
+		return b
+	}
+	b := makeFile()
+
+	c.Run("Rows", func(c *qt.C) {
+		sr, err := OpenReaderStream(bytes.NewReader(b), int64(len(b)))
+		c.Assert(err, qt.IsNil)
+		c.Assert(sr.SheetNames(), qt.DeepEquals, []string{"Orders", "Other"})
+
+		var orders []order
+		var rowNums []int
+		err = sr.Rows("Orders", func(r *Row) error {
+			rowNums = append(rowNums, r.GetCell(0).Ref().Row)
+			if r.GetCell(0).Value == "" || r.GetCell(0).Value == "after a gap" {
+				return nil
+			}
+			var o order
+			if err := r.ReadStruct(&o); err != nil {
+				return err
+			}
+			orders = append(orders, o)
+			c.Assert(r.GetCell(4).Formula(), qt.Equals, "B"+RowIndexToString(len(orders)-1)+"*2")
+			c.Assert(r.GetCell(1).Hidden, qt.Equals, true)
+			c.Assert(r.GetCell(0).Hidden, qt.Equals, false)
+			return nil
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(rowNums, qt.DeepEquals, []int{0, 1, 2, 3, 4, 5})
+		c.Assert(orders, qt.DeepEquals, []order{
+			{Item: "hops", Count: 1, Due: due},
+			{Item: "malt", Count: 2, Due: due, Done: true},
+			{Item: "yeast", Count: 3, Due: due},
+		})
+
+		var values []string
+		err = sr.Rows("Other", func(r *Row) error {
+			values = append(values, r.GetCell(0).Value)
+			return nil
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(values, qt.DeepEquals, []string{"x"})
+	})
+
+	c.Run("MatchesOpenBinary", func(c *qt.C) {
+		f, err := OpenBinary(b)
+		c.Assert(err, qt.IsNil)
+		want, err := f.ToSlice()
+		c.Assert(err, qt.IsNil)
+
+		sr, err := OpenReaderStream(bytes.NewReader(b), int64(len(b)))
+		c.Assert(err, qt.IsNil)
+		var got [][]string
+		err = sr.Rows("Orders", func(r *Row) error {
+			var values []string
+			err := r.ForEachCell(func(cell *Cell) error {
+				value, err := cell.FormattedValue()
+				values = append(values, value)
+				return err
+			})
+			got = append(got, values)
+			return err
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(len(got), qt.Equals, len(want[0]))
+		for i := range got {
+			c.Assert(len(got[i]), qt.Equals, len(want[0][i]), qt.Commentf("row %d", i))
+			for j := range got[i] {
+				c.Assert(got[i][j], qt.Equals, want[0][i][j], qt.Commentf("row %d, cell %d", i, j))
+			}
+		}
+	})
+
+	c.Run("Errors", func(c *qt.C) {
+		sr, err := OpenReaderStream(bytes.NewReader(b), int64(len(b)))
+		c.Assert(err, qt.IsNil)
+		stop := errors.New("stop")
+		calls := 0
+		err = sr.Rows("Orders", func(r *Row) error {
+			calls++
+			return stop
+		})
+		c.Assert(err, qt.Equals, stop)
+		c.Assert(calls, qt.Equals, 1)
+
+		err = sr.Rows("Missing", func(r *Row) error { return nil })
+		c.Assert(err, qt.ErrorMatches, "Unable to find sheet 'Missing'")
+
+		_, err = OpenReaderStream(bytes.NewReader([]byte("not a zip")), 9)
+		c.Assert(err, qt.Not(qt.IsNil))
+	})
+}
@@ -0,0 +1,173 @@
+package xlsx
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// StreamWriter writes the rows of a single sheet straight to an
+// io.Writer, without keeping the rows in memory once they are written.
+// Values are converted to cells the same way as by WriteSlice and
+// WriteStruct.
+//
+// Rows are written in the order they are given, and every row must
+// have the same number of cells as the first. As the styles of the file
+// are written before its rows, the number formats of the cells have to
+// be known up front: those the writer gives values itself, such as the
+// default date and time formats, are always available, and any others,
+// such as those given in struct tags, must be passed to
+// NewStreamWriterWithOptions. A row with a cell of any other number
+// format is rejected.
+type StreamWriter struct {
+	sf      *StreamFile
+	options WriterOptions
+	styles  map[string]StreamStyle // keyed by number format
+	fields  map[reflect.Type]structFieldList
+	width   int // number of cells in each row, set by the first row
+}
+
+// NewStreamWriter returns a StreamWriter that writes an XLSX file with
+// a single sheet, named "Sheet1", to w. The file is complete once
+// Close has been called.
+func NewStreamWriter(w io.Writer) (*StreamWriter, error) {
+	return NewStreamWriterWithOptions(w, WriterOptions{})
+}
+
+// NewStreamWriterWithOptions is like NewStreamWriter, but the values
+// are written as set out by 'options', and the cells may also have the
+// number formats 'numFmts', given as in a struct tag, such as
+// "#,##0.00" or "2006-01-02".
+func NewStreamWriterWithOptions(w io.Writer, options WriterOptions, numFmts ...string) (*StreamWriter, error) {
+	styles := map[string]StreamStyle{
+		"":                    StreamStyleDefaultString,
+		"general":             StreamStyleDefaultString,
+		"General":             StreamStyleDefaultString,
+		DefaultDateFormat:     StreamStyleDefaultDate,
+		DefaultDateTimeFormat: StreamStyleDefaultDateTime,
+	}
+	list := []StreamStyle{
+		StreamStyleDefaultString,
+		StreamStyleDefaultDate,
+		StreamStyleDefaultDateTime,
+	}
+	formats := []string{durationFormat, "0", "@"}
+	if options.DecimalFormat != "" {
+		formats = append(formats, options.Locale.NumberFormat(options.DecimalFormat))
+	}
+	for _, format := range numFmts {
+		formats = append(formats, options.Locale.NumberFormat(format), timeFormat(format))
+	}
+
+	sb := NewStreamFileBuilder(w)
+	for _, format := range formats {
+		if _, ok := styles[format]; ok {
+			continue
+		}
+		id, ok := builtInNumFmtInv[format]
+		if !ok {
+			id = sb.AddNewNumberFormat(format)
+		}
+		style := MakeStyle(id, DefaultFont(), DefaultFill(), DefaultAlignment(), DefaultBorder())
+		styles[format] = style
+		list = append(list, style)
+	}
+	if err := sb.AddStreamStyleList(list); err != nil {
+		return nil, err
+	}
+	if err := sb.AddSheetS("Sheet1", nil); err != nil {
+		return nil, err
+	}
+	sf, err := sb.Build()
+	if err != nil {
+		return nil, err
+	}
+	return &StreamWriter{
+		sf:      sf,
+		options: options,
+		styles:  styles,
+		fields:  make(map[reflect.Type]structFieldList),
+	}, nil
+}
+
+// WriteRow writes a row holding 'cells', one value to each cell. A nil
+// value gives a blank cell, as does a value that can't be written.
+func (sw *StreamWriter) WriteRow(cells []interface{}) error {
+	row := &Row{}
+	v := reflect.ValueOf(cells)
+	for i := range cells {
+		sw.options.writeElement(v.Index(i), func() *Cell { return row.GetCell(i) })
+	}
+	return sw.writeRow(row, len(cells))
+}
+
+// WriteStruct writes a row holding the struct, or pointer to struct,
+// 'e', laid out by its struct tags as by WriteStruct.
+func (sw *StreamWriter) WriteStruct(e interface{}) error {
+	v := reflect.ValueOf(e)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return errNilInterface
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return ErrNotStructPointer
+	}
+	fields, ok := sw.fields[v.Type()]
+	if !ok {
+		var err error
+		fields, err = structFields(v.Type(), &sw.options)
+		if err != nil {
+			return err
+		}
+		sw.fields[v.Type()] = fields
+	}
+	row := &Row{}
+	if _, err := fields.write(row, v, -1, nil); err != nil {
+		return err
+	}
+	return sw.writeRow(row, row.cellCount)
+}
+
+// writeRow streams the first n cells of row. A row of the wrong width,
+// or with a number format there is no style for, is rejected here,
+// rather than by the StreamFile, so that it does not leave the
+// StreamFile in an error state.
+func (sw *StreamWriter) writeRow(row *Row, n int) error {
+	if sw.width != 0 && n != sw.width {
+		return WrongNumberOfRowsError
+	}
+	cells := make([]StreamCell, n)
+	for i := range cells {
+		if i >= len(row.cells) || row.cells[i] == nil {
+			cells[i] = NewStringStreamCell("")
+			continue
+		}
+		cell := row.cells[i]
+		style, ok := sw.styles[cell.NumFmt]
+		if !ok {
+			return fmt.Errorf("xlsx: number format %q of column %s was not given to NewStreamWriterWithOptions",
+				cell.NumFmt, ColIndexToLetters(i))
+		}
+		cellType := cell.cellType
+		if cellType == CellTypeInline {
+			cellType = CellTypeString
+		}
+		cells[i] = NewStreamCell(cell.Value, style, cellType)
+	}
+	sw.width = n
+	return sw.sf.WriteS(cells)
+}
+
+// Flush flushes any buffered data to the underlying io.Writer.
+func (sw *StreamWriter) Flush() error {
+	sw.sf.Flush()
+	return sw.sf.Error()
+}
+
+// Close finishes the XLSX file. It does not close the underlying
+// io.Writer.
+func (sw *StreamWriter) Close() error {
+	return sw.sf.Close()
+}
@@ -0,0 +1,85 @@
+package xlsx
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestStreamWriter(t *testing.T) {
+	c := qt.New(t)
+
+	c.Run("WriteRowsAndStructs", func(c *qt.C) {
+		type record struct {
+			Name string    `xlsx:"0"`
+			Age  int       `xlsx:"1"`
+			Born time.Time `xlsx:"2,date"`
+		}
+		born := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+
+		var buf bytes.Buffer
+		sw, err := NewStreamWriter(&buf)
+		c.Assert(err, qt.IsNil)
+		c.Assert(sw.WriteRow([]interface{}{"Name", "Age", nil}), qt.IsNil)
+		c.Assert(sw.WriteStruct(&record{"Eric", 20, born}), qt.IsNil)
+		c.Assert(sw.WriteStruct(record{"Anna", 30, born}), qt.IsNil)
+		c.Assert(sw.Flush(), qt.IsNil)
+		c.Assert(sw.WriteRow([]interface{}{"too", "short"}), qt.Equals, WrongNumberOfRowsError)
+		c.Assert(sw.Close(), qt.IsNil)
+
+		f, err := OpenBinary(buf.Bytes())
+		c.Assert(err, qt.IsNil)
+		rows, err := f.ToSlice()
+		c.Assert(err, qt.IsNil)
+		c.Assert(rows, qt.HasLen, 1)
+		c.Assert(rows[0], qt.DeepEquals, [][]string{
+			{"Name", "Age", ""},
+			{"Eric", "20", "01-02-00"},
+			{"Anna", "30", "01-02-00"},
+		})
+
+		sheet := f.Sheets[0]
+		cell, err := sheet.Cell(1, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.Type(), qt.Equals, CellTypeNumeric)
+	})
+
+	c.Run("WithOptions", func(c *qt.C) {
+		type record struct {
+			Name   string  `xlsx:"0"`
+			Amount float64 `xlsx:"1,'#,##0.00'"`
+			Note   *string `xlsx:"2"`
+		}
+		type other struct {
+			Name  string  `xlsx:"0"`
+			Share float64 `xlsx:"1,0.0%"`
+			Note  string  `xlsx:"2"`
+		}
+
+		var buf bytes.Buffer
+		sw, err := NewStreamWriterWithOptions(&buf, WriterOptions{NullString: "n/a"}, "#,##0.00")
+		c.Assert(err, qt.IsNil)
+		c.Assert(sw.WriteRow([]interface{}{"Name", 1.5, nil}), qt.IsNil)
+		c.Assert(sw.WriteStruct(&record{"Eric", 1234.5, nil}), qt.IsNil)
+		err = sw.WriteStruct(&other{"Anna", 0.5, ""})
+		c.Assert(err, qt.ErrorMatches, `xlsx: number format "0.0%" of column B was not given to NewStreamWriterWithOptions`)
+		c.Assert(sw.Close(), qt.IsNil)
+
+		f, err := OpenBinary(buf.Bytes())
+		c.Assert(err, qt.IsNil)
+		sheet := f.Sheets[0]
+		c.Assert(sheet.MaxRow, qt.Equals, 2)
+		cell, err := sheet.Cell(0, 2)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.Value, qt.Equals, "n/a")
+		cell, err = sheet.Cell(1, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.NumFmt, qt.Equals, "#,##0.00")
+		c.Assert(cell.Value, qt.Equals, "1234.5")
+		cell, err = sheet.Cell(1, 2)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cell.Value, qt.Equals, "n/a")
+	})
+}
@@ -0,0 +1,652 @@
+package xlsx
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+)
+
+// StructWriter writes values of a single struct type to rows. It
+// works out which field goes to which cell once, when it is created,
+// so it is much cheaper than WriteStruct when writing many rows.
+// The tags it understands are the same as those of WriteStruct.
+type StructWriter struct {
+	// OnCell, when set, is called for each cell Write writes, right
+	// after its value has been set, with the field the value came from
+	// and the index of the cell's column. It may be used to style
+	// cells depending on their value. The cells of a slice field are
+	// each passed with the slice field.
+	OnCell func(field reflect.StructField, col int, cell *Cell)
+
+	typ    reflect.Type
+	fields structFieldList
+}
+
+// NewStructWriter returns a StructWriter for the struct type of
+// 'prototype', which may be a struct or a pointer to one. All the
+// tags of the type are checked here, so an invalid tag is reported by
+// NewStructWriter rather than by Write.
+func NewStructWriter(prototype interface{}) (*StructWriter, error) {
+	return NewStructWriterWithOptions(prototype, WriterOptions{})
+}
+
+// NewStructWriterWithOptions is like NewStructWriter, but the values
+// are written as set out by 'options'.
+func NewStructWriterWithOptions(prototype interface{}, options WriterOptions) (*StructWriter, error) {
+	t := reflect.TypeOf(prototype)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, ErrNotStructPointer
+	}
+	fields, err := structFields(t, &options)
+	if err != nil {
+		return nil, err
+	}
+	return &StructWriter{typ: t, fields: fields}, nil
+}
+
+// ValidateStruct checks the xlsx tags of the struct type of 'e', which
+// may be a struct or a pointer to one, without writing anything. It
+// returns the error WriteStruct would return for the type, such as an
+// ErrInvalidTag wrapped together with the name of the field, or nil if
+// the type can be written.
+func ValidateStruct(e interface{}) error {
+	_, err := NewStructWriter(e)
+	return err
+}
+
+// Write writes 'e' to row r. Accepts a value of the StructWriter's
+// struct type or a pointer to one, and returns the number of columns
+// written.
+func (sw *StructWriter) Write(r *Row, e interface{}) (int, error) {
+	v := reflect.ValueOf(e)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return 0, errNilInterface
+		}
+		v = v.Elem()
+	}
+	if v.Type() != sw.typ {
+		return 0, fmt.Errorf("struct writer for %s cannot write %s", sw.typ, v.Type())
+	}
+	return sw.fields.write(r, v, -1, sw.OnCell)
+}
+
+// WriteHeader writes the header of each field of the StructWriter's
+// struct type to row r, in the same columns Write puts the values, and
+// returns the number of headers written. See WriteStructHeader.
+func (sw *StructWriter) WriteHeader(r *Row) int {
+	return sw.fields.writeHeader(r)
+}
+
+// fieldWriter writes the field value v to row r, starting at column
+// col, and returns the columns written, in increasing order.
+type fieldWriter func(r *Row, col int, v reflect.Value) ([]int, error)
+
+// structField is a field of a struct type that is written to a cell.
+type structField struct {
+	index  []int // index sequence for reflect.Value.FieldByIndex
+	field  reflect.StructField
+	col    int
+	header string
+	write  fieldWriter
+}
+
+// structFieldList holds the written fields of a struct type, in field
+// order, with the fields of nested structs flattened into it.
+type structFieldList []structField
+
+// structFields works out how the fields of the struct type t are
+// written with the options o.
+func structFields(t reflect.Type, o *WriterOptions) (structFieldList, error) {
+	var fields structFieldList
+	if err := fields.add(t, nil, o, map[reflect.Type]bool{}); err != nil {
+		return nil, err
+	}
+	if o.AutoColumns {
+		fields.assignColumns()
+	}
+	if o.StrictPositions {
+		if err := fields.checkPositions(); err != nil {
+			return nil, err
+		}
+	}
+	return fields, nil
+}
+
+// checkPositions returns an error naming the first two fields that are
+// written to the same column.
+func (l structFieldList) checkPositions() error {
+	names := make(map[int]string, len(l))
+	for _, f := range l {
+		if name, ok := names[f.col]; ok {
+			return fmt.Errorf("xlsx: fields %s and %s are both written to column %s: %w",
+				name, f.field.Name, ColIndexToLetters(f.col), ErrDuplicatePosition)
+		}
+		names[f.col] = f.field.Name
+	}
+	return nil
+}
+
+// assignColumns gives each field without a position of its own the
+// column set out by AutoColumns.
+func (l structFieldList) assignColumns() {
+	taken := make(map[int]bool)
+	for _, f := range l {
+		if f.col >= 0 {
+			taken[f.col] = true
+		}
+	}
+	next := 0
+	for i := range l {
+		if l[i].col >= 0 {
+			continue
+		}
+		for taken[next] {
+			next++
+		}
+		l[i].col = next
+		next++
+	}
+}
+
+// add appends the fields of the struct type t, whose index sequence
+// within the outermost struct begins with 'index'. 'seen' holds the
+// struct types currently being added and is used to break out of
+// self-referential types.
+func (l *structFieldList) add(t reflect.Type, index []int, o *WriterOptions, seen map[reflect.Type]bool) error {
+	seen[t] = true
+	defer delete(seen, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("xlsx")
+		if tag == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+		nested := field.Type
+		if nested.Kind() == reflect.Ptr {
+			nested = nested.Elem()
+		}
+		// Unexported fields are skipped, tagged or not, except for
+		// embedded structs: their promoted fields are reachable even
+		// when the struct's type is unexported.
+		if field.PkgPath != "" && !(field.Anonymous && isNestedStruct(nested)) {
+			continue
+		}
+		ft, tagErr := parseTag(tag)
+		if isNestedStruct(nested) && !(tagErr == nil && ft.json) {
+			if seen[nested] {
+				continue
+			}
+			if err := l.add(nested, fieldIndex, o, seen); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if tagErr != nil {
+			return invalidTagError(field, tagErr)
+		}
+		col, err := o.column(ft)
+		if err != nil {
+			return invalidTagError(field, err)
+		}
+		header := ft.header
+		if header == "" {
+			header = field.Name
+		}
+		write := newFieldWriter(field.Type, ft, o)
+		if o.StrictNumbers && !ft.text && !ft.json {
+			write = strictNumberWriter(field, write)
+		}
+		*l = append(*l, structField{
+			index:  fieldIndex,
+			field:  field,
+			col:    col,
+			header: header,
+			write:  write,
+		})
+	}
+	return nil
+}
+
+// write writes the fields of the struct v to row r, stopping after
+// the first 'cols' fields of the outermost struct if 'cols' is > 0.
+// Fields inside a nil nested struct pointer are skipped. If onCell is
+// not nil it is called for each cell written. Returns the number of
+// columns written.
+func (l structFieldList) write(r *Row, v reflect.Value, cols int, onCell func(reflect.StructField, int, *Cell)) (int, error) {
+	var k int
+	for _, f := range l {
+		if cols > 0 && f.index[0] >= cols {
+			break
+		}
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok {
+			continue
+		}
+		written, err := f.write(r, f.col, fv)
+		k += len(written)
+		if onCell != nil {
+			for _, col := range written {
+				onCell(f.field, col, r.GetCell(col))
+			}
+		}
+		if err != nil {
+			return k, err
+		}
+	}
+	return k, nil
+}
+
+// writeHeader writes the header of each field to row r and returns the
+// number of headers written.
+func (l structFieldList) writeHeader(r *Row) int {
+	for _, f := range l {
+		r.GetCell(f.col).SetString(f.header)
+	}
+	return len(l)
+}
+
+// fieldByIndex is like reflect.Value.FieldByIndex, but reports false
+// instead of panicking when it meets a nil pointer on the way.
+func fieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// newFieldWriter selects how a field of type t with the tag 'ft' is
+// written with the options o.
+func newFieldWriter(t reflect.Type, ft fieldTag, o *WriterOptions) fieldWriter {
+	var w fieldWriter
+	switch {
+	case ft.json:
+		w = o.writeJSON
+	case ft.unix != "" && isTimeType(t):
+		w = o.unixWriter(ft.unix == "unixmilli", ft.format)
+	default:
+		w = newValueWriter(t, ft.format, o)
+	}
+	switch {
+	case ft.number:
+		w = o.numberWriter(w, ft.format)
+	case ft.text:
+		w = textWriter(w)
+	}
+	if ft.hyperlink {
+		w = hyperlinkWriter(w)
+	}
+	switch value := w; {
+	case ft.hasDef:
+		w = func(r *Row, col int, v reflect.Value) ([]int, error) {
+			if isEmptyValue(v) {
+				o.writeString(r.GetCell(col), ft.def)
+				return []int{col}, nil
+			}
+			return value(r, col, v)
+		}
+	case ft.omitEmpty:
+		w = func(r *Row, col int, v reflect.Value) ([]int, error) {
+			if isEmptyValue(v) {
+				return nil, nil
+			}
+			return value(r, col, v)
+		}
+	}
+	if ft.trim {
+		// Trim first, so that a value of only spaces counts as empty.
+		trimmed := w
+		w = func(r *Row, col int, v reflect.Value) ([]int, error) {
+			return trimmed(r, col, trimSpace(v))
+		}
+	}
+	return w
+}
+
+// strictNumberWriter returns a writer that writes with w, unless the
+// value of field is a number that a cell can't hold exactly, for which
+// it returns an error naming the field.
+func strictNumberWriter(field reflect.StructField, w fieldWriter) fieldWriter {
+	return func(r *Row, col int, v reflect.Value) ([]int, error) {
+		if err := checkExactNumber(v); err != nil {
+			return nil, fmt.Errorf("xlsx: field %s: %w", field.Name, err)
+		}
+		return w(r, col, v)
+	}
+}
+
+// numberWriter returns a writer that writes a string value holding a
+// decimal number as a number with the number format 'format', and any
+// other value with w.
+func (o *WriterOptions) numberWriter(w fieldWriter, format string) fieldWriter {
+	format = o.Locale.NumberFormat(format)
+	return func(r *Row, col int, v reflect.Value) ([]int, error) {
+		s, ok := stringValue(v)
+		if !ok {
+			return w(r, col, v)
+		}
+		f, ok := parseDecimal(s)
+		if !ok {
+			return w(r, col, v)
+		}
+		cell := r.GetCell(col)
+		cell.SetFloat(f)
+		if format != "" {
+			cell.SetFormat(format)
+		}
+		return []int{col}, nil
+	}
+}
+
+// unixWriter returns a writer that writes a time.Time or nulls.Time
+// value as the number of seconds, or if milli is set milliseconds,
+// since the Unix epoch, with the number format 'format', or "0" if
+// that is empty so that large timestamps aren't shown in scientific
+// notation. NULL and zero times are written as by writeTime.
+func (o *WriterOptions) unixWriter(milli bool, format string) fieldWriter {
+	if format == "" {
+		format = "0"
+	}
+	format = o.Locale.NumberFormat(format)
+	return func(r *Row, col int, v reflect.Value) ([]int, error) {
+		cell := r.GetCell(col)
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				o.writeNull(cell)
+				return []int{col}, nil
+			}
+			v = v.Elem()
+		}
+		var t time.Time
+		switch value := v.Interface().(type) {
+		case time.Time:
+			t = value
+		case nulls.Time:
+			if !value.Valid {
+				o.writeNull(cell)
+				return []int{col}, nil
+			}
+			t = value.Time
+		}
+		if t.IsZero() && !o.ZeroTimeAsDate {
+			o.writeNull(cell)
+			return []int{col}, nil
+		}
+		if milli {
+			cell.SetInt64(t.Unix()*1000 + int64(t.Nanosecond())/int64(time.Millisecond))
+		} else {
+			cell.SetInt64(t.Unix())
+		}
+		cell.SetFormat(format)
+		return []int{col}, nil
+	}
+}
+
+// decimalRegexp matches a plain decimal number, such as "-1.5" or
+// "2e10", leaving out the digit separators, hexadecimal numbers and
+// special values that strconv.ParseFloat also accepts.
+var decimalRegexp = regexp.MustCompile(`^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]+)?$`)
+
+// parseDecimal returns the number s holds, reporting false unless s is
+// a plain decimal number that a float64 holds exactly, so that text
+// such as "1_000" or "12345678901234567890" is kept as it is.
+func parseDecimal(s string) (float64, bool) {
+	if !decimalRegexp.MatchString(s) {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || !isExactFloat(s, f) {
+		return 0, false
+	}
+	return f, true
+}
+
+// isExactFloat reports whether f, parsed from the decimal number s,
+// keeps all the digits of s, so that "0.10" is exact where a number
+// with more digits than a float64 holds is not.
+func isExactFloat(s string, f float64) bool {
+	want, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return false
+	}
+	got, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	return want.Cmp(got) == 0
+}
+
+// stringValue returns the text of v, if v is a string, a valid string
+// null type or a pointer to one.
+func stringValue(v reflect.Value) (string, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), true
+	case reflect.Ptr:
+		if !v.IsNil() {
+			return stringValue(v.Elem())
+		}
+	case reflect.Struct:
+		if t := v.Type(); t == reflect.TypeOf(sql.NullString{}) || t == reflect.TypeOf(nulls.String{}) {
+			return v.FieldByName("String").String(), v.FieldByName("Valid").Bool()
+		}
+	}
+	return "", false
+}
+
+// textWriter returns a writer that writes with w, and then turns each
+// numeric cell written into a text cell holding the value as it is
+// shown, with the text number format.
+func textWriter(w fieldWriter) fieldWriter {
+	return func(r *Row, col int, v reflect.Value) ([]int, error) {
+		written, err := w(r, col, v)
+		for _, col := range written {
+			cell := r.GetCell(col)
+			if cell.cellType != CellTypeNumeric || cell.formula != "" {
+				continue
+			}
+			text, ferr := cell.FormattedValue()
+			if ferr != nil {
+				text = cell.Value
+			}
+			cell.SetString(text)
+			cell.SetFormat("@")
+		}
+		return written, err
+	}
+}
+
+// hyperlinkWriter returns a writer that writes a string value that
+// isn't empty as a hyperlink to the URL it holds, showing the URL, and
+// any other value with w.
+func hyperlinkWriter(w fieldWriter) fieldWriter {
+	return func(r *Row, col int, v reflect.Value) ([]int, error) {
+		s, ok := stringValue(v)
+		if !ok || s == "" {
+			return w(r, col, v)
+		}
+		r.GetCell(col).SetHyperlink(s, "", "")
+		return []int{col}, nil
+	}
+}
+
+// trimSpace returns a copy of v with the leading and trailing white
+// space removed from its text, if v is a string, a string null type or
+// a pointer to one. Any other value is returned as it is.
+func trimSpace(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(strings.TrimSpace(v.String())).Convert(v.Type())
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		return trimSpace(v.Elem())
+	case reflect.Struct:
+		if t := v.Type(); t == reflect.TypeOf(sql.NullString{}) || t == reflect.TypeOf(nulls.String{}) {
+			c := reflect.New(t).Elem()
+			c.Set(v)
+			text := c.FieldByName("String")
+			text.SetString(strings.TrimSpace(text.String()))
+			return c
+		}
+	}
+	return v
+}
+
+// isTimeType reports whether t, or the type t points to, is time.Time
+// or nulls.Time, whose values take a date format from their tag.
+func isTimeType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t == timeType || t == reflect.TypeOf(nulls.Time{})
+}
+
+// isNumericType reports whether values of type t, or of the type t
+// points to, are written as numbers: integers, floats, decimals and
+// the numeric null types.
+func isNumericType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case reflect.TypeOf(sql.NullInt64{}), reflect.TypeOf(sql.NullFloat64{}),
+		reflect.TypeOf(nulls.Int{}), reflect.TypeOf(nulls.Int64{}), reflect.TypeOf(nulls.Float64{}):
+		return true
+	}
+	if t.Implements(decimalType) || reflect.PtrTo(t).Implements(decimalType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// writeJSON writes the value v to the cell at column col of row r as
+// JSON text. A value that marshals to null is written as a NULL value.
+// If marshalling fails the cell is left empty and the error returned.
+func (o *WriterOptions) writeJSON(r *Row, col int, v reflect.Value) ([]int, error) {
+	cell := r.GetCell(col)
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		cell.SetString("")
+		return []int{col}, err
+	}
+	if string(b) == "null" {
+		o.writeNull(cell)
+		return []int{col}, nil
+	}
+	o.writeString(cell, string(b))
+	return []int{col}, nil
+}
+
+// newValueWriter selects how a value of type t with the tag format
+// 'format' is written. Plain strings, numbers and booleans get a
+// writer specific to their kind, and the elements of a slice are
+// written to consecutive cells. Everything else, including types with
+// a registered CellEncoder, goes through writeValue.
+func newValueWriter(t reflect.Type, format string, o *WriterOptions) fieldWriter {
+	if lookupCellEncoder(t) != nil {
+		return o.writeFieldValue
+	}
+	if format != "" && isTimeType(t) {
+		format := timeFormat(format)
+		return func(r *Row, col int, v reflect.Value) ([]int, error) {
+			if v.Kind() == reflect.Ptr {
+				if v.IsNil() {
+					o.writeNull(r.GetCell(col))
+					return []int{col}, nil
+				}
+				v = v.Elem()
+			}
+			var t time.Time
+			switch value := v.Interface().(type) {
+			case time.Time:
+				t = value
+			case nulls.Time:
+				if !value.Valid {
+					o.writeNull(r.GetCell(col))
+					return []int{col}, nil
+				}
+				t = value.Time
+			}
+			if t.IsZero() && !o.ZeroTimeAsDate {
+				o.writeNull(r.GetCell(col))
+				return []int{col}, nil
+			}
+			r.GetCell(col).SetDateWithFormat(t, format)
+			return []int{col}, nil
+		}
+	}
+	if format != "" && isNumericType(t) {
+		format := o.Locale.NumberFormat(format)
+		w := newValueWriter(t, "", o)
+		return func(r *Row, col int, v reflect.Value) ([]int, error) {
+			written, err := w(r, col, v)
+			// A NULL value, or a number too large to be exact, is
+			// written as text and keeps the general format.
+			if len(written) > 0 {
+				if cell := r.GetCell(col); cell.cellType == CellTypeNumeric {
+					cell.SetFormat(format)
+				}
+			}
+			return written, err
+		}
+	}
+	if w := o.kindWriter(t.Kind()); w != nil && !implementsValueInterface(t) {
+		return func(r *Row, col int, v reflect.Value) ([]int, error) {
+			w(r.GetCell(col), v)
+			return []int{col}, nil
+		}
+	}
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && !isTextSlice(t) && !implementsValueInterface(t) {
+		return func(r *Row, col int, v reflect.Value) ([]int, error) {
+			var cols []int
+			for i := 0; i < v.Len(); i++ {
+				pos := col + i
+				written, err := o.writeValue(v.Index(i), func() *Cell { return r.GetCell(pos) })
+				if written {
+					cols = append(cols, pos)
+				}
+				if err != nil {
+					return cols, err
+				}
+			}
+			return cols, nil
+		}
+	}
+	return o.writeFieldValue
+}
+
+// writeFieldValue writes the field value v to the cell at column col of
+// row r with writeValue.
+func (o *WriterOptions) writeFieldValue(r *Row, col int, v reflect.Value) ([]int, error) {
+	written, err := o.writeValue(v, func() *Cell { return r.GetCell(col) })
+	if written {
+		return []int{col}, err
+	}
+	return nil, err
+}
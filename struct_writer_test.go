@@ -0,0 +1,237 @@
+package xlsx
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+type structWriterTest struct {
+	Name    string    `xlsx:"0"`
+	Age     int       `xlsx:"1"`
+	GPA     float64   `xlsx:"2"`
+	Born    time.Time `xlsx:"3,date"`
+	Address struct {
+		City string `xlsx:"4"`
+	}
+	Ignored string `xlsx:"-"`
+}
+
+func TestStructWriter(t *testing.T) {
+	c := qt.New(t)
+
+	csRunO(c, "WriteMatchesWriteStruct", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		val := structWriterTest{
+			Name: "Eric",
+			Age:  20,
+			GPA:  3.94,
+			Born: time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC),
+		}
+		val.Address.City = "Springfield"
+
+		sw, err := NewStructWriter(structWriterTest{})
+		c.Assert(err, qt.IsNil)
+
+		row := sheet.AddRow()
+		cnt, err := sw.Write(row, &val)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 5)
+
+		expected := sheet.AddRow()
+		expectedCnt, err := expected.WriteStruct(&val, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, expectedCnt)
+
+		for i := 0; i < 5; i++ {
+			c.Assert(row.GetCell(i).Value, qt.Equals, expected.GetCell(i).Value)
+			c.Assert(row.GetCell(i).NumFmt, qt.Equals, expected.GetCell(i).NumFmt)
+		}
+	})
+
+	c.Run("InvalidPrototype", func(c *qt.C) {
+		type bad struct {
+			Value string `xlsx:"not a column"`
+		}
+		_, err := NewStructWriter(&bad{})
+		c.Assert(errors.Is(err, ErrInvalidTag), qt.Equals, true)
+
+		_, err = NewStructWriter(42)
+		c.Assert(err, qt.Equals, ErrNotStructPointer)
+	})
+
+	c.Run("StrictPositions", func(c *qt.C) {
+		type dup struct {
+			First  string `xlsx:"0"`
+			Second string `xlsx:"1"`
+			Third  string `xlsx:"B"`
+		}
+		_, err := NewStructWriter(dup{})
+		c.Assert(err, qt.IsNil)
+
+		_, err = NewStructWriterWithOptions(dup{}, WriterOptions{StrictPositions: true})
+		c.Assert(errors.Is(err, ErrDuplicatePosition), qt.Equals, true)
+		c.Assert(err, qt.ErrorMatches, "xlsx: fields Second and Third are both written to column B: .*")
+	})
+
+	c.Run("OneBasedColumns", func(c *qt.C) {
+		type oneBased struct {
+			Name string `xlsx:"1,,Full Name"`
+			Age  int    `xlsx:"2"`
+			Code string `xlsx:"D"`
+		}
+		f := NewFile()
+		sheet, _ := f.AddSheet("Test1")
+		sw, err := NewStructWriterWithOptions(oneBased{}, WriterOptions{OneBasedColumns: true})
+		c.Assert(err, qt.IsNil)
+		header, data := sheet.AddRow(), sheet.AddRow()
+		c.Assert(sw.WriteHeader(header), qt.Equals, 3)
+		_, err = sw.Write(data, oneBased{Name: "Eric", Age: 20, Code: "X"})
+		c.Assert(err, qt.IsNil)
+
+		c.Assert(header.GetCell(0).Value, qt.Equals, "Full Name")
+		c.Assert(header.GetCell(1).Value, qt.Equals, "Age")
+		c.Assert(header.GetCell(3).Value, qt.Equals, "Code")
+		c.Assert(data.GetCell(0).Value, qt.Equals, "Eric")
+		c.Assert(data.GetCell(1).Value, qt.Equals, "20")
+		c.Assert(data.GetCell(2).Value, qt.Equals, "")
+		c.Assert(data.GetCell(3).Value, qt.Equals, "X")
+
+		type zero struct {
+			Name string `xlsx:"0"`
+		}
+		_, err = NewStructWriterWithOptions(zero{}, WriterOptions{OneBasedColumns: true})
+		c.Assert(errors.Is(err, ErrInvalidTag), qt.Equals, true)
+	})
+
+	c.Run("AutoColumns", func(c *qt.C) {
+		type audit struct {
+			By string
+		}
+		type dto struct {
+			A    string `xlsx:"1"`
+			B    string
+			C    string `xlsx:",omitempty"`
+			D    string `xlsx:"3"`
+			E    audit
+			skip string
+		}
+		f := NewFile()
+		sheet, _ := f.AddSheet("Test1")
+		sw, err := NewStructWriterWithOptions(dto{}, WriterOptions{AutoColumns: true, StrictPositions: true})
+		c.Assert(err, qt.IsNil)
+		header, data := sheet.AddRow(), sheet.AddRow()
+		c.Assert(sw.WriteHeader(header), qt.Equals, 5)
+		_, err = sw.Write(data, dto{"a", "b", "c", "d", audit{"e"}, "x"})
+		c.Assert(err, qt.IsNil)
+
+		var headers, values []string
+		for i := 0; i < 5; i++ {
+			headers = append(headers, header.GetCell(i).Value)
+			values = append(values, data.GetCell(i).Value)
+		}
+		c.Assert(headers, qt.DeepEquals, []string{"B", "A", "C", "D", "By"})
+		c.Assert(values, qt.DeepEquals, []string{"b", "a", "c", "d", "e"})
+
+		_, err = NewStructWriter(dto{})
+		c.Assert(errors.Is(err, ErrInvalidTag), qt.Equals, true)
+		c.Assert(err, qt.ErrorMatches, "xlsx: invalid tag on field B: .*")
+	})
+
+	c.Run("OnCell", func(c *qt.C) {
+		type account struct {
+			Name    string        `xlsx:"0"`
+			Balance float64       `xlsx:"1"`
+			History []float64     `xlsx:"2"`
+			Note    string        `xlsx:"5,omitempty"`
+			Extra   []interface{} `xlsx:"6"`
+		}
+		f := NewFile()
+		sheet, _ := f.AddSheet("Test1")
+		sw, err := NewStructWriter(account{})
+		c.Assert(err, qt.IsNil)
+		red := NewStyle()
+		red.Font.Color = RGB_Dark_Red
+		var visited []string
+		sw.OnCell = func(field reflect.StructField, col int, cell *Cell) {
+			visited = append(visited, fmt.Sprintf("%s:%d", field.Name, col))
+			if f, err := cell.Float(); err == nil && f < 0 {
+				cell.SetStyle(red)
+			}
+		}
+		row := sheet.AddRow()
+		// A channel can't be written, so that its cell is skipped.
+		extra := []interface{}{make(chan int), -3}
+		_, err = sw.Write(row, account{Name: "Eric", Balance: -5, History: []float64{1, -2}, Extra: extra})
+		c.Assert(err, qt.IsNil)
+		c.Assert(visited, qt.DeepEquals, []string{"Name:0", "Balance:1", "History:2", "History:3", "Extra:7"})
+		c.Assert(row.cells[6], qt.IsNil)
+		c.Assert(row.GetCell(7).GetStyle().Font.Color, qt.Equals, RGB_Dark_Red)
+		c.Assert(row.GetCell(0).GetStyle().Font.Color, qt.Not(qt.Equals), RGB_Dark_Red)
+		c.Assert(row.GetCell(1).GetStyle().Font.Color, qt.Equals, RGB_Dark_Red)
+		c.Assert(row.GetCell(2).GetStyle().Font.Color, qt.Not(qt.Equals), RGB_Dark_Red)
+		c.Assert(row.GetCell(3).GetStyle().Font.Color, qt.Equals, RGB_Dark_Red)
+	})
+
+	c.Run("Validate", func(c *qt.C) {
+		type good struct {
+			Name  string `xlsx:"0,omitempty"`
+			Price int    `xlsx:"B,'#,##0',Price"`
+		}
+		c.Assert(ValidateStruct(good{}), qt.IsNil)
+		c.Assert(ValidateStruct(&good{}), qt.IsNil)
+
+		type bad struct {
+			Name  string `xlsx:"0"`
+			Price int    `xlsx:"1,'#,##0"`
+		}
+		err := ValidateStruct(&bad{})
+		c.Assert(err, qt.ErrorMatches, "xlsx: invalid tag on field Price: .*")
+		c.Assert(errors.Is(err, ErrInvalidTag), qt.Equals, true)
+
+		f := NewFile()
+		sheet, _ := f.AddSheet("Test1")
+		_, writeErr := sheet.AddRow().WriteStruct(&bad{}, -1)
+		c.Assert(writeErr.Error(), qt.Equals, err.Error())
+
+		c.Assert(ValidateStruct(3), qt.Equals, ErrNotStructPointer)
+	})
+
+	c.Run("WrongType", func(c *qt.C) {
+		f := NewFile()
+		sheet, _ := f.AddSheet("Test1")
+		sw, err := NewStructWriter(&structWriterTest{})
+		c.Assert(err, qt.IsNil)
+		_, err = sw.Write(sheet.AddRow(), &struct{}{})
+		c.Assert(err, qt.ErrorMatches, "struct writer for .* cannot write .*")
+	})
+}
+
+func BenchmarkWriteStruct(b *testing.B) {
+	f := NewFile()
+	sheet, _ := f.AddSheet("Bench")
+	val := structWriterTest{Name: "Eric", Age: 20, GPA: 3.94, Born: time.Now()}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sheet.AddRow().WriteStruct(&val, -1)
+	}
+}
+
+func BenchmarkStructWriter(b *testing.B) {
+	f := NewFile()
+	sheet, _ := f.AddSheet("Bench")
+	val := structWriterTest{Name: "Eric", Age: 20, GPA: 3.94, Born: time.Now()}
+	sw, err := NewStructWriter(val)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sw.Write(sheet.AddRow(), &val)
+	}
+}
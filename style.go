@@ -37,15 +37,18 @@ type Style struct {
 	ApplyAlignment  bool
 	Alignment       Alignment
 	NamedStyleIndex *int
+	ApplyProtection bool
+	Protection      Protection
 }
 
 // Return a new Style structure initialised with the default values.
 func NewStyle() *Style {
 	return &Style{
-		Alignment: *DefaultAlignment(),
-		Border:    *DefaultBorder(),
-		Fill:      *DefaultFill(),
-		Font:      *DefaultFont(),
+		Alignment:  *DefaultAlignment(),
+		Border:     *DefaultBorder(),
+		Fill:       *DefaultFill(),
+		Font:       *DefaultFont(),
+		Protection: *DefaultProtection(),
 	}
 }
 
@@ -109,6 +112,11 @@ func (style *Style) makeXLSXStyleElements() (xFont xlsxFont, xFill xlsxFill, xBo
 	if style.NamedStyleIndex != nil {
 		xCellXf.XfId = style.NamedStyleIndex
 	}
+	if style.ApplyProtection {
+		locked := style.Protection.Locked
+		xCellXf.ApplyProtection = true
+		xCellXf.Protection = &xlsxProtection{Locked: &locked, Hidden: style.Protection.Hidden}
+	}
 	return
 }
 
@@ -206,3 +214,17 @@ func DefaultAlignment() *Alignment {
 		Vertical:   "bottom",
 	}
 }
+
+// Protection is how a cell is protected once its sheet is protected,
+// see Sheet.Protect. Locked cells can't be changed, and Hidden cells
+// don't show their formulas.
+type Protection struct {
+	Locked bool
+	Hidden bool
+}
+
+// DefaultProtection returns the protection of cells that haven't been
+// given one, which are locked but not hidden.
+func DefaultProtection() *Protection {
+	return &Protection{Locked: true}
+}
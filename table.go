@@ -0,0 +1,182 @@
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// defaultTableStyle is the style of tables that aren't given one, as
+// it is in Excel.
+const defaultTableStyle = "TableStyleMedium2"
+
+// TableOptions are the options of a table, see AddTable. StyleName is
+// the name of one of Excel's table styles, such as "TableStyleLight9",
+// and is "TableStyleMedium2" when empty; the Show fields pick which
+// parts of the table the style picks out.
+type TableOptions struct {
+	StyleName         string
+	ShowFirstColumn   bool
+	ShowLastColumn    bool
+	ShowRowStripes    bool
+	ShowColumnStripes bool
+}
+
+// Table is an Excel table over a range of cells of a sheet, see
+// AddTable. Columns are the names of its columns, which are the values
+// of its header row.
+type Table struct {
+	Name    string
+	Ref     string
+	Columns []string
+	Options TableOptions
+}
+
+// AddTable makes the range of cells rangeRef, such as "A1:C10", into
+// a table named name, with filter buttons on its header and the style
+// of opts. The first row of the range is the header, which must already
+// have been written: its cells name the columns of the table, which
+// formulas can then refer to with structured references such as
+// "SUM(Orders[Count])".
+//
+// Table names follow the same rules as defined names, see
+// File.SetDefinedName, and must differ from the names of the other
+// tables and defined names of the file. An error is returned, and no
+// table added, if the name isn't such a name, the range has no rows
+// below the header or overlaps another table or the sheet's auto
+// filter, or a header cell is empty or repeats another.
+func (s *Sheet) AddTable(name, rangeRef string, opts TableOptions) error {
+	if err := checkDefinedName("table name", name); err != nil {
+		return err
+	}
+	if s.tableNameTaken(name) {
+		return fmt.Errorf("table name %q is already used", name)
+	}
+	minx, miny, maxx, maxy, err := parseRangeRef(rangeRef)
+	if err != nil {
+		return fmt.Errorf("table: %w", err)
+	}
+	if maxy == miny {
+		return fmt.Errorf("table: range %q has no rows below its header", rangeRef)
+	}
+	overlaps := func(ref string) bool {
+		x1, y1, x2, y2, err := parseRangeRef(ref)
+		return err == nil && x1 <= maxx && minx <= x2 && y1 <= maxy && miny <= y2
+	}
+	for _, t := range s.Tables {
+		if overlaps(t.Ref) {
+			return fmt.Errorf("table: range %q overlaps table %q", rangeRef, t.Name)
+		}
+	}
+	if s.AutoFilter != nil && overlaps(s.AutoFilter.TopLeftCell+cellRangeChar+s.AutoFilter.BottomRightCell) {
+		return fmt.Errorf("table: range %q overlaps the sheet's auto filter", rangeRef)
+	}
+
+	columns := make([]string, 0, maxx-minx+1)
+	for x := minx; x <= maxx; x++ {
+		cell, err := s.Cell(miny, x)
+		if err != nil {
+			return err
+		}
+		header := cell.Value
+		if header == "" {
+			return fmt.Errorf("table: header cell %s is empty", GetCellIDStringFromCoords(x, miny))
+		}
+		for _, column := range columns {
+			if strings.EqualFold(column, header) {
+				return fmt.Errorf("table: header %q is repeated", header)
+			}
+		}
+		columns = append(columns, header)
+	}
+	s.Tables = append(s.Tables, Table{
+		Name:    name,
+		Ref:     GetCellIDStringFromCoords(minx, miny) + cellRangeChar + GetCellIDStringFromCoords(maxx, maxy),
+		Columns: columns,
+		Options: opts,
+	})
+	return nil
+}
+
+// tableNameTaken reports whether name is the name of a table or a
+// defined name of the file of s, or of a table of s if it has no file.
+func (s *Sheet) tableNameTaken(name string) bool {
+	sheets := []*Sheet{s}
+	if s.File != nil {
+		for _, dn := range s.File.DefinedNames {
+			if strings.EqualFold(dn.Name, name) {
+				return true
+			}
+		}
+		sheets = append(sheets, s.File.Sheets...)
+	}
+	for _, sheet := range sheets {
+		for _, t := range sheet.Tables {
+			if strings.EqualFold(t.Name, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// makeXLSXTable returns the table part of t, which has the id id.
+func (t Table) makeXLSXTable(id int) xlsxTable {
+	style := t.Options.StyleName
+	if style == "" {
+		style = defaultTableStyle
+	}
+	xTable := xlsxTable{
+		Id:          id,
+		Name:        t.Name,
+		DisplayName: t.Name,
+		Ref:         t.Ref,
+		AutoFilter:  &xlsxAutoFilter{Ref: t.Ref},
+		TableStyleInfo: &xlsxTableStyleInfo{
+			Name:              style,
+			ShowFirstColumn:   t.Options.ShowFirstColumn,
+			ShowLastColumn:    t.Options.ShowLastColumn,
+			ShowRowStripes:    t.Options.ShowRowStripes,
+			ShowColumnStripes: t.Options.ShowColumnStripes,
+		},
+	}
+	for i, column := range t.Columns {
+		xTable.TableColumns.TableColumn = append(xTable.TableColumns.TableColumn, xlsxTableColumn{Id: i + 1, Name: column})
+	}
+	xTable.TableColumns.Count = len(t.Columns)
+	return xTable
+}
+
+// readTableFromZipFile returns the table in f, or nil if f is nil.
+func readTableFromZipFile(f *zip.File) (*Table, error) {
+	if f == nil {
+		return nil, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	xTable := new(xlsxTable)
+	if err := xml.NewDecoder(rc).Decode(xTable); err != nil {
+		return nil, err
+	}
+	t := &Table{Name: xTable.DisplayName, Ref: xTable.Ref}
+	if t.Name == "" {
+		t.Name = xTable.Name
+	}
+	for _, column := range xTable.TableColumns.TableColumn {
+		t.Columns = append(t.Columns, column.Name)
+	}
+	if info := xTable.TableStyleInfo; info != nil {
+		t.Options = TableOptions{
+			StyleName:         info.Name,
+			ShowFirstColumn:   info.ShowFirstColumn,
+			ShowLastColumn:    info.ShowLastColumn,
+			ShowRowStripes:    info.ShowRowStripes,
+			ShowColumnStripes: info.ShowColumnStripes,
+		}
+	}
+	return t, nil
+}
@@ -1,96 +1,568 @@
 package xlsx
 
 import (
+	"context"
 	"database/sql"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
 	"reflect"
+	"regexp"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gobuffalo/nulls"
 )
 
-// WriteSlice writes an array to row r. Accepts a pointer to array type 'e',
-// and writes the number of columns to write, 'cols'. If 'cols' is < 0,
-// the entire array will be written if possible. Returns -1 if the 'e'
-// doesn't point to an array, otherwise the number of columns written.
-func (r *Row) WriteSlice(e interface{}, cols int) int {
-	if cols == 0 {
-		return cols
+// WriterOptions control how values are written to cells by a
+// StructWriter. The zero value gives the behaviour of WriteStruct and
+// WriteSlice, which always use the zero value.
+type WriterOptions struct {
+	// NullString is the text written for a NULL value, such as an
+	// invalid sql.NullString or a nil pointer. When it is empty, a NULL
+	// value is written as a blank cell with no value at all.
+	NullString string
+	// BlankEmptyStrings makes an empty string be written as a blank
+	// cell, the same as a NULL value. By default it is written as a
+	// string cell holding the empty string, so that readers can tell
+	// the two apart.
+	BlankEmptyStrings bool
+	// RatPrecision is the number of decimal places a big.Rat is
+	// rounded to. When it is zero, 10 decimal places are used.
+	RatPrecision int
+	// DurationAsText writes a time.Duration as text such as "2h30m0s"
+	// instead of as an Excel time value formatted as durationFormat.
+	DurationAsText bool
+	// StrictPositions makes NewStructWriterWithOptions fail with
+	// ErrDuplicatePosition when two fields are tagged with the same
+	// position, rather than the later field overwriting the earlier.
+	StrictPositions bool
+	// OneBasedColumns makes numeric tag positions count from one, so
+	// that xlsx:"1" is column A, and a position of 0 is an invalid tag.
+	// Positions given as column letters, such as xlsx:"A", are not
+	// affected. By default positions count from zero.
+	OneBasedColumns bool
+	// DecimalFormat is the number format of a decimal value, such as a
+	// shopspring decimal.Decimal, written as a number. When it is empty
+	// the general format is used.
+	DecimalFormat string
+	// BoolFormat sets how booleans are written. By default they are
+	// written as Excel booleans.
+	BoolFormat BoolFormat
+	// TrueText and FalseText are the texts written for true and false
+	// when BoolFormat is BoolAsText. When empty, "Yes" and "No" are
+	// used.
+	TrueText, FalseText string
+	// ZeroTimeAsDate writes a zero time.Time as a date like any other
+	// time, instead of as a blank cell. Excel shows such a date as
+	// nonsense, as it falls long before the dates Excel can represent.
+	ZeroTimeAsDate bool
+	// AutoColumns lets fields leave out their position, either by
+	// having no xlsx tag at all or by a tag such as xlsx:",omitempty".
+	// Such fields are written in declaration order, with nested
+	// structs flattened, each to the lowest column that is after the
+	// column of the previous such field and that no field is tagged
+	// with explicitly. For example, with fields A `xlsx:"1"`, B, C and
+	// D `xlsx:"3"`, B goes to column 0 and C to column 2.
+	AutoColumns bool
+	// ComplexPrecision is the number of decimal places of each part of
+	// a complex number. When it is zero, the fewest digits that
+	// represent the parts exactly are used.
+	ComplexPrecision int
+	// NonFinite sets how a float that is NaN or infinite is written,
+	// as Excel has no number for it. By default such a float is
+	// written as a blank cell.
+	NonFinite NonFiniteFormat
+	// NonFiniteText is the text written for a NaN or infinite float
+	// when NonFinite is NonFiniteAsText.
+	NonFiniteText string
+	// StrictNumbers makes StructWriter.Write fail with ErrInexactNumber
+	// for a number that a cell can't hold exactly, as cells hold
+	// float64 values: an integer beyond ±2^53, or a big.Int,
+	// json.Number or decimal with more digits than a float64 holds. By
+	// default such an integer is written as the nearest float64, or as
+	// text if it is unsigned, and the others as text.
+	StrictNumbers bool
+	// Locale is the locale in which the number formats of tags and
+	// DecimalFormat are written, so that with a German Locale a tag
+	// format of "#.##0,00" groups thousands. See Locale.NumberFormat.
+	// The values themselves are always written in the same way.
+	Locale Locale
+}
+
+// NonFiniteFormat is the way a float that is NaN or infinite is
+// written to a cell.
+type NonFiniteFormat int
+
+const (
+	// NonFiniteAsBlank writes a NaN or infinite float as a blank cell.
+	NonFiniteAsBlank NonFiniteFormat = iota
+	// NonFiniteAsText writes a NaN or infinite float as the text given
+	// by NonFiniteText.
+	NonFiniteAsText
+	// NonFiniteAsError writes a NaN or infinite float as the error
+	// value #NUM!, as Excel shows for a calculation with no finite
+	// result.
+	NonFiniteAsError
+)
+
+// writeFloat writes the float f, of the given bit size, to cell as a
+// number, or as NonFinite says if f is NaN or infinite.
+func (o *WriterOptions) writeFloat(cell *Cell, f float64, bits int) {
+	if !math.IsNaN(f) && !math.IsInf(f, 0) {
+		cell.SetNumeric(strconv.FormatFloat(f, 'f', -1, bits))
+		return
+	}
+	switch o.NonFinite {
+	case NonFiniteAsText:
+		o.writeString(cell, o.NonFiniteText)
+	case NonFiniteAsError:
+		cell.SetString("#NUM!")
+		cell.cellType = CellTypeError
+	default:
+		cell.SetString("")
 	}
+}
 
-	// make sure 'e' is a Ptr to Slice
-	v := reflect.ValueOf(e)
-	if v.Kind() != reflect.Ptr {
-		return -1
+// BoolFormat is the way booleans are written to cells.
+type BoolFormat int
+
+const (
+	// BoolAsBoolean writes booleans as Excel booleans, shown as TRUE
+	// and FALSE.
+	BoolAsBoolean BoolFormat = iota
+	// BoolAsNumber writes booleans as the numbers 1 and 0.
+	BoolAsNumber
+	// BoolAsText writes booleans as the texts given by TrueText and
+	// FalseText.
+	BoolAsText
+)
+
+// writeBool writes the boolean b to cell.
+func (o *WriterOptions) writeBool(cell *Cell, b bool) {
+	switch o.BoolFormat {
+	case BoolAsNumber:
+		if b {
+			cell.SetInt(1)
+		} else {
+			cell.SetInt(0)
+		}
+	case BoolAsText:
+		text := o.FalseText
+		if b {
+			text = o.TrueText
+		}
+		if text == "" {
+			text = "No"
+			if b {
+				text = "Yes"
+			}
+		}
+		o.writeString(cell, text)
+	default:
+		cell.SetBool(b)
 	}
+}
 
-	v = v.Elem()
-	if v.Kind() != reflect.Slice {
-		return -1
+// durationFormat is the number format of a time.Duration written as an
+// Excel time value. The hours are not wrapped at 24, and negative
+// durations get a leading minus sign.
+const durationFormat = "[h]:mm:ss;-[h]:mm:ss"
+
+// writeTime writes the time t to cell, formatted as a date and time.
+func (o *WriterOptions) writeTime(cell *Cell, t time.Time) {
+	if t.IsZero() && !o.ZeroTimeAsDate {
+		o.writeNull(cell)
+		return
 	}
+	cell.SetValue(t)
+}
 
-	// it's a slice, so open up its values
-	n := v.Len()
-	if cols < n && cols > 0 {
-		n = cols
+// writeDuration writes the duration d to cell.
+func (o *WriterOptions) writeDuration(cell *Cell, d time.Duration) {
+	if o.DurationAsText {
+		o.writeString(cell, d.String())
+		return
 	}
+	days := d.Hours() / 24
+	cell.SetFloatWithFormat(days, durationFormat)
+}
 
-	var setCell func(reflect.Value)
-	setCell = func(val reflect.Value) {
-		switch t := val.Interface().(type) {
-		case time.Time:
-			cell := r.AddCell()
-			cell.SetValue(t)
-		case fmt.Stringer: // check Stringer first
-			cell := r.AddCell()
-			cell.SetString(t.String())
-		case sql.NullString: // check null sql types nulls = ''
-			cell := r.AddCell()
-			if cell.SetString(``); t.Valid {
-				cell.SetValue(t.String)
-			}
-		case sql.NullBool:
-			cell := r.AddCell()
-			if cell.SetString(``); t.Valid {
-				cell.SetBool(t.Bool)
+const defaultRatPrecision = 10
+
+// ratPrecision returns the number of decimal places for a big.Rat.
+func (o *WriterOptions) ratPrecision() int {
+	if o.RatPrecision == 0 {
+		return defaultRatPrecision
+	}
+	return o.RatPrecision
+}
+
+// writeExactNumber writes the decimal number s to cell. The cell is
+// numeric when s survives being read back as a float64, and holds s as
+// text otherwise, so that no digits are silently lost.
+func writeExactNumber(cell *Cell, s string) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err == nil && strconv.FormatFloat(f, 'f', -1, 64) == s {
+		cell.SetNumeric(s)
+		return
+	}
+	cell.SetString(s)
+}
+
+// checkExactNumber returns an error wrapping ErrInexactNumber if v, or
+// an element of v if it is a slice, is a number that a cell can't hold
+// exactly, as set out for StrictNumbers.
+func checkExactNumber(v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	var text string
+	exact := true
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := v.Int()
+		text, exact = strconv.FormatInt(n, 10), n >= -maxExactUint && n <= maxExactUint
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n := v.Uint()
+		text, exact = strconv.FormatUint(n, 10), n <= maxExactUint
+	case reflect.Slice, reflect.Array:
+		if isTextSlice(v.Type()) {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := checkExactNumber(v.Index(i)); err != nil {
+				return err
 			}
+		}
+		return nil
+	case reflect.Struct, reflect.String:
+		if !v.CanInterface() {
+			return nil
+		}
+		switch t := v.Interface().(type) {
 		case sql.NullInt64:
-			cell := r.AddCell()
-			if cell.SetString(``); t.Valid {
-				cell.SetValue(t.Int64)
+			if t.Valid {
+				return checkExactNumber(reflect.ValueOf(t.Int64))
+			}
+		case nulls.Int64:
+			if t.Valid {
+				return checkExactNumber(reflect.ValueOf(t.Int64))
 			}
-		case sql.NullFloat64:
-			cell := r.AddCell()
-			if cell.SetString(``); t.Valid {
-				cell.SetValue(t.Float64)
+		case nulls.Int:
+			if t.Valid {
+				return checkExactNumber(reflect.ValueOf(t.Int))
 			}
-		default:
-			switch val.Kind() { // underlying type of slice
-			case reflect.String, reflect.Int, reflect.Int8,
-				reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float64, reflect.Float32:
-				cell := r.AddCell()
-				cell.SetValue(val.Interface())
-			case reflect.Bool:
-				cell := r.AddCell()
-				cell.SetBool(t.(bool))
-			case reflect.Interface:
-				setCell(reflect.ValueOf(t))
+		case big.Int:
+			text = t.String()
+		case json.Number:
+			text = t.String()
+		case decimal:
+			text = t.String()
+		}
+		if text != "" {
+			f, err := strconv.ParseFloat(text, 64)
+			exact = err == nil && isExactFloat(text, f)
+		}
+	}
+	if !exact {
+		return fmt.Errorf("value %s: %w", text, ErrInexactNumber)
+	}
+	return nil
+}
+
+// decimal is implemented by arbitrary precision decimal types such as
+// github.com/shopspring/decimal.Decimal, which would otherwise be
+// written as text for being a fmt.Stringer.
+type decimal interface {
+	Float64() (float64, bool)
+	String() string
+}
+
+// writeDecimal writes the decimal d to cell. Like writeExactNumber it
+// falls back to text when d has more digits than a float64 can hold;
+// the exactness reported by Float64 is not used, as it is false for
+// any value without an exact binary representation, such as 0.1.
+func (o *WriterOptions) writeDecimal(cell *Cell, d decimal) {
+	writeExactNumber(cell, d.String())
+	if cell.cellType == CellTypeNumeric && o.DecimalFormat != "" {
+		cell.SetFormat(o.Locale.NumberFormat(o.DecimalFormat))
+	}
+}
+
+// ratString formats r as a decimal with at most 'prec' decimal places,
+// dropping trailing zeros.
+func ratString(r *big.Rat, prec int) string {
+	s := r.FloatString(prec)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	if s == "-0" {
+		s = "0"
+	}
+	return s
+}
+
+// writeNull writes a NULL value to cell.
+func (o *WriterOptions) writeNull(cell *Cell) {
+	cell.SetString(o.NullString)
+}
+
+// writeString writes the string s to cell.
+func (o *WriterOptions) writeString(cell *Cell, s string) {
+	cell.SetString(s)
+	if s == "" && !o.BlankEmptyStrings {
+		// An empty inline string is saved as a string cell, where an
+		// empty string of any other type is saved as a blank cell.
+		cell.cellType = CellTypeInline
+	}
+}
+
+// WriteSlice appends an array to row r. Accepts a pointer to a slice or
+// array type 'e', and writes the number of columns to write, 'cols'. If
+// 'cols' is < 0, the entire array will be written if possible. Returns
+// -1 if the 'e' doesn't point to a slice or array, otherwise the number
+// of columns written.
+// A nil element, and one whose MarshalText method fails or panics, is
+// written as an empty cell. Each call adds new cells after those already in the
+// row; use SetSlice to overwrite them instead. Merged cells get no
+// special treatment: a value written to the top-left cell of a merge
+// shows across all of it, while values written to the cells it covers
+// are saved but hidden.
+func (r *Row) WriteSlice(e interface{}, cols int) int {
+	v, n := sliceToWrite(e, cols)
+	if n <= 0 {
+		return n
+	}
+
+	o := &WriterOptions{}
+	var i int
+	for i = 0; i < n; i++ {
+		o.writeElement(v.Index(i), r.AddCell) // a failed value leaves an empty cell
+	}
+	return i
+}
+
+// WriteSliceUntilNil is like WriteSlice, writing all of 'e', but takes
+// the first nil interface value in 'e' to mark the end of the data:
+// that element and those after it aren't written. Other empty values,
+// such as "" or a nil pointer held in an interface, are written as
+// usual. Returns the number of cells written, or -1 if 'e' doesn't
+// point to a slice or array.
+func (r *Row) WriteSliceUntilNil(e interface{}) int {
+	v, n := sliceToWrite(e, -1)
+	if n <= 0 {
+		return n
+	}
+	for i := 0; i < n; i++ {
+		if elem := v.Index(i); elem.Kind() == reflect.Interface && elem.IsNil() {
+			n = i
+			break
+		}
+	}
+	return r.WriteSlice(e, n)
+}
+
+// WriteSliceE is like WriteSlice, but stops at the first element that
+// can't be written, returning the number of elements written before it
+// together with an error giving its index and type. That covers values
+// that fail to marshal themselves, values of a kind such as a channel
+// that has no cell representation, and values whose methods panic.
+func (r *Row) WriteSliceE(e interface{}, cols int) (int, error) {
+	v, n := sliceToWrite(e, cols)
+	if n < 0 {
+		return n, errNotSlicePointer
+	}
+
+	o := &WriterOptions{}
+	for i := 0; i < n; i++ {
+		elem := v.Index(i)
+		written, err := o.writeElement(elem, r.AddCell)
+		if err == nil && !written {
+			err = errors.New("value can't be written to a cell")
+		}
+		if err != nil {
+			typ := elem.Type()
+			if elem.Kind() == reflect.Interface && !elem.IsNil() {
+				typ = elem.Elem().Type()
 			}
+			return i, fmt.Errorf("xlsx: element %d of type %s: %w", i, typ, err)
+		}
+	}
+	return n, nil
+}
+
+// writeElement is writeValue, turning a panic, such as from a String
+// method or a CellEncoder, into an error.
+func (o *WriterOptions) writeElement(val reflect.Value, cell func() *Cell) (written bool, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			written, err = true, fmt.Errorf("panic: %v", p)
 		}
+	}()
+	return o.writeValue(val, cell)
+}
+
+// WriteValues appends a cell to row r for each of 'vals', written the
+// same way as the elements of a slice by WriteSlice, and returns the
+// number of cells written. A nil value is written as an empty cell.
+func (r *Row) WriteValues(vals ...interface{}) int {
+	return r.WriteSlice(&vals, -1)
+}
+
+// AddRowValues adds a new row to sheet s holding 'vals', as written by
+// WriteValues, and returns it.
+func (s *Sheet) AddRowValues(vals ...interface{}) *Row {
+	row := s.AddRow()
+	row.WriteValues(vals...)
+	return row
+}
+
+// SetSlice is like WriteSlice, but sets the cells of row r starting at
+// column 0 instead of adding new cells to the end of the row, so that
+// calling it again overwrites the values of the previous call rather
+// than appending to them. Cells past the end of 'e' are left as they
+// were.
+func (r *Row) SetSlice(e interface{}, cols int) int {
+	return r.WriteSliceAt(e, 0, cols)
+}
+
+// WriteSliceAt is like WriteSlice, but writes the elements of 'e' to
+// the cells starting at column 'col' instead of adding new cells to the
+// end of the row. A negative 'col' is treated as 0.
+func (r *Row) WriteSliceAt(e interface{}, col, cols int) int {
+	v, n := sliceToWrite(e, cols)
+	if n <= 0 {
+		return n
+	}
+	if col < 0 {
+		col = 0
 	}
 
+	o := &WriterOptions{}
 	var i int
 	for i = 0; i < n; i++ {
-		setCell(v.Index(i))
+		pos := col + i
+		o.writeElement(v.Index(i), func() *Cell { return r.GetCell(pos) }) // a failed value leaves an empty cell
 	}
 	return i
 }
 
+// sliceToWrite returns the slice or array pointed to by 'e' together
+// with the number of its elements to write, given the 'cols' argument
+// of WriteSlice. The number is -1 if 'e' doesn't point to a slice or
+// array.
+func sliceToWrite(e interface{}, cols int) (reflect.Value, int) {
+	if cols == 0 {
+		return reflect.Value{}, cols
+	}
+
+	// make sure 'e' is a Ptr to Slice or Array
+	v := reflect.ValueOf(e)
+	if v.Kind() != reflect.Ptr {
+		return reflect.Value{}, -1
+	}
+
+	v = v.Elem()
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return reflect.Value{}, -1
+	}
+
+	// it's a slice or array, so open up its values
+	n := v.Len()
+	if cols < n && cols > 0 {
+		n = cols
+	}
+	return v, n
+}
+
 // WriteStruct writes a struct to row r. Accepts a pointer to struct type
 // 'e', and the number of columns to write, `cols`. If 'cols' is < 0,
 // the entire struct will be written if possible. Returns -1 if the 'e'
 // doesn't point to a struct, otherwise the number of columns written
+//
+// Each exported field needs a tag of the form xlsx:"N" where N is the
+// index of the cell to write to, or the letters of its column as in
+// xlsx:"D"; fields tagged xlsx:"-" and unexported fields are skipped.
+// Indexes count from zero here; a StructWriter created with the
+// OneBasedColumns option counts them from one instead.
+//
+// A time.Time field may carry a second token giving its format, either
+// one of the names "date", "datetime" and "time", a Go reference layout
+// such as xlsx:"3,2006-01-02", or an Excel format code such as
+// xlsx:"3,h:mm AM/PM". A zero time.Time is written as an empty cell. On
+// a numeric field, including a numeric null type, the second token is
+// an Excel number format; as number formats often contain commas it may
+// be quoted, as in xlsx:"3,'#,##0.00'" or xlsx:"4,'0.00%'".
+//
+// Exported fields holding other structs, and embedded structs whether
+// exported or not, are flattened into the row, with each nested field
+// written at the position given by its own tag. The fields of a nil
+// embedded pointer are skipped. Pointer fields are written as the value
+// they point to. A nil pointer is written as an empty cell and, like
+// any other written cell, counts towards the number of columns
+// returned. A field of interface type is written according to the value
+// it holds, and as an empty cell when it is nil.
+//
+// Values implementing encoding.TextMarshaler are written as their text,
+// and an error from MarshalText is returned. Decimal types such as
+// shopspring's decimal.Decimal, recognised by their Float64() (float64,
+// bool) method, are written as numbers when no digits are lost and as
+// text otherwise. A value whose type has a CellEncoder, see
+// RegisterCellEncoder, is written by it instead of as described here.
+//
+// A slice or array field is spread across consecutive cells starting at
+// its tagged position, taking one column for each element and none when
+// it is nil or empty, so the number of columns a slice takes varies
+// from row to row. The elements of a []interface{}, such as one from
+// decoded JSON, are each written according to the value they hold, with
+// a nil element taking a column as an empty cell. Fields are written in
+// order, so a later field whose position falls within that span
+// overwrites the element there. Slices of bytes or runes are the
+// exception, and are written to a single cell as a string; bytes that
+// aren't valid UTF-8 are written base64 encoded.
+//
+// A field tagged with the "omitempty" option, as in xlsx:"3,omitempty",
+// is not written at all when it holds an empty value, leaving whatever
+// the cell held before. A field tagged with a default, as in
+// xlsx:"3,default=N/A", has the text of the default written instead of
+// an empty value. The "trim" option strips the white space around the
+// text of a string field, or of a string null type, before it is
+// written, and before it is checked for being empty; other fields are
+// written as usual.
+//
+// The "number" option writes a string field, or a string null type,
+// that holds a decimal number as a numeric cell, as long as no digits
+// are lost on the way, and otherwise as text; the format of the tag
+// then applies. The "text" option does the opposite, writing a value
+// that would be a number as the text it is shown as, so that
+// xlsx:"3,text,00000" writes 42 as "00042". The "unix" and "unixmilli"
+// options write a time.Time or nulls.Time field as the number of
+// seconds or milliseconds since the Unix epoch, rather than as an Excel
+// date, as in xlsx:"3,unix".
+//
+// The "hyperlink" option writes a string field, or a string null type,
+// that isn't empty as a link to the URL it holds, as Cell.SetHyperlink
+// does. A field tagged with the "json" option, as in xlsx:"7,json", is
+// written to a single cell as JSON text; this suits maps and other
+// values that have no cell representation, and an error from
+// json.Marshal is returned.
+//
+// As with WriteSlice, a value shows across a merge only when written
+// to its top-left cell; see Sheet.MergeCells.
+//
+// WriteStruct inspects the struct type on every call; use a
+// StructWriter when writing many values of the same type.
 func (r *Row) WriteStruct(e interface{}, cols int) (int, error) {
 	if cols == 0 {
 		return cols, nil
@@ -98,96 +570,882 @@ func (r *Row) WriteStruct(e interface{}, cols int) (int, error) {
 
 	v := reflect.ValueOf(e).Elem()
 	if v.Kind() != reflect.Struct {
-		return 0, errNotStructPointer
+		return 0, ErrNotStructPointer
 	}
 
-	n := v.NumField() // number of fields in struct
-	if cols < n && cols > 0 {
-		n = cols
+	fields, err := structFields(v.Type(), &WriterOptions{})
+	if err != nil {
+		return 0, err
 	}
+	return fields.write(r, v, cols, nil)
+}
 
-	var k int
-	for i := 0; i < n; i, k = i+1, k+1 {
-		field := v.Type().Field(i)
-		idx := field.Tag.Get("xlsx")
+// WriteStructCols is like WriteStruct, writing all of 'e', but returns
+// the indexes of the columns written to, in increasing order and each
+// once, rather than their number. Columns left alone, such as those of
+// omitempty fields holding an empty value, aren't included.
+func (r *Row) WriteStructCols(e interface{}) ([]int, error) {
+	v := reflect.ValueOf(e)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, errNilInterface
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, ErrNotStructPointer
+	}
 
-		if idx == "-" {
-			k-- // nothing set to reset to previous
-			continue
+	fields, err := structFields(v.Type(), &WriterOptions{})
+	if err != nil {
+		return nil, err
+	}
+	written := make(map[int]bool)
+	_, err = fields.write(r, v, -1, func(_ reflect.StructField, col int, _ *Cell) {
+		written[col] = true
+	})
+	cols := make([]int, 0, len(written))
+	for col := range written {
+		cols = append(cols, col)
+	}
+	sort.Ints(cols)
+	return cols, err
+}
+
+// isTextSlice reports whether t is a slice of bytes or of runes, which
+// is written as a single string rather than element by element.
+func isTextSlice(t reflect.Type) bool {
+	if t.Kind() != reflect.Slice {
+		return false
+	}
+	k := t.Elem().Kind()
+	return k == reflect.Uint8 || k == reflect.Int32
+}
+
+// writeTextSlice writes the byte or rune slice v to cell as a string.
+// Bytes that aren't valid UTF-8 are written base64 encoded, and a nil
+// slice is written as NULL.
+func (o *WriterOptions) writeTextSlice(cell *Cell, v reflect.Value) {
+	if v.IsNil() {
+		o.writeNull(cell)
+		return
+	}
+	if v.Type().Elem().Kind() == reflect.Int32 {
+		runes := make([]rune, v.Len())
+		for i := range runes {
+			runes[i] = rune(v.Index(i).Int())
 		}
+		o.writeString(cell, string(runes))
+		return
+	}
+	b := v.Bytes()
+	if utf8.Valid(b) {
+		o.writeString(cell, string(b))
+		return
+	}
+	o.writeString(cell, base64.StdEncoding.EncodeToString(b))
+}
+
+// kindWriter returns the function that writes a value of kind k to a
+// cell, or nil if values of that kind can't be written directly.
+func (o *WriterOptions) kindWriter(k reflect.Kind) func(*Cell, reflect.Value) {
+	switch k {
+	case reflect.String:
+		return o.writeStringValue
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return writeIntValue
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return writeUintValue
+	case reflect.Float32, reflect.Float64:
+		return o.writeFloatValue
+	case reflect.Bool:
+		return o.writeBoolValue
+	case reflect.Complex64, reflect.Complex128:
+		return o.writeComplexValue
+	}
+	return nil
+}
+
+func (o *WriterOptions) writeStringValue(cell *Cell, v reflect.Value) {
+	o.writeString(cell, v.String())
+}
+
+func writeIntValue(cell *Cell, v reflect.Value) {
+	cell.SetInt64(v.Int())
+}
+
+func writeUintValue(cell *Cell, v reflect.Value) {
+	writeUint(cell, v.Uint())
+}
+
+func (o *WriterOptions) writeFloatValue(cell *Cell, v reflect.Value) {
+	o.writeFloat(cell, v.Float(), v.Type().Bits())
+}
+
+func (o *WriterOptions) writeBoolValue(cell *Cell, v reflect.Value) {
+	o.writeBool(cell, v.Bool())
+}
+
+// writeComplexValue writes a complex number as text of the form
+// "a+bi", as used by Excel's engineering functions such as IMSUM, and
+// understood by parseComplex.
+func (o *WriterOptions) writeComplexValue(cell *Cell, v reflect.Value) {
+	bits := 64
+	if v.Kind() == reflect.Complex64 {
+		bits = 32
+	}
+	if o.ComplexPrecision == 0 {
+		o.writeString(cell, formatComplex(v.Complex(), 'g', -1, bits))
+	} else {
+		o.writeString(cell, formatComplex(v.Complex(), 'f', o.ComplexPrecision, bits))
+	}
+}
+
+// formatComplex formats z as "a+bi", with each part formatted by
+// strconv.FormatFloat with the format, precision and bit size given.
+func formatComplex(z complex128, fmt byte, prec, bitSize int) string {
+	im := strconv.FormatFloat(imag(z), fmt, prec, bitSize)
+	if im[0] != '+' && im[0] != '-' {
+		im = "+" + im
+	}
+	return strconv.FormatFloat(real(z), fmt, prec, bitSize) + im + "i"
+}
+
+// maxExactUint is the largest integer that a float64, and so a numeric
+// cell, holds without loss of precision.
+const maxExactUint = 1 << 53
+
+// writeUint writes u to cell as a number, or as text when it is too
+// large to survive being read back as a float64.
+func writeUint(cell *Cell, u uint64) {
+	if u > maxExactUint {
+		cell.SetString(strconv.FormatUint(u, 10))
+		return
+	}
+	cell.SetUint64(u)
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	stringerType      = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	decimalType       = reflect.TypeOf((*decimal)(nil)).Elem()
+)
+
+// implementsValueInterface reports whether t implements one of the
+// interfaces that writeValue uses to turn a value into text.
+func implementsValueInterface(t reflect.Type) bool {
+	return t.Implements(stringerType) || t.Implements(textMarshalerType)
+}
+
+// isNestedStruct reports whether t is a struct type whose fields are
+// flattened into the row, rather than a type such as time.Time that is
+// written to a single cell.
+func isNestedStruct(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	if t == timeType || isNullType(t) || lookupCellEncoder(t) != nil {
+		return false
+	}
+	return !implementsValueInterface(t) && !implementsValueInterface(reflect.PtrTo(t))
+}
+
+// AppendStruct adds a new row to the end of sheet s and writes 'e' to
+// it with WriteStruct, returning the row. MaxCol is raised to take in
+// the columns of the row, so that it gives the width of the sheet
+// written this way. An error from WriteStruct is returned unchanged,
+// together with the row.
+func (s *Sheet) AppendStruct(e interface{}) (*Row, error) {
+	row := s.AddRow()
+	_, err := row.WriteStruct(e, -1)
+	if row.cellCount > s.MaxCol {
+		s.MaxCol = row.cellCount
+	}
+	return row, err
+}
 
-		pos, err := strconv.Atoi(idx)
+// WriteStructTransposed writes 'e', a struct or a pointer to one, down
+// column col of sheet s, with each field in the row given by its tag
+// position rather than in the column. The values are written as by
+// WriteStruct, so that a slice field still spreads across the columns
+// from col onwards. Returns the number of fields written, leaving out
+// omitempty fields holding an empty value.
+func (s *Sheet) WriteStructTransposed(e interface{}, col int) (int, error) {
+	v := reflect.ValueOf(e)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return 0, errNilInterface
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return 0, ErrNotStructPointer
+	}
+	fields, err := structFields(v.Type(), &WriterOptions{})
+	if err != nil {
+		return 0, err
+	}
+	var n int
+	for _, f := range fields {
+		fv, ok := fieldByIndex(v, f.index)
+		if !ok {
+			continue
+		}
+		row, err := s.Row(f.col)
 		if err != nil {
-			return 0, errInvalidTag
-		}
-
-		f := v.Field(i)
-		switch t := f.Interface().(type) {
-		case time.Time:
-			cell := r.GetCell(pos)
-			cell.SetValue(t)
-		case fmt.Stringer: // check Stringer first
-			cell := r.GetCell(pos)
-			cell.SetString(t.String())
-		case sql.NullString: // check null sql types nulls = ''
-			cell := r.GetCell(pos)
-			if cell.SetString(``); t.Valid {
-				cell.SetValue(t.String)
-			}
-		case sql.NullBool:
-			cell := r.GetCell(pos)
-			if cell.SetString(``); t.Valid {
-				cell.SetBool(t.Bool)
-			}
-		case sql.NullInt64:
-			cell := r.GetCell(pos)
-			if cell.SetString(``); t.Valid {
-				cell.SetValue(t.Int64)
-			}
-		case sql.NullFloat64:
-			cell := r.GetCell(pos)
-			if cell.SetString(``); t.Valid {
-				cell.SetValue(t.Float64)
-			}
-		case nulls.String: // check null sql types nulls = ''
-			cell := r.GetCell(pos)
-			if cell.SetString(``); t.Valid {
-				cell.SetValue(t.String)
-			}
-		case nulls.Bool:
-			cell := r.GetCell(pos)
-			if cell.SetString(``); t.Valid {
-				cell.SetBool(t.Bool)
-			}
-		case nulls.Int:
-			cell := r.GetCell(pos)
-			if cell.SetString(``); t.Valid {
-				cell.SetValue(t.Int)
-			}
-		case nulls.Int64:
-			cell := r.GetCell(pos)
-			if cell.SetString(``); t.Valid {
-				cell.SetValue(t.Int64)
+			return n, err
+		}
+		written, err := f.write(row, col, fv)
+		if len(written) > 0 {
+			n++
+		}
+		if row.cellCount > s.MaxCol {
+			s.MaxCol = row.cellCount
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// WriteMatrix writes each of the slices of data to a new row at the end
+// of sheet s, an element to a cell as by WriteSlice. The slices may be
+// of different lengths, and an empty one gives an empty row. Returns
+// the number of rows written. An element that can't be written, as by
+// WriteSliceE, stops the writing with an error giving its row, which is
+// left holding the elements before it.
+func (s *Sheet) WriteMatrix(data [][]interface{}) (int, error) {
+	for i := range data {
+		if _, err := s.AddRow().WriteSliceE(&data[i], -1); err != nil {
+			return i, fmt.Errorf("row %d: %w", i, err)
+		}
+	}
+	return len(data), nil
+}
+
+// WriteStructs writes each element of 'records' to a new row at the end
+// of sheet s. Accepts a slice of structs or of pointers to structs, or
+// a pointer to such a slice; a nil element gives an empty row. The
+// elements are written as by WriteStruct, with the struct type only
+// inspected once. Returns the number of rows written.
+func (s *Sheet) WriteStructs(records interface{}) (int, error) {
+	return s.WriteStructsContext(context.Background(), records)
+}
+
+// writeStructsCheckInterval is the number of rows WriteStructsContext
+// writes between checks of its context.
+const writeStructsCheckInterval = 1000
+
+// WriteStructsContext is like WriteStructs, but stops once ctx is done.
+// The context is checked every few rows, and when it has been cancelled
+// the number of rows written so far is returned together with
+// ctx.Err().
+func (s *Sheet) WriteStructsContext(ctx context.Context, records interface{}) (int, error) {
+	v, fields, err := structSliceFields(records)
+	if err != nil {
+		return 0, err
+	}
+	n := v.Len()
+	for i := 0; i < n; i++ {
+		if i%writeStructsCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return i, err
 			}
-		case nulls.Float64:
-			cell := r.GetCell(pos)
-			if cell.SetString(``); t.Valid {
-				cell.SetValue(t.Float64)
+		}
+		if err := s.writeStructRow(fields, v.Index(i)); err != nil {
+			return i, err
+		}
+	}
+	return n, nil
+}
+
+// WriteStructsPaged writes the elements of 'records', which are taken
+// as by WriteStructs, across new sheets of f named baseName followed by
+// 1, 2 and so on. Each sheet gets a header row, as written by
+// WriteStructHeader, followed by up to rowsPerSheet of the records;
+// there is always at least one sheet, even when there are no records.
+// rowsPerSheet must leave room for the header within
+// Excel2006MaxRowCount. Returns the sheets created, which on error
+// include the one being written.
+func (f *File) WriteStructsPaged(baseName string, records interface{}, rowsPerSheet int) ([]*Sheet, error) {
+	if rowsPerSheet < 1 || rowsPerSheet >= Excel2006MaxRowCount {
+		return nil, fmt.Errorf("rows per sheet must be between 1 and %d, not %d", Excel2006MaxRowCount-1, rowsPerSheet)
+	}
+	v, fields, err := structSliceFields(records)
+	if err != nil {
+		return nil, err
+	}
+	var sheets []*Sheet
+	n := v.Len()
+	for start := 0; start < n || len(sheets) == 0; start += rowsPerSheet {
+		sheet, err := f.AddSheet(fmt.Sprintf("%s%d", baseName, len(sheets)+1))
+		if err != nil {
+			return sheets, err
+		}
+		sheets = append(sheets, sheet)
+		fields.writeHeader(sheet.AddRow())
+		end := start + rowsPerSheet
+		if end > n {
+			end = n
+		}
+		for i := start; i < end; i++ {
+			if err := sheet.writeStructRow(fields, v.Index(i)); err != nil {
+				return sheets, err
 			}
+		}
+	}
+	return sheets, nil
+}
+
+// structSliceFields returns the slice or array held in, or pointed to
+// by, records, along with the fields of the struct type of its
+// elements, which may be structs or pointers to them.
+func structSliceFields(records interface{}) (reflect.Value, structFieldList, error) {
+	v := reflect.ValueOf(records)
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return v, nil, ErrNotStructPointer
+	}
+	t := v.Type().Elem()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return v, nil, ErrNotStructPointer
+	}
+	fields, err := structFields(t, &WriterOptions{})
+	return v, fields, err
+}
+
+// writeStructRow writes elem, a struct or a pointer to one, to a new
+// row at the end of sheet s, leaving the row empty for a nil pointer.
+func (s *Sheet) writeStructRow(fields structFieldList, elem reflect.Value) error {
+	row := s.AddRow()
+	if elem.Kind() == reflect.Ptr {
+		if elem.IsNil() {
+			return nil
+		}
+		elem = elem.Elem()
+	}
+	_, err := fields.write(row, elem, -1, nil)
+	return err
+}
+
+// WriteStructHeader writes a header row for the struct type of 'e' to
+// row r. Accepts a struct or a pointer to one. Each field gets a
+// header cell at the position given by its tag, labelled with the
+// third token of the tag when present, as in xlsx:"3,,Full Name", and
+// with the field name otherwise. Returns the number of headers
+// written.
+func (r *Row) WriteStructHeader(e interface{}) (int, error) {
+	t := reflect.TypeOf(e)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return 0, ErrNotStructPointer
+	}
+	fields, err := structFields(t, &WriterOptions{})
+	if err != nil {
+		return 0, err
+	}
+	return fields.writeHeader(r), nil
+}
+
+// WriteMap writes the values of m to row r in the order given by
+// 'headers', looking up each header as a key of m. An empty cell is
+// written for a header that has no entry in m. Values are written the
+// same way as by WriteSlice. Returns the number of columns written.
+func (r *Row) WriteMap(m map[string]interface{}, headers []string) int {
+	o := &WriterOptions{}
+	var n int
+	for _, h := range headers {
+		v, ok := m[h]
+		if !ok || v == nil {
+			r.AddCell().SetString("")
+		} else if written, _ := o.writeValue(reflect.ValueOf(v), r.AddCell); !written {
+			r.AddCell().SetString("")
+		}
+		n++
+	}
+	return n
+}
+
+// writeValue writes val to the cell returned by cell, choosing the
+// cell type from the type of val. The cell is only requested once val
+// is known to be writable, and the result reports whether it was. If
+// val fails to marshal itself, the cell is left empty and the error is
+// returned.
+func (o *WriterOptions) writeValue(val reflect.Value, cell func() *Cell) (bool, error) {
+	if !val.IsValid() {
+		return false, nil
+	}
+	if (val.Kind() == reflect.Ptr || val.Kind() == reflect.Interface) && val.IsNil() {
+		o.writeNull(cell())
+		return true, nil
+	}
+	// An interface may hold a nil pointer, whose methods mustn't be
+	// called, so check the value it holds as well.
+	if val.Kind() == reflect.Interface {
+		return o.writeValue(val.Elem(), cell)
+	}
+	if enc := lookupCellEncoder(val.Type()); enc != nil {
+		return true, enc(cell(), val.Interface())
+	}
+	// Write a pointer as the value it points to, unless the methods that
+	// decide how it's written are only defined on the pointer, as for
+	// *big.Int. Otherwise a *time.Time would be written as a Stringer.
+	if val.Kind() == reflect.Ptr && (implementsValueInterface(val.Type().Elem()) || !implementsValueInterface(val.Type())) {
+		return o.writeValue(val.Elem(), cell)
+	}
+	switch t := val.Interface().(type) {
+	case time.Time:
+		o.writeTime(cell(), t)
+	case time.Duration: // a Stringer, but written as a time
+		o.writeDuration(cell(), t)
+	case json.Number: // a Stringer, but numeric when it parses as a number
+		if _, err := t.Float64(); err == nil {
+			cell().SetNumeric(t.String())
+		} else {
+			o.writeString(cell(), t.String())
+		}
+	case *big.Int:
+		writeExactNumber(cell(), t.String())
+	case big.Int:
+		writeExactNumber(cell(), t.String())
+	case *big.Rat:
+		writeExactNumber(cell(), ratString(t, o.ratPrecision()))
+	case big.Rat:
+		writeExactNumber(cell(), ratString(&t, o.ratPrecision()))
+	case decimal: // a Stringer, but numeric when it fits a float64
+		o.writeDecimal(cell(), t)
+	case fmt.Stringer: // check Stringer first
+		o.writeString(cell(), t.String())
+	case sql.NullString: // check null sql types nulls = ''
+		if c := cell(); t.Valid {
+			o.writeString(c, t.String)
+		} else {
+			o.writeNull(c)
+		}
+	case sql.NullBool:
+		if c := cell(); t.Valid {
+			o.writeBool(c, t.Bool)
+		} else {
+			o.writeNull(c)
+		}
+	case sql.NullInt64:
+		if c := cell(); t.Valid {
+			c.SetValue(t.Int64)
+		} else {
+			o.writeNull(c)
+		}
+	case sql.NullFloat64:
+		if c := cell(); t.Valid {
+			o.writeFloat(c, t.Float64, 64)
+		} else {
+			o.writeNull(c)
+		}
+	case nulls.String:
+		if c := cell(); t.Valid {
+			o.writeString(c, t.String)
+		} else {
+			o.writeNull(c)
+		}
+	case nulls.Bool:
+		if c := cell(); t.Valid {
+			o.writeBool(c, t.Bool)
+		} else {
+			o.writeNull(c)
+		}
+	case nulls.Int:
+		if c := cell(); t.Valid {
+			c.SetValue(t.Int)
+		} else {
+			o.writeNull(c)
+		}
+	case nulls.Int64:
+		if c := cell(); t.Valid {
+			c.SetValue(t.Int64)
+		} else {
+			o.writeNull(c)
+		}
+	case nulls.Float64:
+		if c := cell(); t.Valid {
+			o.writeFloat(c, t.Float64, 64)
+		} else {
+			o.writeNull(c)
+		}
+	case nulls.Time:
+		if c := cell(); t.Valid {
+			o.writeTime(c, t.Time)
+		} else {
+			o.writeNull(c)
+		}
+	case nulls.UUID:
+		if c := cell(); t.Valid {
+			o.writeString(c, t.UUID.String())
+		} else {
+			o.writeNull(c)
+		}
+	case encoding.TextMarshaler:
+		text, err := t.MarshalText()
+		if err != nil {
+			cell().SetString("")
+			return true, err
+		}
+		o.writeString(cell(), string(text))
+	default:
+		if isTextSlice(val.Type()) {
+			o.writeTextSlice(cell(), val)
+			return true, nil
+		}
+		if w := o.kindWriter(val.Kind()); w != nil { // underlying type of the value
+			w(cell(), val)
+			return true, nil
+		}
+		switch val.Kind() {
+		case reflect.Interface, reflect.Ptr:
+			return o.writeValue(val.Elem(), cell)
+		default:
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// fieldTag holds the parts of an xlsx struct tag of the form
+// xlsx:"pos,format,header,options...".
+type fieldTag struct {
+	pos       int    // index of the cell
+	letters   bool   // whether pos was given as column letters
+	auto      bool   // whether pos was left out, see AutoColumns
+	name      string // header label the field is mapped to instead of pos
+	format    string // optional format of the cell
+	header    string // optional header label of the column
+	omitEmpty bool   // leave the cell alone when the value is empty
+	def       string // text written instead of an empty value
+	hasDef    bool   // whether def is set, as it may be ""
+	json      bool   // write the value as JSON text
+	trim      bool   // strip white space around a string before writing
+	number    bool   // write a string that holds a number as a number
+	text      bool   // write a number as the text it is shown as
+	unix      string // "unix" or "unixmilli": write a time as a Unix timestamp
+	hyperlink bool   // write a string as a hyperlink to itself
+}
+
+// parseTag splits an xlsx struct tag into its parts. The index may be
+// given either as a number or as column letters, so that "3" and "D"
+// are equivalent, or left out, for which 'auto' is set. In place of
+// the index, "name=Label" maps the field to the column headed Label
+// when reading with ReadStructsWithOptions. Options such as
+// "omitempty", "json", "trim", "number", "text", "unix", "unixmilli",
+// "hyperlink" and "default=text" may appear anywhere after the index; the remaining tokens are, in order,
+// the format and the header. Tokens are split as by splitTag, so a
+// format containing commas has to be quoted, as in
+// xlsx:"3,'#,##0.00'", and a quoted token is never taken as an option.
+// A tag can't have both "number" and "text".
+func parseTag(tag string) (fieldTag, error) {
+	parts, quoted, err := splitTag(tag)
+	if err != nil {
+		return fieldTag{}, err
+	}
+	var ft fieldTag
+	switch {
+	case parts[0] == "":
+		ft.auto = true
+	case strings.HasPrefix(parts[0], "name=") && !quoted[0]:
+		ft.name = strings.TrimPrefix(parts[0], "name=")
+		if ft.name == "" {
+			return fieldTag{}, ErrInvalidTag
+		}
+	case tagOptions[parts[0]] && !quoted[0]:
+		// An option in place of the index, as in xlsx:"omitempty",
+		// is an index left out by mistake, not a column.
+		return fieldTag{}, ErrInvalidTag
+	default:
+		pos, err := parseColumn(parts[0])
+		if err != nil {
+			return fieldTag{}, err
+		}
+		ft.pos, ft.letters = pos, !isDigits(parts[0])
+	}
+	var positional int
+	for i, part := range parts[1:] {
+		switch {
+		case quoted[i+1]:
+		case part == "omitempty":
+			ft.omitEmpty = true
+			continue
+		case part == "json":
+			ft.json = true
+			continue
+		case part == "trim":
+			ft.trim = true
+			continue
+		case part == "number":
+			ft.number = true
+			continue
+		case part == "text":
+			ft.text = true
+			continue
+		case part == "unix" || part == "unixmilli":
+			ft.unix = part
+			continue
+		case part == "hyperlink":
+			ft.hyperlink = true
+			continue
+		case strings.HasPrefix(part, "default="):
+			ft.def = strings.TrimPrefix(part, "default=")
+			ft.hasDef = true
+			continue
+		}
+		switch positional {
+		case 0:
+			ft.format = part
+		case 1:
+			ft.header = part
 		default:
-			switch f.Kind() {
-			case reflect.String, reflect.Int, reflect.Int8,
-				reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float64, reflect.Float32:
-				cell := r.GetCell(pos)
-				cell.SetValue(f.Interface())
-			case reflect.Bool:
-				cell := r.GetCell(pos)
-				cell.SetBool(t.(bool))
-			default:
-				k-- // nothing set so reset to previous
+			return fieldTag{}, ErrInvalidTag
+		}
+		positional++
+	}
+	if ft.number && ft.text {
+		return fieldTag{}, ErrInvalidTag
+	}
+	return ft, nil
+}
+
+// splitTag splits an xlsx struct tag into its comma separated tokens.
+// A token enclosed in single quotes, such as '#,##0.00', is taken
+// literally: it may contain commas, and a single quote within it is
+// written as two. Reports which of the tokens were quoted.
+func splitTag(tag string) ([]string, []bool, error) {
+	var (
+		parts  []string
+		quoted []bool
+	)
+	for {
+		var part string
+		isQuoted := strings.HasPrefix(tag, "'")
+		if isQuoted {
+			var b strings.Builder
+			i := 1
+			for {
+				j := strings.IndexByte(tag[i:], '\'')
+				if j < 0 {
+					return nil, nil, ErrInvalidTag
+				}
+				b.WriteString(tag[i : i+j])
+				i += j + 1
+				if !strings.HasPrefix(tag[i:], "'") {
+					break
+				}
+				b.WriteByte('\'')
+				i++
+			}
+			part, tag = b.String(), tag[i:]
+			if tag != "" && tag[0] != ',' {
+				return nil, nil, ErrInvalidTag
 			}
+		} else if i := strings.IndexByte(tag, ','); i >= 0 {
+			part, tag = tag[:i], tag[i:]
+		} else {
+			part, tag = tag, ""
+		}
+		parts = append(parts, part)
+		quoted = append(quoted, isQuoted)
+		if tag == "" {
+			return parts, quoted, nil
+		}
+		tag = tag[1:] // the comma
+	}
+}
+
+// isEmptyValue reports whether v holds the zero value of its type,
+// counting a zero time.Time and a null type that isn't Valid as empty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Array, reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	case reflect.Struct:
+		if t, ok := v.Interface().(time.Time); ok {
+			return t.IsZero()
+		}
+		if isNullType(v.Type()) {
+			return !v.FieldByName("Valid").Bool()
+		}
+	}
+	return false
+}
+
+// column returns the cell index of the field tagged with 'ft', taking
+// OneBasedColumns into account. A field without a position is only
+// valid with AutoColumns, and is given -1 until its column is
+// assigned.
+func (o *WriterOptions) column(ft fieldTag) (int, error) {
+	if ft.name != "" {
+		return 0, ErrInvalidTag
+	}
+	if ft.auto {
+		if !o.AutoColumns {
+			return 0, ErrInvalidTag
+		}
+		return -1, nil
+	}
+	if !o.OneBasedColumns || ft.letters {
+		return ft.pos, nil
+	}
+	if ft.pos == 0 {
+		return 0, ErrInvalidTag
+	}
+	return ft.pos - 1, nil
+}
+
+// isDigits reports whether s is an optionally signed decimal number.
+func isDigits(s string) bool {
+	_, err := strconv.Atoi(s)
+	return err == nil
+}
+
+// tagOptions are the options of a struct tag that take no value, which
+// can't be taken for the index when it is left out.
+var tagOptions = map[string]bool{
+	"omitempty": true,
+	"json":      true,
+	"trim":      true,
+	"number":    true,
+	"text":      true,
+	"unix":      true,
+	"unixmilli": true,
+	"hyperlink": true,
+}
+
+// parseColumn returns the cell index named by s, which is either a
+// non-negative integer or a column reference such as "AB", and no more
+// than Excel2006MaxColIndex.
+func parseColumn(s string) (int, error) {
+	if pos, err := strconv.Atoi(s); err == nil {
+		if pos < 0 || pos > Excel2006MaxColIndex {
+			return 0, ErrInvalidTag
+		}
+		return pos, nil
+	}
+	if len(s) == 0 || len(s) > 3 {
+		return 0, ErrInvalidTag
+	}
+	for _, c := range s {
+		if !('A' <= c && c <= 'Z' || 'a' <= c && c <= 'z') {
+			return 0, ErrInvalidTag
 		}
 	}
+	pos := ColLettersToIndex(s)
+	if pos > Excel2006MaxColIndex {
+		return 0, ErrInvalidTag
+	}
+	return pos, nil
+}
+
+// namedTimeFormats maps the format names accepted in struct tags to
+// Excel number formats.
+var namedTimeFormats = map[string]string{
+	"date":     DefaultDateFormat,
+	"datetime": DefaultDateTimeFormat,
+	"time":     builtInNumFmt[21],
+}
 
-	return k, nil
+// goLayoutTokens lists the elements of a Go time layout together with
+// their Excel equivalents, longest first so that a prefix never
+// shadows a longer element.
+var goLayoutTokens = []struct {
+	layout, excel string
+}{
+	{"-07:00:00", ""},
+	{"January", "mmmm"},
+	{"-070000", ""},
+	{"Z07:00", ""},
+	{"-07:00", ""},
+	{"Monday", "dddd"},
+	{".000", ".000"},
+	{"2006", "yyyy"},
+	{"Z0700", ""},
+	{"-0700", ""},
+	{"Jan", "mmm"},
+	{"Mon", "ddd"},
+	{"MST", ""},
+	{"Z07", ""},
+	{"-07", ""},
+	{"01", "mm"},
+	{"02", "dd"},
+	{"_2", "d"},
+	{"15", "hh"},
+	{"03", "hh"},
+	{"04", "mm"},
+	{"05", "ss"},
+	{"06", "yy"},
+	{"PM", "AM/PM"},
+	{"pm", "am/pm"},
+	{"1", "m"},
+	{"2", "d"},
+	{"3", "h"},
+	{"4", "m"},
+	{"5", "s"},
+}
+
+// goLayoutRegexp matches the elements of a Go layout that mark a format
+// as one rather than as an Excel format code, which has none of them
+// outside its literals: the digits of the reference time, the names of
+// its month and weekday, and the space padded day.
+var goLayoutRegexp = regexp.MustCompile(`[1-5]|06|_2|Jan|Mon`)
+
+// excelLiteralRegexp matches the literals of an Excel format code, its
+// quoted text and bracketed sections such as [h] and [$-409].
+var excelLiteralRegexp = regexp.MustCompile(`"[^"]*"|\[[^\]]*\]`)
+
+// timeFormat returns the Excel number format for the format token of
+// a struct tag. Named formats are looked up in namedTimeFormats, and a
+// format with any element of the reference time other than its zone,
+// such as "2006-01-02", "3:04PM", "Jan 2" or "Monday", is translated
+// from a Go layout, with its time zone left out as Excel can't show
+// one. Text
+// that isn't part of the layout is copied as is. Anything else is
+// taken to be an Excel format code, such as "h:mm AM/PM", and returned
+// unchanged.
+func timeFormat(format string) string {
+	if excel, ok := namedTimeFormats[format]; ok {
+		return excel
+	}
+	if !goLayoutRegexp.MatchString(excelLiteralRegexp.ReplaceAllString(format, "")) {
+		return format
+	}
+	var b strings.Builder
+	for len(format) > 0 {
+		matched := false
+		for _, tok := range goLayoutTokens {
+			if strings.HasPrefix(format, tok.layout) {
+				b.WriteString(tok.excel)
+				format = format[len(tok.layout):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			b.WriteByte(format[0])
+			format = format[1:]
+		}
+	}
+	return strings.TrimSpace(b.String())
 }
@@ -1,12 +1,21 @@
 package xlsx
 
 import (
+	"bytes"
+	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math"
+	"math/big"
+	"net"
+	"strconv"
 	"testing"
 	"time"
 
 	qt "github.com/frankban/quicktest"
+	"github.com/gobuffalo/nulls"
 )
 
 type testStringerImpl struct {
@@ -17,6 +26,37 @@ func (t testStringerImpl) String() string {
 	return t.Value
 }
 
+type testTextMarshalerImpl struct {
+	Value string
+}
+
+func (t testTextMarshalerImpl) MarshalText() ([]byte, error) {
+	if t.Value == "" {
+		return nil, errors.New("nothing to marshal")
+	}
+	return []byte(t.Value), nil
+}
+
+type testPanicStringer struct{}
+
+func (testPanicStringer) String() string {
+	panic("boom")
+}
+
+// testDecimal stands in for shopspring's decimal.Decimal.
+type testDecimal struct {
+	s string
+}
+
+func (d testDecimal) Float64() (float64, bool) {
+	f, _ := strconv.ParseFloat(d.s, 64)
+	return f, false
+}
+
+func (d testDecimal) String() string {
+	return d.s
+}
+
 func TestWrite(t *testing.T) {
 	c := qt.New(t)
 
@@ -26,21 +66,21 @@ func TestWrite(t *testing.T) {
 		sheet, _ := f.AddSheet("Test1")
 		row := sheet.AddRow()
 		type e struct {
-			FirstName       string
-			Age             int
-			GPA             float64
-			LikesPHP        bool
-			Stringer        testStringerImpl
-			StringerPtr     *testStringerImpl
-			Time            time.Time
-			LastName        sql.NullString
-			HasPhd          sql.NullBool
-			GithubStars     sql.NullInt64
-			Raiting         sql.NullFloat64
-			NullLastName    sql.NullString
-			NullHasPhd      sql.NullBool
-			NullGithubStars sql.NullInt64
-			NullRaiting     sql.NullFloat64
+			FirstName       string            `xlsx:"0"`
+			Age             int               `xlsx:"1"`
+			GPA             float64           `xlsx:"2"`
+			LikesPHP        bool              `xlsx:"3"`
+			Stringer        testStringerImpl  `xlsx:"4"`
+			StringerPtr     *testStringerImpl `xlsx:"5"`
+			Time            time.Time         `xlsx:"6"`
+			LastName        sql.NullString    `xlsx:"7"`
+			HasPhd          sql.NullBool      `xlsx:"8"`
+			GithubStars     sql.NullInt64     `xlsx:"9"`
+			Raiting         sql.NullFloat64   `xlsx:"10"`
+			NullLastName    sql.NullString    `xlsx:"11"`
+			NullHasPhd      sql.NullBool      `xlsx:"12"`
+			NullGithubStars sql.NullInt64     `xlsx:"13"`
+			NullRaiting     sql.NullFloat64   `xlsx:"14"`
 		}
 		testStruct := e{
 			"Eric",
@@ -60,7 +100,7 @@ func TestWrite(t *testing.T) {
 			sql.NullFloat64{Float64: 0.123, Valid: false},
 		}
 		cnt, err := row.WriteStruct(&testStruct, -1)
-		c.Error(err)
+		c.Assert(err, qt.IsNil)
 		c.Assert(cnt, qt.Equals, 15)
 		c.Assert(row, qt.Not(qt.IsNil))
 
@@ -281,4 +321,1669 @@ func TestWrite(t *testing.T) {
 		c.Assert(e11Null, qt.Equals, nil)
 		c.Assert(c11Null, qt.Equals, "")
 	})
+
+	csRunO(c, "TestWriteStructTimeFormat", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		type e struct {
+			Default  time.Time `xlsx:"0"`
+			Date     time.Time `xlsx:"1,date"`
+			DateTime time.Time `xlsx:"2,datetime"`
+			Layout   time.Time `xlsx:"3,2006-01-02 15:04"`
+			Excel    time.Time `xlsx:"4,dd/mm/yyyy"`
+			AMPM     time.Time `xlsx:"5,h:mm AM/PM"`
+			Kitchen  time.Time `xlsx:"6,3:04PM"`
+			Zone     time.Time `xlsx:"7,2006-01-02 15:04 MST"`
+			Short    time.Time `xlsx:"8,Jan 2"`
+			Unpadded time.Time `xlsx:"9,3:4pm"`
+			Weekday  time.Time `xlsx:"10,Monday"`
+			Locale   time.Time `xlsx:"11,[$-409]h:mm AM/PM"`
+		}
+		tm := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+		testStruct := e{tm, tm, tm, tm, tm, tm, tm, tm, tm, tm, tm, tm}
+		cnt, err := row.WriteStruct(&testStruct, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 12)
+
+		c.Assert(row.GetCell(0).NumFmt, qt.Equals, DefaultDateTimeFormat)
+		c.Assert(row.GetCell(1).NumFmt, qt.Equals, DefaultDateFormat)
+		c.Assert(row.GetCell(2).NumFmt, qt.Equals, DefaultDateTimeFormat)
+		c.Assert(row.GetCell(3).NumFmt, qt.Equals, "yyyy-mm-dd hh:mm")
+		c.Assert(row.GetCell(4).NumFmt, qt.Equals, "dd/mm/yyyy")
+		c.Assert(row.GetCell(5).NumFmt, qt.Equals, "h:mm AM/PM")
+		c.Assert(row.GetCell(6).NumFmt, qt.Equals, "h:mmAM/PM")
+		c.Assert(row.GetCell(7).NumFmt, qt.Equals, "yyyy-mm-dd hh:mm")
+		c.Assert(row.GetCell(8).NumFmt, qt.Equals, "mmm d")
+		c.Assert(row.GetCell(9).NumFmt, qt.Equals, "h:mam/pm")
+		c.Assert(row.GetCell(10).NumFmt, qt.Equals, "dddd")
+		c.Assert(row.GetCell(11).NumFmt, qt.Equals, "[$-409]h:mm AM/PM")
+
+		val, err := row.GetCell(3).FormattedValue()
+		c.Assert(err, qt.IsNil)
+		c.Assert(val, qt.Equals, "2020-03-04 05:06")
+	})
+
+	csRunO(c, "TestWriteMap", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		m := map[string]interface{}{
+			"name":    "Eric",
+			"age":     20,
+			"gpa":     3.94,
+			"phd":     sql.NullBool{Bool: true, Valid: true},
+			"nothing": nil,
+		}
+		headers := []string{"name", "missing", "age", "gpa", "phd", "nothing"}
+		c.Assert(row.WriteMap(m, headers), qt.Equals, 6)
+
+		name, err := row.GetCell(0).FormattedValue()
+		c.Assert(err, qt.IsNil)
+		c.Assert(name, qt.Equals, "Eric")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "")
+		age, err := row.GetCell(2).Int()
+		c.Assert(err, qt.IsNil)
+		c.Assert(age, qt.Equals, 20)
+		gpa, err := row.GetCell(3).Float()
+		c.Assert(err, qt.IsNil)
+		c.Assert(gpa, qt.Equals, 3.94)
+		c.Assert(row.GetCell(4).Bool(), qt.Equals, true)
+		c.Assert(row.GetCell(5).Value, qt.Equals, "")
+	})
+
+	csRunO(c, "TestWriteStructNested", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		type address struct {
+			Street string `xlsx:"1"`
+			City   string `xlsx:"2"`
+		}
+		type contact struct {
+			Phone string `xlsx:"3"`
+		}
+		type e struct {
+			Name    string `xlsx:"0"`
+			Address address
+			Contact contact
+			private address
+		}
+		testStruct := e{
+			Name:    "Eric",
+			Address: address{"Main Street", "Springfield"},
+			Contact: contact{"555-1234"},
+			private: address{"Hidden", "Hidden"},
+		}
+		cnt, err := row.WriteStruct(&testStruct, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 4)
+
+		var got []string
+		row.ForEachCell(func(cell *Cell) error {
+			val, err := cell.FormattedValue()
+			got = append(got, val)
+			return err
+		})
+		c.Assert(got, qt.DeepEquals, []string{"Eric", "Main Street", "Springfield", "555-1234"})
+	})
+
+	csRunO(c, "TestWriteStructPointers", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		type e struct {
+			Name     *string           `xlsx:"0"`
+			Age      *int              `xlsx:"1"`
+			Born     *time.Time        `xlsx:"2,2006-01-02"`
+			Stringer *testStringerImpl `xlsx:"3"`
+			Nil      *float64          `xlsx:"4"`
+			NilTime  *time.Time        `xlsx:"5,date"`
+		}
+		name := "Eric"
+		age := 20
+		born := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+		testStruct := e{
+			Name:     &name,
+			Age:      &age,
+			Born:     &born,
+			Stringer: &testStringerImpl{"Stringer"},
+		}
+		cnt, err := row.WriteStruct(&testStruct, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 6)
+
+		var got []string
+		row.ForEachCell(func(cell *Cell) error {
+			val, err := cell.FormattedValue()
+			got = append(got, val)
+			return err
+		})
+		c.Assert(got, qt.DeepEquals, []string{"Eric", "20", "2000-01-02", "Stringer", "", ""})
+	})
+
+	csRunO(c, "TestWriteUint", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		type e struct {
+			ID    uint64 `xlsx:"0"`
+			Big   uint64 `xlsx:"1"`
+			Small uint8  `xlsx:"2"`
+		}
+		testStruct := e{42, math.MaxUint64, 7}
+		cnt, err := row.WriteStruct(&testStruct, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 3)
+		c.Assert(row.GetCell(0).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "42")
+		c.Assert(row.GetCell(1).Type(), qt.Equals, CellTypeString)
+		c.Assert(row.GetCell(1).Value, qt.Equals, "18446744073709551615")
+		c.Assert(row.GetCell(2).Value, qt.Equals, "7")
+
+		ids := []uint{1, 2}
+		row = sheet.AddRow()
+		c.Assert(row.WriteSlice(&ids, -1), qt.Equals, 2)
+		c.Assert(row.GetCell(1).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(row.GetCell(1).Value, qt.Equals, "2")
+	})
+
+	csRunO(c, "TestWriteStructColumnLetters", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		type e struct {
+			First  string `xlsx:"A"`
+			Second string `xlsx:"1"`
+			Fourth string `xlsx:"d"`
+			Late   string `xlsx:"AA"`
+		}
+		testStruct := e{"a", "b", "d", "aa"}
+		cnt, err := row.WriteStruct(&testStruct, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 4)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "a")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "b")
+		c.Assert(row.GetCell(3).Value, qt.Equals, "d")
+		c.Assert(row.GetCell(26).Value, qt.Equals, "aa")
+
+		type bad struct {
+			Value string `xlsx:"A1"`
+		}
+		_, err = sheet.AddRow().WriteStruct(&bad{"x"}, -1)
+		c.Assert(errors.Is(err, ErrInvalidTag), qt.Equals, true)
+		c.Assert(err, qt.ErrorMatches, "xlsx: invalid tag on field Value: .*")
+
+		// An option with the index left out, or a column past the last
+		// one Excel has, is no column.
+		type forgotten struct {
+			Value string `xlsx:"omitempty"`
+		}
+		type letters struct {
+			Value string `xlsx:"XFE"`
+		}
+		type digits struct {
+			Value string `xlsx:"16384"`
+		}
+		for _, v := range []interface{}{&forgotten{"a"}, &letters{"a"}, &digits{"a"}} {
+			_, err = sheet.AddRow().WriteStruct(v, -1)
+			c.Assert(errors.Is(err, ErrInvalidTag), qt.Equals, true, qt.Commentf("%T", v))
+		}
+		type last struct {
+			A string `xlsx:"XFD"`
+			B string `xlsx:"16383"`
+		}
+		_, err = sheet.AddRow().WriteStruct(&last{"a", "b"}, -1)
+		c.Assert(err, qt.IsNil)
+	})
+
+	csRunO(c, "TestWriteStructHeader", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		type e struct {
+			Name    string    `xlsx:"0,,Full Name"`
+			Age     int       `xlsx:"2"`
+			Born    time.Time `xlsx:"3,date,Date of Birth"`
+			Ignored string    `xlsx:"-"`
+		}
+		row := sheet.AddRow()
+		cnt, err := row.WriteStructHeader(e{})
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 3)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "Full Name")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "")
+		c.Assert(row.GetCell(2).Value, qt.Equals, "Age")
+		c.Assert(row.GetCell(3).Value, qt.Equals, "Date of Birth")
+
+		born := time.Date(2000, 1, 2, 0, 0, 0, 0, time.UTC)
+		row = sheet.AddRow()
+		_, err = row.WriteStruct(&e{Name: "Eric", Age: 20, Born: born}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "Eric")
+		c.Assert(row.GetCell(3).NumFmt, qt.Equals, DefaultDateFormat)
+
+		_, err = row.WriteStructHeader(42)
+		c.Assert(err, qt.Equals, ErrNotStructPointer)
+	})
+
+	csRunO(c, "TestWriteSliceAt", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		row.AddCell().SetString("first")
+		row.AddCell().SetString("second")
+		row.AddCell().SetString("third")
+
+		s := []string{"a", "b", "c"}
+		c.Assert(row.WriteSliceAt(&s, 2, -1), qt.Equals, 3)
+		c.Assert(row.WriteSliceAt(&s, -1, 1), qt.Equals, 1)
+		c.Assert(row.WriteSliceAt(s, 0, -1), qt.Equals, -1)
+		row.AddCell().SetString("last")
+
+		var got []string
+		row.ForEachCell(func(cell *Cell) error {
+			got = append(got, cell.Value)
+			return nil
+		})
+		c.Assert(got, qt.DeepEquals, []string{"a", "second", "a", "b", "c", "last"})
+	})
+
+	csRunO(c, "TestWriteTextMarshaler", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		type e struct {
+			IP   net.IP                `xlsx:"0"`
+			Text testTextMarshalerImpl `xlsx:"1"`
+		}
+		row := sheet.AddRow()
+		cnt, err := row.WriteStruct(&e{net.IPv4(10, 0, 0, 1), testTextMarshalerImpl{"text"}}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 2)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "10.0.0.1")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "text")
+
+		_, err = sheet.AddRow().WriteStruct(&e{}, -1)
+		c.Assert(err, qt.ErrorMatches, "nothing to marshal")
+
+		s := []testTextMarshalerImpl{{""}, {"ok"}}
+		row = sheet.AddRow()
+		c.Assert(row.WriteSlice(&s, -1), qt.Equals, 2)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "ok")
+	})
+
+	csRunO(c, "TestWriteJSONNumber", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		type e struct {
+			Count json.Number `xlsx:"0"`
+			Bad   json.Number `xlsx:"1"`
+		}
+		row := sheet.AddRow()
+		_, err := row.WriteStruct(&e{"42.5", "n/a"}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(row.GetCell(0).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "42.5")
+		c.Assert(row.GetCell(1).Type(), qt.Equals, CellTypeString)
+		c.Assert(row.GetCell(1).Value, qt.Equals, "n/a")
+
+		s := []interface{}{json.Number("7")}
+		row = sheet.AddRow()
+		row.WriteSlice(&s, -1)
+		c.Assert(row.GetCell(0).Type(), qt.Equals, CellTypeNumeric)
+	})
+
+	csRunO(c, "TestWriteStructOmitEmpty", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		type e struct {
+			Name  string         `xlsx:"0,omitempty"`
+			Age   int            `xlsx:"1,omitempty"`
+			Born  time.Time      `xlsx:"2,date,Born,omitempty"`
+			Phd   bool           `xlsx:"3,omitempty"`
+			Last  sql.NullString `xlsx:"4,omitempty"`
+			Stars nulls.Int      `xlsx:"5,omitempty"`
+			Plain string         `xlsx:"6"`
+		}
+		row := sheet.AddRow()
+		for i := 0; i < 7; i++ {
+			row.GetCell(i).SetString("template")
+		}
+		cnt, err := row.WriteStruct(&e{Age: 3}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 2)
+
+		var got []string
+		row.ForEachCell(func(cell *Cell) error {
+			got = append(got, cell.Value)
+			return nil
+		})
+		c.Assert(got, qt.DeepEquals, []string{"template", "3", "template", "template", "template", "template", ""})
+
+		header := sheet.AddRow()
+		header.WriteStructHeader(e{})
+		c.Assert(header.GetCell(2).Value, qt.Equals, "Born")
+	})
+
+	csRunO(c, "TestWriteStructs", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		type e struct {
+			Name string `xlsx:"0"`
+			Age  int    `xlsx:"1"`
+		}
+		records := []e{{"Eric", 20}, {"Anna", 30}}
+		cnt, err := sheet.WriteStructs(records)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 2)
+
+		ptrs := []*e{{"Paul", 40}, nil}
+		cnt, err = sheet.WriteStructs(&ptrs)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 2)
+
+		cnt, err = sheet.WriteStructs([]e{})
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 0)
+
+		_, err = sheet.WriteStructs([]int{1})
+		c.Assert(err, qt.Equals, ErrNotStructPointer)
+
+		c.Assert(sheet.MaxRow, qt.Equals, 4)
+		var got []string
+		err = sheet.ForEachRow(func(r *Row) error {
+			return r.ForEachCell(func(cell *Cell) error {
+				got = append(got, cell.Value)
+				return nil
+			})
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.DeepEquals, []string{"Eric", "20", "Anna", "30", "Paul", "40"})
+	})
+
+	csRunO(c, "TestWriteStructsContext", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		type e struct {
+			Value int `xlsx:"0"`
+		}
+		records := make([]e, writeStructsCheckInterval+1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cnt, err := sheet.WriteStructsContext(ctx, records)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, len(records))
+
+		cancel()
+		cnt, err = sheet.WriteStructsContext(ctx, records)
+		c.Assert(err, qt.Equals, context.Canceled)
+		c.Assert(cnt, qt.Equals, 0)
+	})
+
+	csRunO(c, "TestWriteStructsPaged", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		type e struct {
+			Name string `xlsx:"0,,Full Name"`
+			Age  int    `xlsx:"1"`
+		}
+		records := []*e{{"Eric", 20}, {"Anna", 30}, nil, {"Paul", 40}, {"Mary", 50}}
+
+		sheets, err := f.WriteStructsPaged("People", records, 2)
+		c.Assert(err, qt.IsNil)
+		c.Assert(sheets, qt.HasLen, 3)
+		var got [][]string
+		for i, sheet := range sheets {
+			c.Assert(sheet.Name, qt.Equals, "People"+strconv.Itoa(i+1))
+			c.Assert(f.Sheets[i], qt.Equals, sheet)
+			err := sheet.ForEachRow(func(r *Row) error {
+				var values []string
+				err := r.ForEachCell(func(cell *Cell) error {
+					values = append(values, cell.Value)
+					return nil
+				})
+				got = append(got, values)
+				return err
+			})
+			c.Assert(err, qt.IsNil)
+		}
+		c.Assert(got, qt.DeepEquals, [][]string{
+			{"Full Name", "Age"}, {"Eric", "20"}, {"Anna", "30"},
+			{"Full Name", "Age"}, nil, {"Paul", "40"},
+			{"Full Name", "Age"}, {"Mary", "50"},
+		})
+
+		sheets, err = f.WriteStructsPaged("Empty", []e{}, 2)
+		c.Assert(err, qt.IsNil)
+		c.Assert(sheets, qt.HasLen, 1)
+		c.Assert(sheets[0].MaxRow, qt.Equals, 1)
+
+		_, err = f.WriteStructsPaged("Bad", records, 0)
+		c.Assert(err, qt.ErrorMatches, "rows per sheet must be between 1 and 1048575, not 0")
+		_, err = f.WriteStructsPaged("Bad", []int{1}, 2)
+		c.Assert(err, qt.Equals, ErrNotStructPointer)
+		sheets, err = f.WriteStructsPaged("People", records, 2)
+		c.Assert(err, qt.Not(qt.IsNil))
+		c.Assert(sheets, qt.HasLen, 0)
+	})
+
+	csRunO(c, "TestWriteNullAndEmptyString", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		type e struct {
+			Null  sql.NullString `xlsx:"0"`
+			Empty sql.NullString `xlsx:"1"`
+			Plain string         `xlsx:"2"`
+		}
+		val := e{Empty: sql.NullString{Valid: true}}
+		_, err := sheet.AddRow().WriteStruct(&val, -1)
+		c.Assert(err, qt.IsNil)
+
+		sw, err := NewStructWriterWithOptions(e{}, WriterOptions{NullString: "NULL"})
+		c.Assert(err, qt.IsNil)
+		_, err = sw.Write(sheet.AddRow(), &val)
+		c.Assert(err, qt.IsNil)
+
+		sw, err = NewStructWriterWithOptions(e{}, WriterOptions{BlankEmptyStrings: true})
+		c.Assert(err, qt.IsNil)
+		_, err = sw.Write(sheet.AddRow(), &val)
+		c.Assert(err, qt.IsNil)
+
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		xml := parts["xl/worksheets/sheet1.xml"]
+		c.Assert(xml, qt.Contains, `<c r="A1"></c><c r="B1" t="inlineStr"><is><t></t></is></c><c r="C1" t="inlineStr"><is><t></t></is></c>`)
+		c.Assert(xml, qt.Contains, `<c r="A2" t="s"><v>0</v></c><c r="B2" t="inlineStr"><is><t></t></is></c>`)
+		c.Assert(xml, qt.Contains, `<c r="A3"></c><c r="B3"></c><c r="C3"></c>`)
+	})
+
+	csRunO(c, "TestWriteBigNumbers", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+		type e struct {
+			Small    *big.Int `xlsx:"0"`
+			Huge     *big.Int `xlsx:"1"`
+			Half     *big.Rat `xlsx:"2"`
+			Third    *big.Rat `xlsx:"3"`
+			Nil      *big.Int `xlsx:"4"`
+			ValueInt big.Int  `xlsx:"5"`
+		}
+		val := e{
+			Small:    big.NewInt(42),
+			Huge:     huge,
+			Half:     big.NewRat(-1, 2),
+			Third:    big.NewRat(1, 3),
+			ValueInt: *big.NewInt(7),
+		}
+		row := sheet.AddRow()
+		cnt, err := row.WriteStruct(&val, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 6)
+
+		c.Assert(row.GetCell(0).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "42")
+		c.Assert(row.GetCell(1).Type(), qt.Equals, CellTypeString)
+		c.Assert(row.GetCell(1).Value, qt.Equals, "123456789012345678901234567890")
+		c.Assert(row.GetCell(2).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(row.GetCell(2).Value, qt.Equals, "-0.5")
+		c.Assert(row.GetCell(3).Value, qt.Equals, "0.3333333333")
+		c.Assert(row.GetCell(4).Value, qt.Equals, "")
+		c.Assert(row.GetCell(5).Value, qt.Equals, "7")
+
+		sw, err := NewStructWriterWithOptions(e{}, WriterOptions{RatPrecision: 2})
+		c.Assert(err, qt.IsNil)
+		row = sheet.AddRow()
+		_, err = sw.Write(row, &val)
+		c.Assert(err, qt.IsNil)
+		c.Assert(row.GetCell(3).Value, qt.Equals, "0.33")
+	})
+
+	csRunO(c, "TestWriteStructSliceField", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		type e struct {
+			Name  string   `xlsx:"0"`
+			Tags  []string `xlsx:"1"`
+			Score []int    `xlsx:"4"`
+			Last  string   `xlsx:"5"`
+		}
+		row := sheet.AddRow()
+		cnt, err := row.WriteStruct(&e{"Eric", []string{"a", "b", "c"}, nil, "end"}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 5)
+
+		var got []string
+		row.ForEachCell(func(cell *Cell) error {
+			got = append(got, cell.Value)
+			return nil
+		})
+		c.Assert(got, qt.DeepEquals, []string{"Eric", "a", "b", "c", "end"})
+
+		row = sheet.AddRow()
+		cnt, err = row.WriteStruct(&e{"Anna", nil, []int{1, 2}, "end"}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 4)
+		c.Assert(row.GetCell(4).Value, qt.Equals, "1")
+		c.Assert(row.GetCell(5).Value, qt.Equals, "end")
+	})
+
+	csRunO(c, "TestWriteArray", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+
+		values := [3]int{1, 2, 3}
+		row := sheet.AddRow()
+		c.Assert(row.WriteSlice(&values, -1), qt.Equals, 3)
+		c.Assert(row.WriteSlice(&values, 2), qt.Equals, 2)
+		c.Assert(row.WriteSlice(values, -1), qt.Equals, -1)
+		var got []string
+		row.ForEachCell(func(cell *Cell) error {
+			got = append(got, cell.Value)
+			return nil
+		})
+		c.Assert(got, qt.DeepEquals, []string{"1", "2", "3", "1", "2"})
+
+		mixed := [2]interface{}{"Eric", nil}
+		n, err := sheet.AddRow().WriteSliceE(&mixed, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 2)
+
+		type e struct {
+			Name   string     `xlsx:"0"`
+			Scores [3]float64 `xlsx:"1"`
+			Last   string     `xlsx:"4"`
+		}
+		row = sheet.AddRow()
+		cnt, err := row.WriteStruct(&e{"Anna", [3]float64{1.5, 2, 0}, "end"}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 5)
+		c.Assert(row.GetCell(1).Value, qt.Equals, "1.5")
+		c.Assert(row.GetCell(3).Value, qt.Equals, "0")
+		c.Assert(row.GetCell(4).Value, qt.Equals, "end")
+	})
+
+	csRunO(c, "TestWriteMatrix", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+
+		n, err := sheet.WriteMatrix([][]interface{}{
+			{"Name", "Age", "Member"},
+			{"Eric", 20, true},
+			{},
+			{"Anna", 1.5, nil, time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)},
+		})
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 4)
+		c.Assert(sheet.MaxRow, qt.Equals, 4)
+		var got [][]string
+		sheet.ForEachRow(func(row *Row) error {
+			var values []string
+			row.ForEachCell(func(cell *Cell) error {
+				values = append(values, cell.Value)
+				return nil
+			})
+			got = append(got, values)
+			return nil
+		})
+		c.Assert(got, qt.DeepEquals, [][]string{
+			{"Name", "Age", "Member"},
+			{"Eric", "20", "1"},
+			nil,
+			{"Anna", "1.5", "", "43891"},
+		})
+
+		n, err = sheet.WriteMatrix([][]interface{}{
+			{"ok"},
+			{"bad", make(chan int)},
+		})
+		c.Assert(err, qt.ErrorMatches, `row 1: xlsx: element 1 of type chan int: .*`)
+		c.Assert(n, qt.Equals, 1)
+		c.Assert(sheet.MaxRow, qt.Equals, 6)
+	})
+
+	csRunO(c, "TestWriteDuration", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		type e struct {
+			Worked   time.Duration `xlsx:"0"`
+			Overtime time.Duration `xlsx:"1"`
+		}
+		val := e{2*time.Hour + 30*time.Minute, -90 * time.Minute}
+		row := sheet.AddRow()
+		_, err := row.WriteStruct(&val, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(row.GetCell(0).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(row.GetCell(0).NumFmt, qt.Equals, durationFormat)
+		days, err := row.GetCell(0).Float()
+		c.Assert(err, qt.IsNil)
+		c.Assert(days, qt.Equals, 2.5/24)
+		days, err = row.GetCell(1).Float()
+		c.Assert(err, qt.IsNil)
+		c.Assert(days, qt.Equals, -1.5/24)
+
+		sw, err := NewStructWriterWithOptions(e{}, WriterOptions{DurationAsText: true})
+		c.Assert(err, qt.IsNil)
+		row = sheet.AddRow()
+		_, err = sw.Write(row, &val)
+		c.Assert(err, qt.IsNil)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "2h30m0s")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "-1h30m0s")
+	})
+
+	csRunO(c, "TestWriteStructDefault", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		type e struct {
+			Name  string         `xlsx:"0,default=N/A"`
+			Count int            `xlsx:"1,default=0"`
+			Last  sql.NullString `xlsx:"2,default=unknown"`
+			Note  string         `xlsx:"3,default="`
+			Plain int            `xlsx:"4"`
+		}
+		row := sheet.AddRow()
+		cnt, err := row.WriteStruct(&e{}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 5)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "N/A")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "0")
+		c.Assert(row.GetCell(1).Type(), qt.Equals, CellTypeString)
+		c.Assert(row.GetCell(2).Value, qt.Equals, "unknown")
+		c.Assert(row.GetCell(3).Type(), qt.Equals, CellTypeInline)
+		c.Assert(row.GetCell(4).Type(), qt.Equals, CellTypeNumeric)
+
+		row = sheet.AddRow()
+		_, err = row.WriteStruct(&e{Name: "Eric", Count: 3}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "Eric")
+		c.Assert(row.GetCell(1).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(row.GetCell(1).Value, qt.Equals, "3")
+	})
+
+	csRunO(c, "TestWriteByteAndRuneSlices", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		type e struct {
+			Bytes  []byte          `xlsx:"0"`
+			Runes  []rune          `xlsx:"1"`
+			Binary []byte          `xlsx:"2"`
+			Raw    json.RawMessage `xlsx:"3"`
+			Nil    []byte          `xlsx:"4"`
+		}
+		val := e{
+			Bytes:  []byte("héllo"),
+			Runes:  []rune("wörld"),
+			Binary: []byte{0xff, 0xfe},
+			Raw:    json.RawMessage(`{"a":1}`),
+		}
+		row := sheet.AddRow()
+		cnt, err := row.WriteStruct(&val, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 5)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "héllo")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "wörld")
+		c.Assert(row.GetCell(2).Value, qt.Equals, "//4=")
+		c.Assert(row.GetCell(3).Value, qt.Equals, `{"a":1}`)
+		c.Assert(row.GetCell(4).Type(), qt.Equals, CellTypeString)
+		c.Assert(row.GetCell(4).Value, qt.Equals, "")
+
+		s := [][]byte{[]byte("one"), []byte("two")}
+		row = sheet.AddRow()
+		c.Assert(row.WriteSlice(&s, -1), qt.Equals, 2)
+		c.Assert(row.GetCell(1).Value, qt.Equals, "two")
+	})
+
+	csRunO(c, "TestWriteStructInterfaceFields", func(c *qt.C, option FileOption) {
+		type e struct {
+			Text  interface{} `xlsx:"0"`
+			Num   interface{} `xlsx:"1"`
+			When  interface{} `xlsx:"2"`
+			Null  interface{} `xlsx:"3"`
+			Str   interface{} `xlsx:"4"`
+			Valid interface{} `xlsx:"5"`
+			Empty interface{} `xlsx:"6"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		when := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+		n, err := row.WriteStruct(&e{
+			Text:  "hello",
+			Num:   42,
+			When:  when,
+			Null:  sql.NullString{},
+			Str:   net.IPv4(10, 0, 0, 1),
+			Valid: nulls.NewInt64(7),
+		}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 7)
+
+		c.Assert(row.GetCell(0).Value, qt.Equals, "hello")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "42")
+		c.Assert(row.GetCell(1).Type(), qt.Equals, CellTypeNumeric)
+		got, err := row.GetCell(2).GetTime(false)
+		c.Assert(err, qt.IsNil)
+		c.Assert(got, qt.Equals, when)
+		c.Assert(row.GetCell(3).Value, qt.Equals, "")
+		c.Assert(row.GetCell(4).Value, qt.Equals, "10.0.0.1")
+		c.Assert(row.GetCell(5).Value, qt.Equals, "7")
+		c.Assert(row.GetCell(6).Value, qt.Equals, "")
+
+		slice := []interface{}{"a", nil, 3}
+		row = sheet.AddRow()
+		c.Assert(row.WriteSlice(&slice, -1), qt.Equals, 3)
+		c.Assert(row.GetCell(1).Value, qt.Equals, "")
+		c.Assert(row.GetCell(2).Value, qt.Equals, "3")
+	})
+
+	csRunO(c, "TestWriteDecimal", func(c *qt.C, option FileOption) {
+		type invoice struct {
+			Amount testDecimal  `xlsx:"0"`
+			Huge   testDecimal  `xlsx:"1"`
+			Tax    *testDecimal `xlsx:"2"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		sw, err := NewStructWriterWithOptions(invoice{}, WriterOptions{DecimalFormat: "0.00"})
+		c.Assert(err, qt.IsNil)
+		_, err = sw.Write(row, invoice{
+			Amount: testDecimal{"1234.5"},
+			Huge:   testDecimal{"12345678901234567890.12"},
+			Tax:    &testDecimal{"0.1"},
+		})
+		c.Assert(err, qt.IsNil)
+
+		amount := row.GetCell(0)
+		c.Assert(amount.Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(amount.Value, qt.Equals, "1234.5")
+		c.Assert(amount.NumFmt, qt.Equals, "0.00")
+		huge := row.GetCell(1)
+		c.Assert(huge.Type(), qt.Equals, CellTypeString)
+		c.Assert(huge.Value, qt.Equals, "12345678901234567890.12")
+		c.Assert(huge.NumFmt, qt.Equals, "")
+		c.Assert(row.GetCell(2).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(row.GetCell(2).Value, qt.Equals, "0.1")
+
+		row = sheet.AddRow()
+		_, err = row.WriteStruct(&invoice{Amount: testDecimal{"2"}}, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(row.GetCell(0).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(row.GetCell(0).NumFmt, qt.Equals, "general")
+	})
+
+	csRunO(c, "TestSetSlice", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+
+		first := []interface{}{"a", "b", "c"}
+		c.Assert(row.SetSlice(&first, -1), qt.Equals, 3)
+		second := []interface{}{"x", 2}
+		c.Assert(row.SetSlice(&second, -1), qt.Equals, 2)
+
+		var values []string
+		row.ForEachCell(func(cell *Cell) error {
+			values = append(values, cell.Value)
+			return nil
+		})
+		c.Assert(values, qt.DeepEquals, []string{"x", "2", "c"})
+
+		c.Assert(row.SetSlice(&first, 1), qt.Equals, 1)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "a")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "2")
+		c.Assert(row.SetSlice(first, -1), qt.Equals, -1)
+	})
+
+	csRunO(c, "TestWriteStructEmbedded", func(c *qt.C, option FileOption) {
+		type audit struct {
+			CreatedBy string `xlsx:"2"`
+		}
+		type Base struct {
+			ID int `xlsx:"0"`
+		}
+		type model struct {
+			Base
+			*audit
+			Name string `xlsx:"1"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		n, err := row.WriteStruct(&model{Base{7}, &audit{"eric"}, "widget"}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 3)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "7")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "widget")
+		c.Assert(row.GetCell(2).Value, qt.Equals, "eric")
+
+		row = sheet.AddRow()
+		n, err = row.WriteStruct(&model{Base: Base{8}, Name: "gadget"}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 2)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "8")
+		c.Assert(row.GetCell(2).Value, qt.Equals, "")
+	})
+
+	csRunO(c, "TestWriteBoolFormat", func(c *qt.C, option FileOption) {
+		type flags struct {
+			Active  bool          `xlsx:"0"`
+			Deleted bool          `xlsx:"1"`
+			Checked sql.NullBool  `xlsx:"2"`
+			Missing nulls.Bool    `xlsx:"3"`
+			Ptr     *bool         `xlsx:"4"`
+			Any     []interface{} `xlsx:"5"`
+		}
+		yes := true
+		value := flags{
+			Active:  true,
+			Checked: sql.NullBool{Bool: false, Valid: true},
+			Ptr:     &yes,
+			Any:     []interface{}{false},
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+
+		cases := []struct {
+			options WriterOptions
+			values  []string
+			typ     CellType
+		}{
+			{WriterOptions{}, []string{"1", "0", "0", "", "1", "0"}, CellTypeBool},
+			{WriterOptions{BoolFormat: BoolAsNumber}, []string{"1", "0", "0", "", "1", "0"}, CellTypeNumeric},
+			{WriterOptions{BoolFormat: BoolAsText}, []string{"Yes", "No", "No", "", "Yes", "No"}, CellTypeString},
+			{WriterOptions{BoolFormat: BoolAsText, TrueText: "Ja", FalseText: "Nein"}, []string{"Ja", "Nein", "Nein", "", "Ja", "Nein"}, CellTypeString},
+		}
+		for _, tc := range cases {
+			sw, err := NewStructWriterWithOptions(flags{}, tc.options)
+			c.Assert(err, qt.IsNil)
+			row := sheet.AddRow()
+			_, err = sw.Write(row, value)
+			c.Assert(err, qt.IsNil)
+			for i, want := range tc.values {
+				c.Assert(row.GetCell(i).Value, qt.Equals, want, qt.Commentf("options %+v, cell %d", tc.options, i))
+				if want != "" {
+					c.Assert(row.GetCell(i).Type(), qt.Equals, tc.typ)
+				}
+			}
+		}
+	})
+
+	csRunO(c, "TestWriteZeroTime", func(c *qt.C, option FileOption) {
+		type event struct {
+			At      time.Time  `xlsx:"0"`
+			Day     time.Time  `xlsx:"1,date"`
+			Ptr     *time.Time `xlsx:"2"`
+			Skipped time.Time  `xlsx:"3,omitempty"`
+			Missing time.Time  `xlsx:"4,default=never"`
+		}
+		var zero time.Time
+		value := event{Ptr: &zero}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+
+		row := sheet.AddRow()
+		row.GetCell(3).SetString("kept")
+		_, err := row.WriteStruct(&value, -1)
+		c.Assert(err, qt.IsNil)
+		for i := 0; i < 3; i++ {
+			c.Assert(row.GetCell(i).Value, qt.Equals, "")
+		}
+		c.Assert(row.GetCell(3).Value, qt.Equals, "kept")
+		c.Assert(row.GetCell(4).Value, qt.Equals, "never")
+
+		times := []interface{}{zero}
+		row = sheet.AddRow()
+		c.Assert(row.WriteSlice(&times, -1), qt.Equals, 1)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "")
+
+		sw, err := NewStructWriterWithOptions(event{}, WriterOptions{ZeroTimeAsDate: true})
+		c.Assert(err, qt.IsNil)
+		row = sheet.AddRow()
+		_, err = sw.Write(row, value)
+		c.Assert(err, qt.IsNil)
+		for i := 0; i < 3; i++ {
+			c.Assert(row.GetCell(i).Value, qt.Not(qt.Equals), "")
+		}
+		c.Assert(row.GetCell(4).Value, qt.Equals, "never")
+	})
+
+	csRunO(c, "TestWriteStructJSON", func(c *qt.C, option FileOption) {
+		type point struct {
+			X, Y int
+		}
+		type e struct {
+			Attrs map[string]int `xlsx:"0,json"`
+			At    point          `xlsx:"1,json"`
+			Tags  []string       `xlsx:"2,json,,Labels"`
+			None  map[string]int `xlsx:"3,json"`
+			Bad   chan int       `xlsx:"4,json"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		_, err := row.WriteStruct(&e{
+			Attrs: map[string]int{"b": 2, "a": 1},
+			At:    point{1, 2},
+			Tags:  []string{"x", "y"},
+		}, 4)
+		c.Assert(err, qt.IsNil)
+		c.Assert(row.GetCell(0).Value, qt.Equals, `{"a":1,"b":2}`)
+		c.Assert(row.GetCell(1).Value, qt.Equals, `{"X":1,"Y":2}`)
+		c.Assert(row.GetCell(2).Value, qt.Equals, `["x","y"]`)
+		c.Assert(row.GetCell(3).Value, qt.Equals, "")
+
+		header := sheet.AddRow()
+		_, err = header.WriteStructHeader(e{})
+		c.Assert(err, qt.IsNil)
+		c.Assert(header.GetCell(2).Value, qt.Equals, "Labels")
+
+		_, err = sheet.AddRow().WriteStruct(&e{Bad: make(chan int)}, -1)
+		c.Assert(err, qt.ErrorMatches, "json: unsupported type: chan int")
+	})
+
+	csRunO(c, "TestWriteStructSkipsUnexported", func(c *qt.C, option FileOption) {
+		type e struct {
+			Name    string `xlsx:"0"`
+			dirty   bool
+			counter int `xlsx:"1"`
+			Age     int `xlsx:"2"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		n, err := row.WriteStruct(&e{Name: "Eric", dirty: true, counter: 3, Age: 20}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 2)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "Eric")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "")
+		c.Assert(row.GetCell(2).Value, qt.Equals, "20")
+
+		type invalid struct {
+			Name  string `xlsx:"0"`
+			Other string
+		}
+		_, err = sheet.AddRow().WriteStruct(&invalid{}, -1)
+		c.Assert(errors.Is(err, ErrInvalidTag), qt.Equals, true)
+	})
+
+	csRunO(c, "TestWriteNilStringer", func(c *qt.C, option FileOption) {
+		type e struct {
+			Buf      *bytes.Buffer `xlsx:"0"`
+			Stringer fmt.Stringer  `xlsx:"1"`
+			Marshal  interface{}   `xlsx:"2"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		value := e{
+			Stringer: (*bytes.Buffer)(nil),
+			Marshal:  (*testTextMarshalerImpl)(nil),
+		}
+		n, err := row.WriteStruct(&value, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 3)
+		for i := 0; i < 3; i++ {
+			c.Assert(row.GetCell(i).Value, qt.Equals, "")
+		}
+
+		values := []interface{}{value.Stringer, value.Marshal}
+		row = sheet.AddRow()
+		c.Assert(row.WriteSlice(&values, -1), qt.Equals, 2)
+	})
+
+	csRunO(c, "TestWriteValues", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		c.Assert(row.WriteValues("a", 1, nil, true), qt.Equals, 4)
+		c.Assert(row.WriteValues(), qt.Equals, 0)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "a")
+		c.Assert(row.GetCell(1).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(row.GetCell(2).Value, qt.Equals, "")
+		c.Assert(row.GetCell(3).Type(), qt.Equals, CellTypeBool)
+
+		row = sheet.AddRowValues("b", 2.5)
+		c.Assert(sheet.MaxRow, qt.Equals, 2)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "b")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "2.5")
+	})
+
+	csRunO(c, "TestWriteStructNumberFormat", func(c *qt.C, option FileOption) {
+		type e struct {
+			Price    float64       `xlsx:"0,'#,##0.00',Unit Price"`
+			Share    *float64      `xlsx:"1,0.00%"`
+			Count    sql.NullInt64 `xlsx:"2,'#,##0'"`
+			Missing  nulls.Float64 `xlsx:"3,'#,##0'"`
+			Quote    int           `xlsx:"4,'0\" ''pcs'''"`
+			Name     string        `xlsx:"5,'#,##0'"`
+			Elapsed  time.Duration `xlsx:"6,[mm]:ss"`
+			Huge     uint64        `xlsx:"7,0"`
+			Literals string        `xlsx:"8,'omitempty'"`
+			Sum      testDecimal   `xlsx:"9,'#,##0.00'"`
+		}
+		share := 0.25
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		_, err := row.WriteStruct(&e{
+			Price:    1234.5,
+			Share:    &share,
+			Count:    sql.NullInt64{Int64: 3, Valid: true},
+			Quote:    7,
+			Name:     "x",
+			Elapsed:  90 * time.Second,
+			Huge:     math.MaxUint64,
+			Literals: "",
+			Sum:      testDecimal{"10.5"},
+		}, -1)
+		c.Assert(err, qt.IsNil)
+
+		formats := []string{"#,##0.00", "0.00%", "#,##0", "", `0" 'pcs'`, "", "[mm]:ss", "", "", "#,##0.00"}
+		for i, want := range formats {
+			c.Assert(row.GetCell(i).NumFmt, qt.Equals, want, qt.Commentf("cell %d", i))
+		}
+		c.Assert(row.GetCell(8).Value, qt.Equals, "")
+		c.Assert(row.GetCell(8).Type(), qt.Equals, CellTypeInline)
+		fv, err := row.GetCell(0).FormattedValue()
+		c.Assert(err, qt.IsNil)
+		c.Assert(fv, qt.Equals, "1234.50")
+
+		header := sheet.AddRow()
+		_, err = header.WriteStructHeader(e{})
+		c.Assert(err, qt.IsNil)
+		c.Assert(header.GetCell(0).Value, qt.Equals, "Unit Price")
+
+		type unterminated struct {
+			Price float64 `xlsx:"0,'#,##0.00"`
+		}
+		_, err = sheet.AddRow().WriteStruct(&unterminated{}, -1)
+		c.Assert(errors.Is(err, ErrInvalidTag), qt.Equals, true)
+		type trailing struct {
+			Price float64 `xlsx:"0,'0.00'x"`
+		}
+		_, err = sheet.AddRow().WriteStruct(&trailing{}, -1)
+		c.Assert(errors.Is(err, ErrInvalidTag), qt.Equals, true)
+	})
+
+	csRunO(c, "TestWriteStructCols", func(c *qt.C, option FileOption) {
+		type e struct {
+			Name   string   `xlsx:"4"`
+			Age    int      `xlsx:"1"`
+			Scores []int    `xlsx:"6"`
+			Again  string   `xlsx:"1"`
+			Note   string   `xlsx:"9,omitempty"`
+			Tags   []string `xlsx:"12"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		cols, err := row.WriteStructCols(e{Name: "Eric", Age: 20, Scores: []int{1, 2}, Again: "x"})
+		c.Assert(err, qt.IsNil)
+		c.Assert(cols, qt.DeepEquals, []int{1, 4, 6, 7})
+
+		_, err = row.WriteStructCols(42)
+		c.Assert(err, qt.Equals, ErrNotStructPointer)
+		_, err = row.WriteStructCols((*e)(nil))
+		c.Assert(err, qt.Equals, errNilInterface)
+	})
+
+	csRunO(c, "TestWriteSliceE", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+
+		good := []interface{}{"a", 1, nil, true}
+		row := sheet.AddRow()
+		n, err := row.WriteSliceE(&good, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 4)
+
+		marshalErr := []interface{}{"a", testTextMarshalerImpl{}, "c"}
+		row = sheet.AddRow()
+		n, err = row.WriteSliceE(&marshalErr, -1)
+		c.Assert(err, qt.ErrorMatches, "xlsx: element 1 of type xlsx.testTextMarshalerImpl: nothing to marshal")
+		c.Assert(n, qt.Equals, 1)
+
+		unwritable := []interface{}{make(chan int)}
+		n, err = sheet.AddRow().WriteSliceE(&unwritable, -1)
+		c.Assert(err, qt.ErrorMatches, "xlsx: element 0 of type chan int: value can't be written to a cell")
+		c.Assert(n, qt.Equals, 0)
+
+		panicky := []fmt.Stringer{testPanicStringer{}}
+		n, err = sheet.AddRow().WriteSliceE(&panicky, -1)
+		c.Assert(err, qt.ErrorMatches, "xlsx: element 0 of type xlsx.testPanicStringer: panic: boom")
+		c.Assert(n, qt.Equals, 0)
+		row = sheet.AddRow()
+		c.Assert(row.WriteSlice(&panicky, -1), qt.Equals, 1)
+
+		n, err = sheet.AddRow().WriteSliceE(good, -1)
+		c.Assert(err, qt.Equals, errNotSlicePointer)
+		c.Assert(n, qt.Equals, -1)
+	})
+
+	csRunO(c, "TestWriteComplex", func(c *qt.C, option FileOption) {
+		type e struct {
+			Z   complex128 `xlsx:"0"`
+			Z64 complex64  `xlsx:"1"`
+			Neg complex128 `xlsx:"2"`
+		}
+		value := e{complex(1.5, 2), complex64(complex(0.1, 0)), complex(-3, -0.25)}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		_, err := row.WriteStruct(&value, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "1.5+2i")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "0.1+0i")
+		c.Assert(row.GetCell(2).Value, qt.Equals, "-3-0.25i")
+		c.Assert(row.GetCell(0).Type(), qt.Equals, CellTypeString)
+
+		var got e
+		c.Assert(row.ReadStruct(&got), qt.IsNil)
+		c.Assert(got, qt.Equals, value)
+
+		row.GetCell(0).SetString("(1e+2-3i)")
+		row.GetCell(1).SetString("2i")
+		row.GetCell(2).SetString("-4")
+		c.Assert(row.ReadStruct(&got), qt.IsNil)
+		c.Assert(got, qt.Equals, e{complex(100, -3), complex64(complex(0, 2)), complex(-4, 0)})
+		row.GetCell(0).SetString("1+2j")
+		c.Assert(row.ReadStruct(&got), qt.Not(qt.IsNil))
+
+		values := []interface{}{complex(1, -1)}
+		row = sheet.AddRow()
+		c.Assert(row.WriteSlice(&values, -1), qt.Equals, 1)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "1-1i")
+
+		sw, err := NewStructWriterWithOptions(e{}, WriterOptions{ComplexPrecision: 2})
+		c.Assert(err, qt.IsNil)
+		row = sheet.AddRow()
+		_, err = sw.Write(row, value)
+		c.Assert(err, qt.IsNil)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "1.50+2.00i")
+	})
+
+	csRunO(c, "TestWriteStructInterfaceSlice", func(c *qt.C, option FileOption) {
+		type e struct {
+			ID     int           `xlsx:"0"`
+			Values []interface{} `xlsx:"1"`
+			Last   string        `xlsx:"4"`
+		}
+		var decoded struct{ Values []interface{} }
+		err := json.Unmarshal([]byte(`{"Values": ["a", 1.5, null, true]}`), &decoded)
+		c.Assert(err, qt.IsNil)
+
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		n, err := row.WriteStruct(&e{1, decoded.Values, "end"}, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 6)
+		c.Assert(row.GetCell(1).Value, qt.Equals, "a")
+		c.Assert(row.GetCell(2).Value, qt.Equals, "1.5")
+		c.Assert(row.GetCell(2).Type(), qt.Equals, CellTypeNumeric)
+		c.Assert(row.GetCell(3).Value, qt.Equals, "")
+		// The fourth element is overwritten by the later field Last.
+		c.Assert(row.GetCell(4).Value, qt.Equals, "end")
+
+		for _, values := range [][]interface{}{nil, {}} {
+			row = sheet.AddRow()
+			n, err = row.WriteStruct(&e{2, values, "end"}, -1)
+			c.Assert(err, qt.IsNil)
+			c.Assert(n, qt.Equals, 2)
+		}
+	})
+
+	csRunO(c, "TestWriteNullsTimeAndUUID", func(c *qt.C, option FileOption) {
+		type e struct {
+			At      nulls.Time `xlsx:"0"`
+			Day     nulls.Time `xlsx:"1,date"`
+			Never   nulls.Time `xlsx:"2,date"`
+			ID      nulls.UUID `xlsx:"3"`
+			Missing nulls.UUID `xlsx:"4"`
+		}
+		when := time.Date(2020, 3, 4, 5, 6, 7, 0, time.UTC)
+		var id nulls.UUID
+		c.Assert(id.UnmarshalText([]byte("6ba7b810-9dad-11d1-80b4-00c04fd430c8")), qt.IsNil)
+		value := e{At: nulls.NewTime(when), Day: nulls.NewTime(when), ID: id}
+
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		n, err := row.WriteStruct(&value, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 5)
+		c.Assert(row.GetCell(0).NumFmt, qt.Equals, DefaultDateTimeFormat)
+		c.Assert(row.GetCell(1).NumFmt, qt.Equals, DefaultDateFormat)
+		c.Assert(row.GetCell(2).Value, qt.Equals, "")
+		c.Assert(row.GetCell(3).Value, qt.Equals, "6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+		c.Assert(row.GetCell(4).Value, qt.Equals, "")
+
+		var got e
+		c.Assert(row.ReadStruct(&got), qt.IsNil)
+		c.Assert(got.At.Valid, qt.Equals, true)
+		c.Assert(got.At.Time.Round(time.Millisecond), qt.Equals, when)
+		c.Assert(got.Never.Valid, qt.Equals, false)
+		c.Assert(got.ID, qt.Equals, id)
+		c.Assert(got.Missing.Valid, qt.Equals, false)
+
+		values := []interface{}{nulls.NewTime(when), nulls.Time{}, id, nulls.UUID{}}
+		row = sheet.AddRow()
+		c.Assert(row.WriteSlice(&values, -1), qt.Equals, 4)
+		c.Assert(row.GetCell(0).IsTime(), qt.Equals, true)
+		c.Assert(row.GetCell(1).Value, qt.Equals, "")
+		c.Assert(row.GetCell(2).Value, qt.Equals, "6ba7b810-9dad-11d1-80b4-00c04fd430c8")
+		c.Assert(row.GetCell(3).Value, qt.Equals, "")
+	})
+
+	csRunO(c, "TestWriteSliceUntilNil", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+
+		var nilPtr *string
+		values := []interface{}{"a", "", nilPtr, 0, nil, "ignored"}
+		row := sheet.AddRow()
+		c.Assert(row.WriteSliceUntilNil(&values), qt.Equals, 4)
+		var cells []string
+		row.ForEachCell(func(cell *Cell) error {
+			cells = append(cells, cell.Value)
+			return nil
+		})
+		c.Assert(cells, qt.DeepEquals, []string{"a", "", "", "0"})
+
+		leading := []interface{}{nil, "b"}
+		c.Assert(sheet.AddRow().WriteSliceUntilNil(&leading), qt.Equals, 0)
+		complete := []interface{}{"x", "y"}
+		c.Assert(sheet.AddRow().WriteSliceUntilNil(&complete), qt.Equals, 2)
+		strs := []string{"p", "q"}
+		c.Assert(sheet.AddRow().WriteSliceUntilNil(&strs), qt.Equals, 2)
+		c.Assert(sheet.AddRow().WriteSliceUntilNil(complete), qt.Equals, -1)
+	})
+
+	csRunO(c, "TestAppendStruct", func(c *qt.C, option FileOption) {
+		type narrow struct {
+			Name string `xlsx:"0"`
+		}
+		type wide struct {
+			Name string `xlsx:"0"`
+			Note string `xlsx:"E"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+
+		row, err := sheet.AppendStruct(&wide{"a", "b"})
+		c.Assert(err, qt.IsNil)
+		c.Assert(row.GetCell(4).Value, qt.Equals, "b")
+		c.Assert(sheet.MaxRow, qt.Equals, 1)
+		c.Assert(sheet.MaxCol, qt.Equals, 5)
+
+		row, err = sheet.AppendStruct(&narrow{"c"})
+		c.Assert(err, qt.IsNil)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "c")
+		c.Assert(sheet.MaxCol, qt.Equals, 5)
+
+		type invalid struct {
+			Name string `xlsx:"?"`
+		}
+		row, err = sheet.AppendStruct(&invalid{})
+		c.Assert(errors.Is(err, ErrInvalidTag), qt.Equals, true)
+		c.Assert(row, qt.Not(qt.IsNil))
+	})
+
+	csRunO(c, "TestWriteStructTrim", func(c *qt.C, option FileOption) {
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		type name string
+		type e struct {
+			Plain   string         `xlsx:"0,trim"`
+			Ptr     *string        `xlsx:"1,trim"`
+			Null    sql.NullString `xlsx:"2,trim"`
+			Nulls   nulls.String   `xlsx:"3,trim"`
+			Named   name           `xlsx:"4,trim"`
+			Omit    string         `xlsx:"5,trim,omitempty"`
+			Default string         `xlsx:"6,default=n/a,trim"`
+			Count   int            `xlsx:"7,trim"`
+			Kept    string         `xlsx:"8"`
+		}
+		ptr := "\tpointed\n"
+		v := e{
+			Plain:   "  plain ",
+			Ptr:     &ptr,
+			Null:    sql.NullString{String: " null ", Valid: true},
+			Nulls:   nulls.NewString(" nulls "),
+			Named:   " named",
+			Omit:    "   ",
+			Default: " \t ",
+			Count:   3,
+			Kept:    " kept ",
+		}
+		row := sheet.AddRow()
+		row.GetCell(5).SetString("template")
+		cnt, err := row.WriteStruct(&v, -1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(cnt, qt.Equals, 8)
+
+		var got []string
+		row.ForEachCell(func(cell *Cell) error {
+			got = append(got, cell.Value)
+			return nil
+		})
+		c.Assert(got, qt.DeepEquals, []string{"plain", "pointed", "null", "nulls", "named", "template", "n/a", "3", " kept "})
+		// The struct itself is left alone.
+		c.Assert(ptr, qt.Equals, "\tpointed\n")
+		c.Assert(v.Null.String, qt.Equals, " null ")
+	})
+
+	csRunO(c, "TestWriteNonFinite", func(c *qt.C, option FileOption) {
+		type reading struct {
+			NaN     float64         `xlsx:"0"`
+			Inf     float32         `xlsx:"1,'0.00'"`
+			Null    sql.NullFloat64 `xlsx:"2"`
+			Nulls   nulls.Float64   `xlsx:"3"`
+			Finite  float64         `xlsx:"4"`
+			Missing nulls.Float64   `xlsx:"5"`
+		}
+		value := reading{
+			NaN:    math.NaN(),
+			Inf:    float32(math.Inf(1)),
+			Null:   sql.NullFloat64{Float64: math.Inf(-1), Valid: true},
+			Nulls:  nulls.NewFloat64(math.NaN()),
+			Finite: 1.5,
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+
+		cases := []struct {
+			options WriterOptions
+			want    string
+			typ     CellType
+		}{
+			{WriterOptions{}, "", CellTypeString},
+			{WriterOptions{NonFinite: NonFiniteAsText, NonFiniteText: "n/a"}, "n/a", CellTypeString},
+			{WriterOptions{NonFinite: NonFiniteAsError}, "#NUM!", CellTypeError},
+		}
+		for _, tc := range cases {
+			sw, err := NewStructWriterWithOptions(reading{}, tc.options)
+			c.Assert(err, qt.IsNil)
+			row := sheet.AddRow()
+			_, err = sw.Write(row, value)
+			c.Assert(err, qt.IsNil)
+			for i := 0; i < 4; i++ {
+				cell := row.GetCell(i)
+				c.Assert(cell.Value, qt.Equals, tc.want, qt.Commentf("options %+v, cell %d", tc.options, i))
+				c.Assert(cell.Type(), qt.Equals, tc.typ)
+				c.Assert(cell.NumFmt, qt.Not(qt.Equals), "0.00")
+			}
+			c.Assert(row.GetCell(4).Value, qt.Equals, "1.5")
+			c.Assert(row.GetCell(5).Value, qt.Equals, "")
+		}
+
+		row := sheet.AddRow()
+		values := []interface{}{math.Inf(1), 2.5}
+		c.Assert(row.WriteSlice(&values, -1), qt.Equals, 2)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "2.5")
+
+		var buf bytes.Buffer
+		c.Assert(f.Write(&buf), qt.IsNil)
+	})
+
+	csRunO(c, "TestWriteStructLocale", func(c *qt.C, option FileOption) {
+		type invoice struct {
+			Total  float64     `xlsx:"0,'#.##0,00'"`
+			Amount testDecimal `xlsx:"1"`
+			Date   time.Time   `xlsx:"2,dd.mm.yyyy"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		sw, err := NewStructWriterWithOptions(invoice{}, WriterOptions{
+			Locale:        Locale{Decimal: ',', Group: '.'},
+			DecimalFormat: "0,000",
+		})
+		c.Assert(err, qt.IsNil)
+		row := sheet.AddRow()
+		_, err = sw.Write(row, invoice{1234.5, testDecimal{"2.5"}, time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC)})
+		c.Assert(err, qt.IsNil)
+		c.Assert(row.GetCell(0).Value, qt.Equals, "1234.5")
+		c.Assert(row.GetCell(0).NumFmt, qt.Equals, "#,##0.00")
+		c.Assert(row.GetCell(1).Value, qt.Equals, "2.5")
+		c.Assert(row.GetCell(1).NumFmt, qt.Equals, "0.000")
+		c.Assert(row.GetCell(2).NumFmt, qt.Equals, "dd.mm.yyyy")
+	})
+
+	csRunO(c, "TestWriteStructTransposed", func(c *qt.C, option FileOption) {
+		type card struct {
+			Name   string   `xlsx:"0"`
+			Age    int      `xlsx:"2"`
+			Note   string   `xlsx:"3,omitempty"`
+			Scores []int    `xlsx:"4"`
+			Ptr    *float64 `xlsx:"5"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		n, err := sheet.WriteStructTransposed(&card{Name: "Eric", Age: 20, Scores: []int{7, 9}}, 1)
+		c.Assert(err, qt.IsNil)
+		c.Assert(n, qt.Equals, 4)
+
+		want := map[[2]int]string{
+			{0, 1}: "Eric",
+			{1, 1}: "",
+			{2, 1}: "20",
+			{3, 1}: "",
+			{4, 1}: "7",
+			{4, 2}: "9",
+			{5, 1}: "",
+			{0, 0}: "",
+		}
+		for pos, value := range want {
+			cell, err := sheet.Cell(pos[0], pos[1])
+			c.Assert(err, qt.IsNil)
+			c.Assert(cell.Value, qt.Equals, value, qt.Commentf("row %d, col %d", pos[0], pos[1]))
+		}
+		c.Assert(sheet.MaxCol, qt.Equals, 3)
+
+		_, err = sheet.WriteStructTransposed(3, 0)
+		c.Assert(err, qt.Equals, ErrNotStructPointer)
+	})
+
+	csRunO(c, "TestWriteStructNumberText", func(c *qt.C, option FileOption) {
+		type e struct {
+			Count    string         `xlsx:"0,number"`
+			Price    *string        `xlsx:"1,number,'#,##0.00'"`
+			Phone    string         `xlsx:"2,number"`
+			Long     string         `xlsx:"3,number"`
+			Null     sql.NullString `xlsx:"4,number"`
+			Code     int            `xlsx:"5,text,00000"`
+			Ratio    float64        `xlsx:"6,text"`
+			Born     time.Time      `xlsx:"7,text,yyyy-mm-dd"`
+			Missing  nulls.Int      `xlsx:"8,text"`
+			Kept     string         `xlsx:"9"`
+			Untagged int            `xlsx:"10"`
+			Grouped  string         `xlsx:"11,number"`
+		}
+		price := "1234.50"
+		v := e{
+			Count:    "42",
+			Price:    &price,
+			Phone:    "+44 20 7946 0000",
+			Long:     "12345678901234567890",
+			Null:     sql.NullString{String: "0.1", Valid: true},
+			Code:     42,
+			Ratio:    0.5,
+			Born:     time.Date(2020, 3, 4, 0, 0, 0, 0, time.UTC),
+			Kept:     "42",
+			Untagged: 7,
+			Grouped:  "1_000",
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		_, err := row.WriteStruct(&v, -1)
+		c.Assert(err, qt.IsNil)
+
+		want := []struct {
+			value  string
+			typ    CellType
+			numFmt string
+		}{
+			{"42", CellTypeNumeric, "general"},
+			{"1234.5", CellTypeNumeric, "#,##0.00"},
+			{"+44 20 7946 0000", CellTypeString, ""},
+			{"12345678901234567890", CellTypeString, ""},
+			{"0.1", CellTypeNumeric, "general"},
+			{"00042", CellTypeString, "@"},
+			{"0.5", CellTypeString, "@"},
+			{"2020-03-04", CellTypeString, "@"},
+			{"", CellTypeString, ""},
+			{"42", CellTypeString, ""},
+			{"7", CellTypeNumeric, "general"},
+			{"1_000", CellTypeString, ""},
+		}
+		for i, w := range want {
+			cell := row.GetCell(i)
+			c.Assert(cell.Value, qt.Equals, w.value, qt.Commentf("cell %d", i))
+			c.Assert(cell.Type(), qt.Equals, w.typ, qt.Commentf("cell %d", i))
+			c.Assert(cell.NumFmt, qt.Equals, w.numFmt, qt.Commentf("cell %d", i))
+		}
+
+		type both struct {
+			A string `xlsx:"0,number,text"`
+		}
+		_, err = row.WriteStruct(&both{}, -1)
+		c.Assert(errors.Is(err, ErrInvalidTag), qt.Equals, true)
+	})
+
+	csRunO(c, "TestWriteStrictNumbers", func(c *qt.C, option FileOption) {
+		type account struct {
+			ID      int64         `xlsx:"0"`
+			Balance testDecimal   `xlsx:"1"`
+			Ref     *uint64       `xlsx:"2"`
+			Parts   []int64       `xlsx:"3"`
+			Legacy  nulls.Int64   `xlsx:"5"`
+			Code    int64         `xlsx:"6,text"`
+			Any     []interface{} `xlsx:"7"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		sw, err := NewStructWriterWithOptions(account{}, WriterOptions{StrictNumbers: true})
+		c.Assert(err, qt.IsNil)
+
+		small := uint64(1 << 53)
+		ok := account{
+			ID:      -1 << 53,
+			Balance: testDecimal{"1234.10"},
+			Ref:     &small,
+			Parts:   []int64{1, 2},
+			Legacy:  nulls.Int64{Int64: 1<<60 + 1},
+			Code:    1<<60 + 1,
+			Any:     []interface{}{"x", json.Number("0.5")},
+		}
+		_, err = sw.Write(sheet.AddRow(), ok)
+		c.Assert(err, qt.IsNil)
+
+		big := uint64(1<<53 + 1)
+		cases := []struct {
+			account account
+			err     string
+		}{
+			{account{ID: 1<<53 + 1}, "xlsx: field ID: value 9007199254740993: number can't be stored exactly"},
+			{account{Balance: testDecimal{"0.12345678901234567890"}}, "xlsx: field Balance: value 0.12345678901234567890: number can't be stored exactly"},
+			{account{Ref: &big}, "xlsx: field Ref: value 9007199254740993: number can't be stored exactly"},
+			{account{Parts: []int64{1, -1 << 60}}, "xlsx: field Parts: value -1152921504606846976: number can't be stored exactly"},
+			{account{Legacy: nulls.NewInt64(1 << 54)}, "xlsx: field Legacy: value 18014398509481984: number can't be stored exactly"},
+			{account{Any: []interface{}{json.Number("12345678901234567890")}}, "xlsx: field Any: value 12345678901234567890: number can't be stored exactly"},
+		}
+		for _, tc := range cases {
+			_, err := sw.Write(sheet.AddRow(), tc.account)
+			c.Assert(err, qt.ErrorMatches, tc.err)
+			c.Assert(errors.Is(err, ErrInexactNumber), qt.Equals, true)
+		}
+
+		// By default the same values are written without complaint.
+		_, err = sheet.AddRow().WriteStruct(&cases[0].account, -1)
+		c.Assert(err, qt.IsNil)
+	})
+
+	csRunO(c, "TestWriteStructUnix", func(c *qt.C, option FileOption) {
+		type event struct {
+			At      time.Time  `xlsx:"0,unix"`
+			AtMilli time.Time  `xlsx:"1,unixmilli"`
+			Seen    nulls.Time `xlsx:"2,unix,'#,##0'"`
+			Missing nulls.Time `xlsx:"3,unix"`
+			Ptr     *time.Time `xlsx:"4,unixmilli"`
+			Zero    time.Time  `xlsx:"5,unix"`
+			Date    time.Time  `xlsx:"6"`
+			Name    string     `xlsx:"7,unix"`
+		}
+		at := time.Date(2021, 6, 1, 12, 30, 0, 123456789, time.UTC)
+		v := event{
+			At:      at,
+			AtMilli: at,
+			Seen:    nulls.NewTime(at.In(time.FixedZone("CEST", 2*60*60))),
+			Ptr:     &at,
+			Date:    at,
+			Name:    "x",
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		_, err := row.WriteStruct(&v, -1)
+		c.Assert(err, qt.IsNil)
+
+		want := []struct {
+			value  string
+			typ    CellType
+			numFmt string
+		}{
+			{"1622550600", CellTypeNumeric, "0"},
+			{"1622550600123", CellTypeNumeric, "0"},
+			{"1622550600", CellTypeNumeric, "#,##0"},
+			{"", CellTypeString, ""},
+			{"1622550600123", CellTypeNumeric, "0"},
+			{"", CellTypeString, ""},
+			{"x", CellTypeString, ""},
+		}
+		for i, w := range want {
+			col := i
+			if i == 6 {
+				col = 7
+			}
+			cell := row.GetCell(col)
+			c.Assert(cell.Value, qt.Equals, w.value, qt.Commentf("cell %d", col))
+			c.Assert(cell.Type(), qt.Equals, w.typ, qt.Commentf("cell %d", col))
+			c.Assert(cell.NumFmt, qt.Equals, w.numFmt, qt.Commentf("cell %d", col))
+		}
+		date, err := row.GetCell(6).GetTime(false)
+		c.Assert(err, qt.IsNil)
+		c.Assert(date.Round(time.Second).Equal(at.Round(time.Second)), qt.Equals, true)
+	})
+
+	csRunO(c, "TestWriteStructHyperlink", func(c *qt.C, option FileOption) {
+		type link struct {
+			Name string         `xlsx:"0"`
+			URL  string         `xlsx:"1,hyperlink"`
+			Docs sql.NullString `xlsx:"2,hyperlink"`
+			None string         `xlsx:"3,hyperlink,default=-"`
+		}
+		f := NewFile(option)
+		sheet, _ := f.AddSheet("Test1")
+		row := sheet.AddRow()
+		_, err := row.WriteStruct(&link{
+			Name: "Go",
+			URL:  "https://go.dev/",
+			Docs: sql.NullString{String: "https://pkg.go.dev/", Valid: true},
+		}, -1)
+		c.Assert(err, qt.IsNil)
+
+		c.Assert(row.GetCell(0).Hyperlink, qt.Equals, Hyperlink{})
+		c.Assert(row.GetCell(1).Value, qt.Equals, "https://go.dev/")
+		c.Assert(row.GetCell(1).Hyperlink, qt.Equals, Hyperlink{Link: "https://go.dev/"})
+		c.Assert(row.GetCell(2).Hyperlink, qt.Equals, Hyperlink{Link: "https://pkg.go.dev/"})
+		c.Assert(row.GetCell(3).Value, qt.Equals, "-")
+		c.Assert(row.GetCell(3).Hyperlink, qt.Equals, Hyperlink{})
+		c.Assert(sheet.Relations, qt.HasLen, 2)
+
+		parts, err := f.MarshallParts()
+		c.Assert(err, qt.IsNil)
+		c.Assert(parts["xl/worksheets/sheet1.xml"], qt.Contains, `<hyperlink r:id="rId1" ref="B1"></hyperlink><hyperlink r:id="rId2" ref="C1"></hyperlink>`)
+	})
 }
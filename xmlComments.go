@@ -0,0 +1,101 @@
+package xlsx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// xlsxComments directly maps the comments element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxComments struct {
+	XMLName     xml.Name        `xml:"http://schemas.openxmlformats.org/spreadsheetml/2006/main comments"`
+	Authors     xlsxAuthors     `xml:"authors"`
+	CommentList xlsxCommentList `xml:"commentList"`
+}
+
+// xlsxAuthors directly maps the authors element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main
+type xlsxAuthors struct {
+	Author []string `xml:"author"`
+}
+
+// xlsxCommentList directly maps the commentList element in the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main
+type xlsxCommentList struct {
+	Comment []xlsxComment `xml:"comment"`
+}
+
+// xlsxComment directly maps the comment element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main - the
+// text is held the same way as a shared string.
+type xlsxComment struct {
+	Ref      string `xml:"ref,attr"`
+	AuthorId int    `xml:"authorId,attr"`
+	Text     xlsxSI `xml:"text"`
+}
+
+// add adds comment c on the cell ref, adding its author to the authors
+// unless it's there already.
+func (cs *xlsxComments) add(ref string, c Comment) {
+	id := -1
+	for i, author := range cs.Authors.Author {
+		if author == c.Author {
+			id = i
+			break
+		}
+	}
+	if id < 0 {
+		id = len(cs.Authors.Author)
+		cs.Authors.Author = append(cs.Authors.Author, c.Author)
+	}
+	cs.CommentList.Comment = append(cs.CommentList.Comment, xlsxComment{
+		Ref:      ref,
+		AuthorId: id,
+		Text:     xlsxSI{T: &xlsxT{Text: c.Text}},
+	})
+}
+
+// comment returns the Comment held by c, with the text of all of its
+// runs joined together.
+func (cs *xlsxComments) comment(c xlsxComment) Comment {
+	var comment Comment
+	if c.AuthorId >= 0 && c.AuthorId < len(cs.Authors.Author) {
+		comment.Author = cs.Authors.Author[c.AuthorId]
+	}
+	var b strings.Builder
+	if c.Text.T != nil {
+		b.WriteString(c.Text.T.Text)
+	}
+	for _, r := range c.Text.R {
+		b.WriteString(r.T.Text)
+	}
+	comment.Text = b.String()
+	return comment
+}
+
+// makeVMLDrawing returns the legacy VML drawing of the note boxes of
+// the comments, which Excel needs in order to show them. Each sheet
+// has its own 'id', which keeps the shape ids apart.
+func (cs *xlsxComments) makeVMLDrawing(id int) (string, error) {
+	var b strings.Builder
+	b.WriteString(`<xml xmlns:v="urn:schemas-microsoft-com:vml" xmlns:o="urn:schemas-microsoft-com:office:office" xmlns:x="urn:schemas-microsoft-com:office:excel">`)
+	fmt.Fprintf(&b, `<o:shapelayout v:ext="edit"><o:idmap v:ext="edit" data="%d"/></o:shapelayout>`, id)
+	b.WriteString(`<v:shapetype id="_x0000_t202" coordsize="21600,21600" o:spt="202" path="m,l,21600r21600,l21600,xe">`)
+	b.WriteString(`<v:stroke joinstyle="miter"/><v:path gradientshapeok="t" o:connecttype="rect"/></v:shapetype>`)
+	for i, c := range cs.CommentList.Comment {
+		col, row, err := GetCoordsFromCellIDString(c.Ref)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&b, `<v:shape id="_x0000_s%d" type="#_x0000_t202" style="position:absolute;margin-left:59.25pt;margin-top:1.5pt;width:108pt;height:59.25pt;z-index:%d;visibility:hidden" fillcolor="#ffffe1" o:insetmode="auto">`, id*1024+i+1, i+1)
+		b.WriteString(`<v:fill color2="#ffffe1"/><v:shadow on="t" color="black" obscured="t"/><v:path o:connecttype="none"/>`)
+		b.WriteString(`<v:textbox style="mso-direction-alt:auto"><div style="text-align:left"></div></v:textbox>`)
+		fmt.Fprintf(&b, `<x:ClientData ObjectType="Note"><x:MoveWithCells/><x:SizeWithCells/><x:Anchor>%d, 15, %d, 2, %d, 15, %d, 4</x:Anchor>`, col+1, row, col+3, row+4)
+		fmt.Fprintf(&b, `<x:AutoFill>False</x:AutoFill><x:Row>%d</x:Row><x:Column>%d</x:Column></x:ClientData></v:shape>`, row, col)
+	}
+	b.WriteString(`</xml>`)
+	return b.String(), nil
+}
@@ -0,0 +1,49 @@
+package xlsx
+
+// xlsxConditionalFormatting directly maps the conditionalFormatting
+// element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxConditionalFormatting struct {
+	Sqref  string       `xml:"sqref,attr"`
+	CfRule []xlsxCfRule `xml:"cfRule"`
+}
+
+// xlsxCfRule directly maps the cfRule element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main - the
+// format a cell value rule applies is the differential format DxfId
+// of the style sheet.
+type xlsxCfRule struct {
+	Type       string          `xml:"type,attr"`
+	DxfId      *int            `xml:"dxfId,attr"`
+	Priority   int             `xml:"priority,attr"`
+	Operator   string          `xml:"operator,attr,omitempty"`
+	Formula    []string        `xml:"formula"`
+	ColorScale *xlsxColorScale `xml:"colorScale"`
+	DataBar    *xlsxDataBar    `xml:"dataBar"`
+}
+
+// xlsxColorScale directly maps the colorScale element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main, with a
+// color for each of its cfvo thresholds.
+type xlsxColorScale struct {
+	Cfvo  []xlsxCfvo  `xml:"cfvo"`
+	Color []xlsxColor `xml:"color"`
+}
+
+// xlsxDataBar directly maps the dataBar element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main
+type xlsxDataBar struct {
+	Cfvo  []xlsxCfvo  `xml:"cfvo"`
+	Color []xlsxColor `xml:"color"`
+}
+
+// xlsxCfvo directly maps the cfvo element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main, which
+// gives a threshold of a color scale or data bar, such as the lowest
+// value or the 50th percentile.
+type xlsxCfvo struct {
+	Type string `xml:"type,attr"`
+	Val  string `xml:"val,attr,omitempty"`
+}
@@ -0,0 +1,77 @@
+package xlsx
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// xlsxDrawing directly maps the drawing element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main, which
+// refers to the drawing holding the images of a sheet.
+type xlsxDrawing struct {
+	RelationshipId string `xml:"id,attr"`
+}
+
+// xlsxWsDr directly maps the wsDr element in the namespace
+// http://schemas.openxmlformats.org/drawingml/2006/spreadsheetDrawing -
+// only the pictures anchored to two cells are read, which is how
+// images are saved.
+type xlsxWsDr struct {
+	XMLName       xml.Name            `xml:"http://schemas.openxmlformats.org/drawingml/2006/spreadsheetDrawing wsDr"`
+	TwoCellAnchor []xlsxTwoCellAnchor `xml:"twoCellAnchor"`
+}
+
+// xlsxTwoCellAnchor directly maps the twoCellAnchor element in the
+// namespace
+// http://schemas.openxmlformats.org/drawingml/2006/spreadsheetDrawing
+type xlsxTwoCellAnchor struct {
+	From xlsxDrawingMarker `xml:"from"`
+	To   xlsxDrawingMarker `xml:"to"`
+	Pic  *xlsxPic          `xml:"pic"`
+}
+
+// xlsxDrawingMarker directly maps the from and to elements in the
+// namespace
+// http://schemas.openxmlformats.org/drawingml/2006/spreadsheetDrawing,
+// with the offsets in EMUs.
+type xlsxDrawingMarker struct {
+	Col    int   `xml:"col"`
+	ColOff int64 `xml:"colOff"`
+	Row    int   `xml:"row"`
+	RowOff int64 `xml:"rowOff"`
+}
+
+// xlsxPic directly maps the pic element in the namespace
+// http://schemas.openxmlformats.org/drawingml/2006/spreadsheetDrawing -
+// currently I have not checked it for completeness - it does as much
+// as I need.
+type xlsxPic struct {
+	BlipFill struct {
+		Blip struct {
+			Embed string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships embed,attr"`
+		} `xml:"http://schemas.openxmlformats.org/drawingml/2006/main blip"`
+	} `xml:"blipFill"`
+}
+
+// makeDrawing returns the drawing of the images of a sheet, the image
+// 'i' of which is embedded by the relationship "rId<i+1>" of the
+// drawing. The encoding/xml package can't write the namespace prefixes
+// Excel insists on, so the XML is built by hand.
+func makeDrawing(images []Image) string {
+	var b strings.Builder
+	b.WriteString(xml.Header)
+	b.WriteString(`<xdr:wsDr xmlns:xdr="http://schemas.openxmlformats.org/drawingml/2006/spreadsheetDrawing" xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	for i, img := range images {
+		a := img.Anchor
+		b.WriteString(`<xdr:twoCellAnchor editAs="oneCell">`)
+		fmt.Fprintf(&b, `<xdr:from><xdr:col>%d</xdr:col><xdr:colOff>%d</xdr:colOff><xdr:row>%d</xdr:row><xdr:rowOff>%d</xdr:rowOff></xdr:from>`, a.FromCol, a.FromColOffset, a.FromRow, a.FromRowOffset)
+		fmt.Fprintf(&b, `<xdr:to><xdr:col>%d</xdr:col><xdr:colOff>%d</xdr:colOff><xdr:row>%d</xdr:row><xdr:rowOff>%d</xdr:rowOff></xdr:to>`, a.ToCol, a.ToColOffset, a.ToRow, a.ToRowOffset)
+		fmt.Fprintf(&b, `<xdr:pic><xdr:nvPicPr><xdr:cNvPr id="%d" name="Picture %d"/><xdr:cNvPicPr><a:picLocks noChangeAspect="1"/></xdr:cNvPicPr></xdr:nvPicPr>`, i+2, i+1)
+		fmt.Fprintf(&b, `<xdr:blipFill><a:blip r:embed="rId%d"/><a:stretch><a:fillRect/></a:stretch></xdr:blipFill>`, i+1)
+		b.WriteString(`<xdr:spPr><a:prstGeom prst="rect"><a:avLst/></a:prstGeom></xdr:spPr></xdr:pic>`)
+		b.WriteString(`<xdr:clientData/></xdr:twoCellAnchor>`)
+	}
+	b.WriteString(`</xdr:wsDr>`)
+	return b.String()
+}
@@ -182,6 +182,17 @@ type xlsxStyleSheet struct {
 	numFmtRefTable    map[int]xlsxNumFmt
 	parsedNumFmtTableMU sync.RWMutex
 	parsedNumFmtTable map[string]*parsedNumberFormat
+
+	// styleXfs are the cell xfs of the styles written so far, see
+	// styleXf.
+	styleXfs map[styleXfKey]int
+}
+
+// styleXfKey is a Style paired with the number format it is written
+// with, which together give a cell xf.
+type styleXfKey struct {
+	style    *Style
+	numFmtId int
 }
 
 func newXlsxStyleSheet(t *theme) *xlsxStyleSheet {
@@ -192,9 +203,11 @@ func newXlsxStyleSheet(t *theme) *xlsxStyleSheet {
 }
 
 func (styles *xlsxStyleSheet) reset() {
+	styles.styleXfs = nil
 	styles.Fonts = xlsxFonts{}
 	styles.Fills = xlsxFills{}
 	styles.Borders = xlsxBorders{}
+	styles.DXfs = xlsxDXFs{}
 
 	// Microsoft seems to want Arial 11 defined by default.
 	styles.addFont(
@@ -236,6 +249,11 @@ func (styles *xlsxStyleSheet) populateStyleFromXf(style *Style, xf xlsxXf) {
 	style.ApplyFill = xf.ApplyFill
 	style.ApplyFont = xf.ApplyFont
 	style.ApplyAlignment = xf.ApplyAlignment
+	style.ApplyProtection = xf.ApplyProtection || xf.Protection != nil
+	style.Protection.Locked = xf.Protection.locked()
+	if xf.Protection != nil {
+		style.Protection.Hidden = xf.Protection.Hidden
+	}
 
 	if xf.BorderId > -1 && xf.BorderId < styles.Borders.Count {
 		var border xlsxBorder
@@ -413,6 +431,19 @@ func (styles *xlsxStyleSheet) addFill(xFill xlsxFill) (index int) {
 	return
 }
 
+func (styles *xlsxStyleSheet) addDxf(xDxf xlsxDxf) (index int) {
+	var dxf xlsxDxf
+	for index, dxf = range styles.DXfs.Dxf {
+		if dxf.Equals(xDxf) {
+			return index
+		}
+	}
+	styles.DXfs.Dxf = append(styles.DXfs.Dxf, xDxf)
+	index = styles.DXfs.Count
+	styles.DXfs.Count++
+	return
+}
+
 func (styles *xlsxStyleSheet) addBorder(xBorder xlsxBorder) (index int) {
 	var border xlsxBorder
 	for index, border = range styles.Borders.Border {
@@ -457,6 +488,23 @@ func (styles *xlsxStyleSheet) addCellXf(xCellXf xlsxXf) (index int) {
 	return
 }
 
+// styleXf returns the index of the cell xf for style with the number
+// format numFmtId, adding the xf if there isn't an equal one already.
+// The index is remembered for the style, so that cells sharing a Style
+// don't each compare it with the existing xfs.
+func (styles *xlsxStyleSheet) styleXf(style *Style, numFmtId int) int {
+	key := styleXfKey{style: style, numFmtId: numFmtId}
+	if xfId, ok := styles.styleXfs[key]; ok {
+		return xfId
+	}
+	xfId := handleStyleForXLSX(style, numFmtId, styles)
+	if styles.styleXfs == nil {
+		styles.styleXfs = make(map[styleXfKey]int)
+	}
+	styles.styleXfs[key] = xfId
+	return xfId
+}
+
 // newNumFmt generate a xlsxNumFmt according the format code. When the FormatCode is built in, it will return a xlsxNumFmt with the NumFmtId defined in ECMA document, otherwise it will generate a new NumFmtId greater than 164.
 func (styles *xlsxStyleSheet) newNumFmt(formatCode string) xlsxNumFmt {
 	if compareFormatString(formatCode, "general") {
@@ -576,11 +624,72 @@ func (styles *xlsxStyleSheet) Marshal() (string, error) {
 		result += xcellStyles
 	}
 
+	if styles.DXfs.Count > 0 {
+		xdxfs, err := styles.DXfs.Marshal()
+		if err != nil {
+			return "", err
+		}
+		result += xdxfs
+	}
+
 	return result + "</styleSheet>", nil
 }
 
+// xlsxDXFs directly maps the dxfs element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main, which
+// holds the differential formats used by conditional formatting.
 type xlsxDXFs struct {
-	Count int `xml:"count,attr"`
+	Count int       `xml:"count,attr"`
+	Dxf   []xlsxDxf `xml:"dxf,omitempty"`
+}
+
+func (dxfs *xlsxDXFs) Marshal() (result string, err error) {
+	result = fmt.Sprintf(`<dxfs count="%d">`, dxfs.Count)
+	for _, dxf := range dxfs.Dxf {
+		var xdxf string
+		xdxf, err = dxf.Marshal()
+		if err != nil {
+			return
+		}
+		result += xdxf
+	}
+	return result + `</dxfs>`, nil
+}
+
+// xlsxDxf directly maps the dxf element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main - a
+// differential format only holds the parts of a style it changes.
+type xlsxDxf struct {
+	Font *xlsxFont `xml:"font,omitempty"`
+	Fill *xlsxFill `xml:"fill,omitempty"`
+}
+
+func (dxf *xlsxDxf) Equals(other xlsxDxf) bool {
+	if (dxf.Font == nil) != (other.Font == nil) || (dxf.Fill == nil) != (other.Fill == nil) {
+		return false
+	}
+	return (dxf.Font == nil || dxf.Font.Equals(*other.Font)) && (dxf.Fill == nil || dxf.Fill.Equals(*other.Fill))
+}
+
+func (dxf *xlsxDxf) Marshal() (result string, err error) {
+	result = "<dxf>"
+	if dxf.Font != nil {
+		var xfont string
+		xfont, err = dxf.Font.Marshal()
+		if err != nil {
+			return
+		}
+		result += xfont
+	}
+	if dxf.Fill != nil {
+		var xfill string
+		xfill, err = dxf.Fill.Marshal()
+		if err != nil {
+			return
+		}
+		result += xfill
+	}
+	return result + "</dxf>", nil
 }
 
 // xlsxNumFmts directly maps the numFmts element in the namespace
@@ -1053,18 +1162,32 @@ func (cellXfs *xlsxCellXfs) Marshal(outputBorderMap, outputFillMap, outputFontMa
 // currently I have not checked it for completeness - it does as much
 // as I need.
 type xlsxXf struct {
-	ApplyAlignment    bool          `xml:"applyAlignment,attr"`
-	ApplyBorder       bool          `xml:"applyBorder,attr"`
-	ApplyFont         bool          `xml:"applyFont,attr"`
-	ApplyFill         bool          `xml:"applyFill,attr"`
-	ApplyNumberFormat bool          `xml:"applyNumberFormat,attr"`
-	ApplyProtection   bool          `xml:"applyProtection,attr"`
-	BorderId          int           `xml:"borderId,attr"`
-	FillId            int           `xml:"fillId,attr"`
-	FontId            int           `xml:"fontId,attr"`
-	NumFmtId          int           `xml:"numFmtId,attr"`
-	XfId              *int          `xml:"xfId,attr,omitempty"`
-	Alignment         xlsxAlignment `xml:"alignment"`
+	ApplyAlignment    bool            `xml:"applyAlignment,attr"`
+	ApplyBorder       bool            `xml:"applyBorder,attr"`
+	ApplyFont         bool            `xml:"applyFont,attr"`
+	ApplyFill         bool            `xml:"applyFill,attr"`
+	ApplyNumberFormat bool            `xml:"applyNumberFormat,attr"`
+	ApplyProtection   bool            `xml:"applyProtection,attr"`
+	BorderId          int             `xml:"borderId,attr"`
+	FillId            int             `xml:"fillId,attr"`
+	FontId            int             `xml:"fontId,attr"`
+	NumFmtId          int             `xml:"numFmtId,attr"`
+	XfId              *int            `xml:"xfId,attr,omitempty"`
+	Alignment         xlsxAlignment   `xml:"alignment"`
+	Protection        *xlsxProtection `xml:"protection"`
+}
+
+// xlsxProtection directly maps the protection element in the namespace
+// http://schemas.openxmlformats.org/spreadsheetml/2006/main, in which
+// a missing locked attribute means the cell is locked.
+type xlsxProtection struct {
+	Locked *bool `xml:"locked,attr"`
+	Hidden bool  `xml:"hidden,attr"`
+}
+
+// locked reports whether the cells of the protection are locked.
+func (protection *xlsxProtection) locked() bool {
+	return protection == nil || protection.Locked == nil || *protection.Locked
 }
 
 func (xf *xlsxXf) Equals(other xlsxXf) bool {
@@ -1080,7 +1203,11 @@ func (xf *xlsxXf) Equals(other xlsxXf) bool {
 		(xf.XfId == other.XfId ||
 			((xf.XfId != nil && other.XfId != nil) &&
 				*xf.XfId == *other.XfId)) &&
-		xf.Alignment.Equals(other.Alignment)
+		xf.Alignment.Equals(other.Alignment) &&
+		(xf.Protection == nil) == (other.Protection == nil) &&
+		(xf.Protection == nil ||
+			(xf.Protection.locked() == other.Protection.locked() &&
+				xf.Protection.Hidden == other.Protection.Hidden))
 }
 
 func (xf *xlsxXf) Marshal(outputBorderMap, outputFillMap, outputFontMap map[int]int) (result string, err error) {
@@ -1093,7 +1220,11 @@ func (xf *xlsxXf) Marshal(outputBorderMap, outputFillMap, outputFontMap map[int]
 	if err != nil {
 		return result, err
 	}
-	return result + xAlignment + "</xf>", nil
+	result += xAlignment
+	if xf.Protection != nil {
+		result += fmt.Sprintf(`<protection locked="%d" hidden="%d"/>`, bool2Int(xf.Protection.locked()), bool2Int(xf.Protection.Hidden))
+	}
+	return result + "</xf>", nil
 }
 
 type xlsxAlignment struct {
@@ -8,7 +8,12 @@ import (
 type RelationshipType string
 
 const (
-	RelationshipTypeHyperlink RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+	RelationshipTypeHyperlink  RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/hyperlink"
+	RelationshipTypeComments   RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/comments"
+	RelationshipTypeVMLDrawing RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/vmlDrawing"
+	RelationshipTypeDrawing    RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/drawing"
+	RelationshipTypeImage      RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/image"
+	RelationshipTypeTable      RelationshipType = "http://schemas.openxmlformats.org/officeDocument/2006/relationships/table"
 )
 
 type RelationshipTargetMode string
@@ -27,7 +32,7 @@ type xlsxWorksheetRelation struct {
 	Id         string                 `xml:"Id,attr"`
 	Type       RelationshipType       `xml:"Type,attr"`
 	Target     string                 `xml:"Target,attr"`
-	TargetMode RelationshipTargetMode `xml:"TargetMode,attr"`
+	TargetMode RelationshipTargetMode `xml:"TargetMode,attr,omitempty"`
 }
 
 // xlsxWorksheet directly maps the worksheet element in the namespace
@@ -35,21 +40,66 @@ type xlsxWorksheetRelation struct {
 // currently I have not checked it for completeness - it does as much
 // as I need.
 type xlsxWorksheet struct {
-	XMLName         xml.Name             `xml:"http://schemas.openxmlformats.org/spreadsheetml/2006/main worksheet"`
-	SheetPr         xlsxSheetPr          `xml:"sheetPr"`
-	Dimension       xlsxDimension        `xml:"dimension"`
-	SheetViews      xlsxSheetViews       `xml:"sheetViews"`
-	SheetFormatPr   xlsxSheetFormatPr    `xml:"sheetFormatPr"`
-	Cols            *xlsxCols            `xml:"cols,omitempty"`
-	SheetData       xlsxSheetData        `xml:"sheetData"`
-	Hyperlinks      *xlsxHyperlinks      `xml:"hyperlinks,omitempty"`
-	DataValidations *xlsxDataValidations `xml:"dataValidations"`
-	AutoFilter      *xlsxAutoFilter      `xml:"autoFilter,omitempty"`
-	MergeCells      *xlsxMergeCells      `xml:"mergeCells,omitempty"`
-	PrintOptions    xlsxPrintOptions     `xml:"printOptions"`
-	PageMargins     xlsxPageMargins      `xml:"pageMargins"`
-	PageSetUp       xlsxPageSetUp        `xml:"pageSetup"`
-	HeaderFooter    xlsxHeaderFooter     `xml:"headerFooter"`
+	XMLName               xml.Name                    `xml:"http://schemas.openxmlformats.org/spreadsheetml/2006/main worksheet"`
+	SheetPr               xlsxSheetPr                 `xml:"sheetPr"`
+	Dimension             xlsxDimension               `xml:"dimension"`
+	SheetViews            xlsxSheetViews              `xml:"sheetViews"`
+	SheetFormatPr         xlsxSheetFormatPr           `xml:"sheetFormatPr"`
+	Cols                  *xlsxCols                   `xml:"cols,omitempty"`
+	SheetData             xlsxSheetData               `xml:"sheetData"`
+	SheetProtection       *xlsxSheetProtection        `xml:"sheetProtection,omitempty"`
+	AutoFilter            *xlsxAutoFilter             `xml:"autoFilter,omitempty"`
+	MergeCells            *xlsxMergeCells             `xml:"mergeCells,omitempty"`
+	ConditionalFormatting []xlsxConditionalFormatting `xml:"conditionalFormatting,omitempty"`
+	DataValidations       *xlsxDataValidations        `xml:"dataValidations"`
+	Hyperlinks            *xlsxHyperlinks             `xml:"hyperlinks,omitempty"`
+	PrintOptions          xlsxPrintOptions            `xml:"printOptions"`
+	PageMargins           xlsxPageMargins             `xml:"pageMargins"`
+	PageSetUp             xlsxPageSetUp               `xml:"pageSetup"`
+	HeaderFooter          xlsxHeaderFooter            `xml:"headerFooter"`
+	Drawing               *xlsxDrawing                `xml:"drawing,omitempty"`
+	LegacyDrawing         *xlsxLegacyDrawing          `xml:"legacyDrawing,omitempty"`
+	TableParts            *xlsxTableParts             `xml:"tableParts,omitempty"`
+
+	// comments holds the comments of the cells, which are saved in a
+	// part of their own.
+	comments *xlsxComments
+}
+
+// xlsxSheetProtection directly maps the sheetProtection element in the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main.
+// Each attribute that is true protects the thing it names; those that
+// are true when missing are pointers, so that they are only written to
+// turn the protection off.
+type xlsxSheetProtection struct {
+	Password            string `xml:"password,attr,omitempty"`
+	AlgorithmName       string `xml:"algorithmName,attr,omitempty"`
+	HashValue           string `xml:"hashValue,attr,omitempty"`
+	SaltValue           string `xml:"saltValue,attr,omitempty"`
+	SpinCount           int    `xml:"spinCount,attr,omitempty"`
+	Sheet               bool   `xml:"sheet,attr,omitempty"`
+	Objects             bool   `xml:"objects,attr,omitempty"`
+	Scenarios           bool   `xml:"scenarios,attr,omitempty"`
+	FormatCells         *bool  `xml:"formatCells,attr"`
+	FormatColumns       *bool  `xml:"formatColumns,attr"`
+	FormatRows          *bool  `xml:"formatRows,attr"`
+	InsertColumns       *bool  `xml:"insertColumns,attr"`
+	InsertRows          *bool  `xml:"insertRows,attr"`
+	InsertHyperlinks    *bool  `xml:"insertHyperlinks,attr"`
+	DeleteColumns       *bool  `xml:"deleteColumns,attr"`
+	DeleteRows          *bool  `xml:"deleteRows,attr"`
+	SelectLockedCells   bool   `xml:"selectLockedCells,attr,omitempty"`
+	Sort                *bool  `xml:"sort,attr"`
+	AutoFilter          *bool  `xml:"autoFilter,attr"`
+	PivotTables         *bool  `xml:"pivotTables,attr"`
+	SelectUnlockedCells bool   `xml:"selectUnlockedCells,attr,omitempty"`
+}
+
+// xlsxLegacyDrawing directly maps the legacyDrawing element in the
+// namespace http://schemas.openxmlformats.org/spreadsheetml/2006/main,
+// which refers to the VML drawing of the comments of a sheet.
+type xlsxLegacyDrawing struct {
+	RelationshipId string `xml:"id,attr"`
 }
 
 // xlsxHeaderFooter directly maps the headerFooter element in the namespace
@@ -199,6 +249,7 @@ type xlsxPane struct {
 // as I need.
 type xlsxSheetPr struct {
 	FilterMode  bool              `xml:"filterMode,attr"`
+	TabColor    *xlsxColor        `xml:"tabColor,omitempty"`
 	PageSetUpPr []xlsxPageSetUpPr `xml:"pageSetUpPr"`
 }
 
@@ -314,6 +365,7 @@ type xlsxRow struct {
 	Ht           string  `xml:"ht,attr,omitempty"`
 	CustomHeight bool    `xml:"customHeight,attr,omitempty"`
 	OutlineLevel uint8   `xml:"outlineLevel,attr,omitempty"`
+	Collapsed    bool    `xml:"collapsed,attr,omitempty"`
 }
 
 type xlsxAutoFilter struct {
@@ -325,7 +377,7 @@ type xlsxMergeCell struct {
 }
 
 type xlsxMergeCells struct {
-	XMLName  xml.Name                 //`xml:"mergeCells,omitempty"`
+	XMLName  xml.Name                 `xml:"mergeCells"`
 	Count    int                      `xml:"count,attr,omitempty"`
 	Cells    []xlsxMergeCell          `xml:"mergeCell,omitempty"`
 	CellsMap map[string]xlsxMergeCell `xml:"-"`